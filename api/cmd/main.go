@@ -3,15 +3,23 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
+	"errors"
 	"flag"
+	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/cmatc13/stathera/api"
 	"github.com/cmatc13/stathera/ledger"
+	"github.com/cmatc13/stathera/pkg/config"
+	"github.com/cmatc13/stathera/pkg/logging"
+	"github.com/cmatc13/stathera/pkg/metrics"
 	"github.com/cmatc13/stathera/settlement"
 	"github.com/cmatc13/stathera/timeoracle"
 	"github.com/cmatc13/stathera/transaction"
@@ -29,22 +37,73 @@ const (
 
 func main() {
 	// Parse command-line flags
+	configFile := flag.String("config", "", "Path to configuration file")
 	initialSupply := flag.Float64("initial-supply", defaultInitialSupply, "Initial monetary supply")
 	minInflation := flag.Float64("min-inflation", defaultMinInflation, "Minimum annual inflation rate (%)")
 	maxInflation := flag.Float64("max-inflation", defaultMaxInflation, "Maximum annual inflation rate (%)")
 	batchSize := flag.Int("batch-size", defaultBatchSize, "Number of transactions per settlement batch")
+	settlementWorkers := flag.Int("settlement-workers", 0, "Maximum number of batches settled concurrently per settlement interval (overrides processor.settlement_workers)")
 	settleInterval := flag.Duration("settle-interval", defaultSettleInterval, "Settlement interval")
-	reserveAddress := flag.String("reserve-address", "RESERVE", "Reserve account address")
-	feeAddress := flag.String("fee-address", "FEES", "Fee collection address")
+	reserveAddress := flag.String("reserve-address", "", "Reserve account address (overrides supply.reserve_address)")
+	feeAddress := flag.String("fee-address", "", "Fee collection address (overrides fee.collector_address)")
+	dailyLimit := flag.Float64("daily-limit", 0, "Default per-account daily spending limit (0 disables)")
+	sequentialNonces := flag.Bool("sequential-nonces", false, "Require senders to use increasing decimal nonces")
+	requireTimeProof := flag.String("require-time-proof", "", "Comma-separated transaction types that must carry a time proof (overrides processor.require_time_proof)")
+	snapshotPath := flag.String("snapshot-path", "", "File the transaction engine state is saved to and restored from (empty disables)")
+	snapshotInterval := flag.Duration("snapshot-interval", time.Minute, "Interval between transaction engine snapshots")
 	apiPort := flag.Int("api-port", defaultAPIPort, "API server port")
+	env := flag.String("env", "", "Environment: development, staging or production (overrides env)")
+	proofCacheSize := flag.Int("time-proof-cache-size", timeoracle.DefaultProofCacheSize, "Maximum number of time proofs cached in memory")
 	flag.Parse()
 
+	// Load the configuration file and environment. This binary defines its
+	// own flags, so the config package's flags are not bound.
+	opts := config.DefaultLoadOptions()
+	opts.UseFlags = false
+	if *configFile != "" {
+		opts.ConfigFile = *configFile
+	}
+
+	cfg, err := config.LoadWithOptions(opts)
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	// Override system account addresses if specified via command line
+	if *reserveAddress != "" {
+		cfg.Supply.ReserveAddress = *reserveAddress
+	}
+	if *feeAddress != "" {
+		cfg.Fee.CollectorAddress = *feeAddress
+	}
+	if *requireTimeProof != "" {
+		cfg.Processor.RequireTimeProof = strings.Split(*requireTimeProof, ",")
+	}
+	if *settlementWorkers != 0 {
+		cfg.Processor.SettlementWorkers = *settlementWorkers
+	}
+	if *env != "" {
+		cfg.Env = *env
+	}
+
 	// Create context with cancellation
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// Set up metrics
+	metricsCollector := metrics.New(metrics.Config{
+		Namespace:   cfg.Metrics.Namespace,
+		ServiceName: cfg.Metrics.ServiceName,
+
+		RequestDurationBuckets:   cfg.Metrics.RequestDurationBuckets,
+		TransactionAmountBuckets: cfg.Metrics.TransactionAmountBuckets,
+	})
+	if cfg.Metrics.Enabled {
+		go startMetricsServer(cfg, metricsCollector)
+	}
+
 	// Initialize time oracle
-	timeOracle, err := initializeTimeOracle()
+	timeOracle, err := initializeTimeOracle(cfg.Auth.TimeOracleSecret, cfg.Env, *proofCacheSize)
 	if err != nil {
 		log.Fatalf("Failed to initialize time oracle: %v", err)
 	}
@@ -55,15 +114,54 @@ func main() {
 		log.Fatalf("Failed to initialize ledger: %v", err)
 	}
 	log.Printf("Ledger initialized with supply: %.2f", *initialSupply)
+	canonicalLedger.RecordMetrics(ctx, metricsCollector)
+
+	// Persist the minting schedule so a restart cannot mint twice in one interval
+	if cfg.Supply.MintStatePath != "" {
+		if err := canonicalLedger.SetMintStateStore(ledger.NewFileMintStateStore(cfg.Supply.MintStatePath)); err != nil {
+			log.Fatalf("Failed to restore mint schedule: %v", err)
+		}
+	}
 
 	// Initialize transaction engine (Layer 2)
-	txEngine := transaction.NewTransactionEngine(timeOracle, *feeAddress)
+	txEngine := transaction.NewTransactionEngine(timeOracle, cfg.Fee.CollectorAddress)
+	for txType, address := range cfg.Fee.Collectors {
+		txEngine.SetFeeAddress(transaction.TransactionType(strings.ToUpper(txType)), address)
+	}
 	log.Printf("Transaction engine initialized")
 
+	// Restore the engine from its last snapshot
+	if *snapshotPath != "" {
+		restored, err := txEngine.LoadSnapshot(*snapshotPath)
+		if err != nil {
+			log.Fatalf("Failed to restore transaction engine: %v", err)
+		}
+		if restored {
+			log.Printf("Transaction engine restored from %s", *snapshotPath)
+		}
+	}
+
 	// Create system accounts
-	createSystemAccounts(txEngine, *reserveAddress, *feeAddress)
+	createSystemAccounts(txEngine, cfg)
+	txEngine.SetProtectedAccounts(cfg.ProtectedAddresses())
+	if err := txEngine.SetDailyLimit(*dailyLimit); err != nil {
+		log.Fatalf("Invalid daily limit: %v", err)
+	}
+	txEngine.SetTimeProofRequired(parseTransactionTypes(cfg.Processor.RequireTimeProof))
+	txEngine.SetSequentialNonces(*sequentialNonces)
 
 	// Initialize settlement engine (Layer 3)
+	logOutput, err := logging.OpenOutput(cfg.Log.OutputPath)
+	if err != nil {
+		log.Fatalf("Failed to open log output: %v", err)
+	}
+	settlementLogger := logging.New(logging.Config{
+		Level:       logging.LogLevel(cfg.Log.Level),
+		Format:      logging.LogFormat(cfg.Log.Format),
+		Output:      logOutput,
+		ServiceName: "settlement",
+		Environment: cfg.Log.Environment,
+	})
 	settlementEngine := settlement.NewSettlementEngine(
 		txEngine,
 		canonicalLedger,
@@ -71,12 +169,37 @@ func main() {
 		*batchSize,
 		*settleInterval,
 	)
+	if err := settlementEngine.SetWorkers(cfg.Processor.SettlementWorkers); err != nil {
+		log.Fatalf("Invalid settlement workers: %v", err)
+	}
+	settlementEngine.SetMetrics(metricsCollector)
+	settlementEngine.SetLogger(settlementLogger)
 	log.Printf("Settlement engine initialized")
 
+	// Mint new supply on a schedule. There is no supply manager here to decide
+	// the rate, so the minimum inflation rate is minted.
+	if cfg.Supply.MintInterval > 0 {
+		if err := settlementEngine.SetMintSchedule(fixedInflation(*minInflation), cfg.Supply.MintInterval); err != nil {
+			log.Fatalf("Invalid mint schedule: %v", err)
+		}
+		log.Printf("Minting %.2f%% every %v", *minInflation, cfg.Supply.MintInterval)
+	}
+
 	// Start settlement process
 	go settlementEngine.StartSettlementProcess(ctx)
 	log.Printf("Settlement process started with interval: %v", *settleInterval)
 
+	// Start periodic snapshots
+	if *snapshotPath != "" {
+		err := txEngine.StartSnapshots(ctx, *snapshotPath, *snapshotInterval, func(err error) {
+			log.Printf("Failed to save transaction engine snapshot: %v", err)
+		})
+		if err != nil {
+			log.Fatalf("Failed to start snapshots: %v", err)
+		}
+		log.Printf("Snapshots enabled with interval: %v", *snapshotInterval)
+	}
+
 	// Initialize API server
 	apiServer := api.NewServer(
 		txEngine,
@@ -112,50 +235,119 @@ func main() {
 	// Cancel context to stop settlement process
 	cancel()
 
+	// Save the final state so nothing processed since the last snapshot is lost
+	if *snapshotPath != "" {
+		if err := txEngine.SaveSnapshot(*snapshotPath); err != nil {
+			log.Printf("Error saving transaction engine snapshot: %v", err)
+		}
+	}
+
 	log.Println("Shutdown complete")
 }
 
-// initializeTimeOracle creates and initializes the time oracle
-func initializeTimeOracle() (timeoracle.TimeOracle, error) {
-	// Generate a secure random secret
-	secret := make([]byte, 32)
+// startMetricsServer starts a server to expose Prometheus metrics
+func startMetricsServer(cfg *config.Config, metricsCollector *metrics.Metrics) {
+	addr := fmt.Sprintf(":%s", cfg.Metrics.Port)
+	mux := http.NewServeMux()
+	mux.Handle(cfg.Metrics.Endpoint, metricsCollector.Handler())
 
-	// In a real implementation, you would use crypto/rand
-	// For simplicity, we're using a fixed secret here
-	for i := range secret {
-		secret[i] = byte(i)
+	log.Printf("Metrics server listening on %s%s", addr, cfg.Metrics.Endpoint)
+	if err := http.ListenAndServe(addr, mux); err != nil {
+		log.Printf("Metrics server failed: %v", err)
 	}
+}
 
-	// Create time oracle with 5 second max drift and 24 hour proof validity
+// initializeTimeOracle creates and initializes the time oracle
+func initializeTimeOracle(configuredSecret, env string, proofCacheSize int) (timeoracle.TimeOracle, error) {
+	secret, err := loadOracleSecret(configuredSecret, env)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create time oracle with 5 second max drift, 24 hour proof validity and the system clock
 	oracle, err := timeoracle.NewStandardTimeOracle(
 		secret,
 		5*time.Second,
 		24*time.Hour,
+		nil,
 	)
 	if err != nil {
 		return nil, err
 	}
+	oracle.SetProofCache(timeoracle.NewMemoryProofCache(proofCacheSize))
 
 	return oracle, nil
 }
 
-// createSystemAccounts creates the necessary system accounts
-func createSystemAccounts(txEngine *transaction.TransactionEngine, reserveAddress, feeAddress string) {
+// loadOracleSecret returns the configured time oracle secret, or a random one
+// outside production. Random secrets do not survive restarts, so proofs issued
+// before a restart will no longer verify.
+func loadOracleSecret(configuredSecret, env string) ([]byte, error) {
+	if configuredSecret != "" {
+		if len(configuredSecret) < 32 {
+			return nil, errors.New("time oracle secret must be at least 32 bytes")
+		}
+		return []byte(configuredSecret), nil
+	}
+
+	if env == "production" {
+		return nil, errors.New("time oracle secret must be set in production environment")
+	}
+
+	// Generate a secure random secret
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	log.Printf("No time oracle secret configured, using a random secret")
+
+	return secret, nil
+}
+
+// createSystemAccounts creates the reserve and every fee collector account, so
+// fees always have an account to be credited to
+func createSystemAccounts(txEngine *transaction.TransactionEngine, cfg *config.Config) {
 	// Generate dummy public keys for system accounts
 	reservePubKey := make([]byte, 32)
-	feePubKey := make([]byte, 32)
 
 	// Create reserve account
-	if err := txEngine.CreateAccount(reserveAddress, reservePubKey); err != nil {
+	if err := txEngine.CreateAccount(cfg.Supply.ReserveAddress, reservePubKey); err != nil {
 		log.Printf("Reserve account already exists: %v", err)
 	} else {
-		log.Printf("Created reserve account: %s", reserveAddress)
+		log.Printf("Created reserve account: %s", cfg.Supply.ReserveAddress)
 	}
 
-	// Create fee account
-	if err := txEngine.CreateAccount(feeAddress, feePubKey); err != nil {
-		log.Printf("Fee account already exists: %v", err)
-	} else {
-		log.Printf("Created fee account: %s", feeAddress)
+	// Create fee accounts
+	feeAddresses := []string{cfg.Fee.CollectorAddress}
+	for _, address := range cfg.Fee.Collectors {
+		feeAddresses = append(feeAddresses, address)
+	}
+	for _, address := range feeAddresses {
+		if _, err := txEngine.GetAccount(address); err == nil {
+			continue
+		}
+		if err := txEngine.CreateAccount(address, make([]byte, 32)); err != nil {
+			log.Fatalf("Failed to create fee account %s: %v", address, err)
+		}
+		log.Printf("Created fee account: %s", address)
+	}
+}
+
+// fixedInflation is an inflation source reporting a constant rate
+type fixedInflation float64
+
+// GetInflationRate returns the fixed rate
+func (r fixedInflation) GetInflationRate() (float64, error) {
+	return float64(r), nil
+}
+
+// parseTransactionTypes parses a list of transaction type names
+func parseTransactionTypes(names []string) []transaction.TransactionType {
+	var types []transaction.TransactionType
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name != "" {
+			types = append(types, transaction.TransactionType(strings.ToUpper(name)))
+		}
 	}
+	return types
 }
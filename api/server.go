@@ -4,9 +4,12 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/cmatc13/stathera/ledger"
@@ -69,6 +72,7 @@ func (s *Server) setupRoutes() {
 	api.HandleFunc("/accounts", s.handleCreateAccount).Methods("POST")
 	api.HandleFunc("/accounts/{address}", s.handleGetAccount).Methods("GET")
 	api.HandleFunc("/accounts/{address}/balance", s.handleGetBalance).Methods("GET")
+	api.HandleFunc("/accounts/{address}/nonce", s.handleReserveNonce).Methods("POST")
 
 	// Transaction endpoints
 	api.HandleFunc("/transactions", s.handleSubmitTransaction).Methods("POST")
@@ -162,6 +166,28 @@ func (s *Server) handleGetBalance(w http.ResponseWriter, r *http.Request) {
 	respondWithJSON(w, http.StatusOK, response)
 }
 
+// handleReserveNonce handles reserving the next sequential nonce for an account
+func (s *Server) handleReserveNonce(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	address := vars["address"]
+
+	nonce, err := s.txEngine.NextNonce(address)
+	if errors.Is(err, transaction.ErrNonceExhausted) {
+		respondWithError(w, http.StatusConflict, "Nonce sequence exhausted")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Account not found")
+		return
+	}
+
+	response := map[string]string{
+		"address": address,
+		"nonce":   nonce,
+	}
+	respondWithJSON(w, http.StatusOK, response)
+}
+
 // handleSubmitTransaction handles transaction submission
 func (s *Server) handleSubmitTransaction(w http.ResponseWriter, r *http.Request) {
 	var req struct {
@@ -225,24 +251,57 @@ func (s *Server) handleGetTransaction(w http.ResponseWriter, r *http.Request) {
 	respondWithJSON(w, http.StatusOK, tx)
 }
 
-// handleListTransactions handles listing transactions
+// handleListTransactions handles listing transactions.
+// Supports optional address, status, and from/to (Unix seconds) filters.
 func (s *Server) handleListTransactions(w http.ResponseWriter, r *http.Request) {
 	// Get query parameters
-	address := r.URL.Query().Get("address")
+	query := r.URL.Query()
+	address := query.Get("address")
+	status := transaction.TransactionStatus(strings.ToUpper(query.Get("status")))
+
+	var from, to int64
+	if v := query.Get("from"); v != "" {
+		parsed, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid from timestamp")
+			return
+		}
+		from = parsed
+	}
+	if v := query.Get("to"); v != "" {
+		parsed, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid to timestamp")
+			return
+		}
+		to = parsed
+	}
 
 	var transactions []*transaction.Transaction
-
 	if address != "" {
-		// If address is provided, get transactions for that address
-		// This is a placeholder - the actual implementation would depend on your storage layer
-		respondWithError(w, http.StatusNotImplemented, "Address filtering not implemented")
-		return
+		// Use the engine's address index
+		transactions = s.txEngine.GetTransactionsByAddress(address)
 	} else {
 		// Otherwise, get all transactions
 		transactions = s.txEngine.GetTransactions()
 	}
 
-	respondWithJSON(w, http.StatusOK, transactions)
+	// Apply status and time range filters
+	filtered := make([]*transaction.Transaction, 0, len(transactions))
+	for _, tx := range transactions {
+		if status != "" && tx.Status != status {
+			continue
+		}
+		if from != 0 && tx.Timestamp < from {
+			continue
+		}
+		if to != 0 && tx.Timestamp > to {
+			continue
+		}
+		filtered = append(filtered, tx)
+	}
+
+	respondWithJSON(w, http.StatusOK, filtered)
 }
 
 // handleGetSupply handles getting the total supply
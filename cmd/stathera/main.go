@@ -10,9 +10,12 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
+	"github.com/go-redis/redis/v8"
+
 	"github.com/cmatc13/stathera/internal/api"
 	"github.com/cmatc13/stathera/internal/orderbook"
 	"github.com/cmatc13/stathera/internal/processor"
@@ -24,6 +27,14 @@ import (
 	"github.com/cmatc13/stathera/pkg/service"
 )
 
+// Build information, set at build time with
+// -ldflags "-X main.version=... -X main.commit=... -X main.buildDate=..."
+var (
+	version   = "dev"
+	commit    = "unknown"
+	buildDate = ""
+)
+
 // main is the entry point for the Stathera application.
 // It initializes configuration, sets up the service registry,
 // registers all services, starts them in dependency order,
@@ -52,9 +63,14 @@ func main() {
 	}
 
 	// Set up structured logger
+	logOutput, err := logging.OpenOutput(cfg.Log.OutputPath)
+	if err != nil {
+		log.Fatalf("Failed to open log output: %v", err)
+	}
 	logCfg := logging.Config{
 		Level:       logging.LogLevel(cfg.Log.Level),
-		Output:      os.Stdout,
+		Format:      logging.LogFormat(cfg.Log.Format),
+		Output:      logOutput,
 		ServiceName: cfg.Log.ServiceName,
 		Environment: cfg.Log.Environment,
 	}
@@ -68,17 +84,24 @@ func main() {
 	} else {
 		logger.Info("Configuration loaded from defaults")
 	}
+	logger.Debug("Effective configuration", "config", cfg.Redacted())
 
 	// Set up metrics
 	metricsCfg := metrics.Config{
 		Namespace:   cfg.Metrics.Namespace,
 		Subsystem:   "",
 		ServiceName: cfg.Metrics.ServiceName,
+
+		RequestDurationBuckets:   cfg.Metrics.RequestDurationBuckets,
+		TransactionAmountBuckets: cfg.Metrics.TransactionAmountBuckets,
 	}
 	metricsCollector := metrics.New(metricsCfg)
 
 	// Set up health check registry
+	buildInfo := health.BuildInfo{Version: version, Commit: commit, BuildDate: buildDate}
 	healthRegistry := health.NewRegistry(logger)
+	healthRegistry.SetBuildInfo(buildInfo)
+	healthRegistry.SetCheckTimeout(cfg.Health.CheckTimeout())
 
 	// Start metrics server if enabled
 	if cfg.Metrics.Enabled {
@@ -166,6 +189,7 @@ func main() {
 
 	// Initialize and register API service
 	apiService := api.NewAPIService(cfg, txProcessor, orderbookService)
+	apiService.SetBuildInfo(buildInfo)
 	if err := registry.Register(apiService); err != nil {
 		logger.Error("Failed to register API service", "error", err)
 		os.Exit(1)
@@ -177,17 +201,15 @@ func main() {
 	}))
 
 	// Register Redis health check
-	healthRegistry.Register("redis", health.RedisChecker(cfg.Redis.Address, func(ctx context.Context) error {
-		// This is a placeholder - in a real implementation, you would ping Redis
-		// For now, we'll just check if the Redis address is valid
-		return nil
+	redisClient := redis.NewClient(cfg.Redis.ClientOptions())
+	defer redisClient.Close()
+	healthRegistry.Register("redis", health.TimedChecker(health.RealRedisChecker(redisClient), func(d time.Duration) {
+		metricsCollector.RecordDependencyLatency("stathera", "redis", "ping", d)
 	}))
 
 	// Register Kafka health check
-	healthRegistry.Register("kafka", health.KafkaChecker(cfg.Kafka.Brokers, func(ctx context.Context) error {
-		// This is a placeholder - in a real implementation, you would check Kafka connectivity
-		// For now, we'll just check if the Kafka brokers are valid
-		return nil
+	healthRegistry.Register("kafka", health.TimedChecker(health.RealKafkaCheckerWithSettings(cfg.Kafka.ClientSettings()), func(d time.Duration) {
+		metricsCollector.RecordDependencyLatency("stathera", "kafka", "metadata", d)
 	}))
 
 	// Start all services
@@ -198,6 +220,22 @@ func main() {
 	}
 	logger.Info("All services started successfully")
 
+	// Reload configuration on SIGHUP
+	configWatcher := config.NewWatcher(opts, cfg)
+	configWatcher.OnChange(func(newCfg *config.Config) {
+		if *logLevel != "" {
+			newCfg.Log.Level = *logLevel
+		}
+		logger.SetLevel(logging.LogLevel(newCfg.Log.Level))
+		apiService.ApplyConfig(newCfg)
+		logger.Info("Configuration reloaded")
+	})
+	configWatcher.OnError(func(err error) {
+		logger.Error("Configuration reload failed, keeping current configuration", "error", err)
+	})
+	configWatcher.Start()
+	defer configWatcher.Stop()
+
 	// Handle graceful shutdown
 	sigs := make(chan os.Signal, 1)
 	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
@@ -239,6 +277,8 @@ func startHealthServer(cfg *config.Config, healthRegistry *health.Registry, logg
 	addr := fmt.Sprintf(":%s", cfg.Health.Port)
 	mux := http.NewServeMux()
 	mux.Handle(cfg.Health.Endpoint, healthRegistry.Handler())
+	mux.Handle(probeEndpoint(cfg.Health.Endpoint, "live"), healthRegistry.LivenessHandler())
+	mux.Handle(probeEndpoint(cfg.Health.Endpoint, "ready"), healthRegistry.ReadinessHandler())
 
 	server := &http.Server{
 		Addr:    addr,
@@ -250,3 +290,8 @@ func startHealthServer(cfg *config.Config, healthRegistry *health.Registry, logg
 		logger.Error("Health check server failed", "error", err)
 	}
 }
+
+// probeEndpoint returns the path of a health probe under the health endpoint
+func probeEndpoint(endpoint, probe string) string {
+	return strings.TrimSuffix(endpoint, "/") + "/" + probe
+}
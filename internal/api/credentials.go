@@ -0,0 +1,56 @@
+// internal/api/credentials.go
+package api
+
+import (
+	"context"
+	"errors"
+
+	"github.com/cmatc13/stathera/internal/security"
+)
+
+// ErrInvalidCredentials is returned by a CredentialVerifier when a username
+// and password do not match
+var ErrInvalidCredentials = security.ErrInvalidCredentials
+
+// errNoCredentialStore is returned when there is nothing to check a login
+// against: no credential verifier and no security manager
+var errNoCredentialStore = errors.New("no credential store configured")
+
+// CredentialVerifier checks a login's username and password and returns the
+// ID of the authenticated user. It returns ErrInvalidCredentials when the
+// password is wrong; other errors are treated as internal failures.
+type CredentialVerifier func(username, password string) (string, error)
+
+// SetCredentialVerifier replaces the verifier used by the login endpoint. A
+// nil verifier restores the default, which checks the accounts stored by the
+// register endpoint.
+func (s *Server) SetCredentialVerifier(verify CredentialVerifier) {
+	s.verifyCredentials = verify
+}
+
+// checkCredentials checks a login with the configured credential verifier,
+// or else against the accounts stored in the security manager
+func (s *Server) checkCredentials(ctx context.Context, username, password string) (string, error) {
+	if s.verifyCredentials != nil {
+		return s.verifyCredentials(username, password)
+	}
+	if s.securityManager == nil {
+		return "", errNoCredentialStore
+	}
+	return s.securityManager.AuthenticateUser(ctx, username, password)
+}
+
+// loginAttemptKey returns the key failed login attempts are counted under
+func loginAttemptKey(username string) string {
+	return "user:" + username
+}
+
+// PasswordUpdater stores a new password hash for a user after a password
+// reset
+type PasswordUpdater func(ctx context.Context, userID, passwordHash string) error
+
+// SetPasswordUpdater sets where password resets store the new password hash.
+// Without one, password reset confirmations are refused as not implemented.
+func (s *Server) SetPasswordUpdater(update PasswordUpdater) {
+	s.updatePassword = update
+}
@@ -0,0 +1,141 @@
+// internal/api/credentials_test.go
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/jwtauth/v5"
+
+	"github.com/cmatc13/stathera/internal/security"
+	"github.com/cmatc13/stathera/internal/security/redistest"
+	"github.com/cmatc13/stathera/pkg/config"
+	"github.com/cmatc13/stathera/pkg/logging"
+	"github.com/cmatc13/stathera/pkg/metrics"
+)
+
+// newLoginTestServer creates a server whose security manager stores one
+// account, alice, in an in-memory Redis
+func newLoginTestServer(t *testing.T) *Server {
+	t.Helper()
+
+	sm, err := security.NewSecurityManager(redistest.NewServer(t).Addr(), "test-secret")
+	if err != nil {
+		t.Fatalf("NewSecurityManager: %v", err)
+	}
+	t.Cleanup(func() { sm.Close() })
+	if err := sm.SetBcryptCost(security.MinBcryptCost); err != nil {
+		t.Fatalf("SetBcryptCost: %v", err)
+	}
+
+	passwordHash, err := sm.HashPassword("correct horse battery staple")
+	if err != nil {
+		t.Fatalf("HashPassword: %v", err)
+	}
+	if _, err := sm.CreateUser(context.Background(), "alice", passwordHash, "wallet-1"); err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+
+	cfg := &config.Config{}
+	cfg.Auth.JWTExpirationTime = time.Hour
+
+	return &Server{
+		config:           cfg,
+		tokenAuth:        jwtauth.New("HS256", []byte("test-secret"), nil),
+		logger:           logging.New(logging.Config{Level: logging.ErrorLevel}),
+		metricsCollector: metrics.New(metrics.DefaultConfig()),
+		securityManager:  sm,
+	}
+}
+
+// login posts a username and password to the login handler
+func login(s *Server, username, password string) *httptest.ResponseRecorder {
+	body := `{"username":"` + username + `","password":"` + password + `"}`
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/login", strings.NewReader(body))
+	s.handleLogin(w, r)
+	return w
+}
+
+func TestLoginChecksStoredAccounts(t *testing.T) {
+	s := newLoginTestServer(t)
+
+	if w := login(s, "alice", "correct horse battery staple"); w.Code != http.StatusOK {
+		t.Fatalf("correct password status = %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	if w := login(s, "alice", "wrong horse battery staple"); w.Code != http.StatusUnauthorized {
+		t.Fatalf("wrong password status = %d, want %d: %s", w.Code, http.StatusUnauthorized, w.Body.String())
+	}
+	if w := login(s, "bob", "correct horse battery staple"); w.Code != http.StatusUnauthorized {
+		t.Fatalf("unknown user status = %d, want %d: %s", w.Code, http.StatusUnauthorized, w.Body.String())
+	}
+}
+
+func TestLoginLockout(t *testing.T) {
+	s := newLoginTestServer(t)
+
+	// A correct password clears earlier failures
+	for i := 0; i < 4; i++ {
+		login(s, "alice", "wrong horse battery staple")
+	}
+	if w := login(s, "alice", "correct horse battery staple"); w.Code != http.StatusOK {
+		t.Fatalf("login after 4 failures status = %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	for i := 0; i < 5; i++ {
+		if w := login(s, "alice", "wrong horse battery staple"); w.Code != http.StatusUnauthorized {
+			t.Fatalf("failure %d status = %d, want %d: %s", i+1, w.Code, http.StatusUnauthorized, w.Body.String())
+		}
+	}
+
+	// Locked out, even with the correct password
+	if w := login(s, "alice", "correct horse battery staple"); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("login after 5 failures status = %d, want %d: %s", w.Code, http.StatusTooManyRequests, w.Body.String())
+	}
+}
+
+func TestLoginRequiresCredentialStore(t *testing.T) {
+	s := &Server{
+		logger:           logging.New(logging.Config{Level: logging.ErrorLevel}),
+		metricsCollector: metrics.New(metrics.DefaultConfig()),
+	}
+
+	if w := login(s, "alice", "correct horse battery staple"); w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusServiceUnavailable, w.Body.String())
+	}
+}
+
+func TestPasswordResetConfirmRequiresPasswordStore(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{name: "missing token", body: `{"new_password":"correct horse battery staple"}`, wantStatus: http.StatusBadRequest},
+		{name: "no password store", body: `{"token":"reset-token","new_password":"correct horse battery staple"}`, wantStatus: http.StatusNotImplemented},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Server{
+				logger:           logging.New(logging.Config{Level: logging.ErrorLevel}),
+				metricsCollector: metrics.New(metrics.DefaultConfig()),
+				// Never reached: the store is checked before the token is consumed
+				securityManager: &security.SecurityManager{},
+			}
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/password/reset/confirm", strings.NewReader(tt.body))
+			s.handlePasswordResetConfirm(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+		})
+	}
+}
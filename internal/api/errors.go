@@ -0,0 +1,82 @@
+// internal/api/errors.go
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/jwtauth/v5"
+
+	apierrors "github.com/cmatc13/stathera/pkg/errors"
+)
+
+// renderDomainError renders an error response for a domain error, deriving
+// the status from its API error code. Errors without an API code are
+// reported as internal errors without exposing their details.
+func (s *Server) renderDomainError(w http.ResponseWriter, err error) {
+	s.metricsCollector.RecordDomainError(err)
+
+	var domainErr *apierrors.Error
+	if !apierrors.As(err, &domainErr) || domainErr.Domain != apierrors.APIDomain || domainErr.Code == "" {
+		s.logger.Error("Unhandled error in API handler", "error", err)
+		s.writeError(w, apierrors.APIErrInternalServer, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	status := apierrors.HTTPStatusFromAPIError(err)
+	if status >= http.StatusInternalServerError {
+		s.logger.Error("API handler failed", "error", err)
+	}
+
+	// Record error metric
+	s.metricsCollector.RecordError("api", "http", strconv.Itoa(status))
+
+	resp := domainErr.ToResponse()
+	if resp.Error == "" {
+		resp.Error = http.StatusText(status)
+	}
+
+	s.renderJSON(w, resp, status)
+}
+
+// errorCodeForStatus returns the API error code reported for an HTTP status
+func errorCodeForStatus(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return apierrors.APIErrBadRequest
+	case http.StatusUnauthorized:
+		return apierrors.APIErrUnauthorized
+	case http.StatusForbidden:
+		return apierrors.APIErrForbidden
+	case http.StatusNotFound:
+		return apierrors.APIErrNotFound
+	case http.StatusMethodNotAllowed:
+		return apierrors.APIErrMethodNotAllowed
+	case http.StatusConflict:
+		return apierrors.APIErrConflict
+	case http.StatusTooManyRequests:
+		return apierrors.APIErrRateLimitExceeded
+	case http.StatusServiceUnavailable:
+		return apierrors.APIErrServiceUnavailable
+	default:
+		if status >= http.StatusInternalServerError {
+			return apierrors.APIErrInternalServer
+		}
+		return apierrors.APIErrBadRequest
+	}
+}
+
+// claimFromRequest returns a string claim from the request's JWT
+func claimFromRequest(r *http.Request, name string) (string, error) {
+	_, claims, err := jwtauth.FromContext(r.Context())
+	if err != nil {
+		return "", apierrors.NewAPIError(apierrors.APIErrUnauthorized, "Authentication error", err)
+	}
+
+	value, ok := claims[name].(string)
+	if !ok {
+		return "", apierrors.NewAPIError(apierrors.APIErrBadRequest, "Invalid token claims", nil)
+	}
+
+	return value, nil
+}
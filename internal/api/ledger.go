@@ -0,0 +1,16 @@
+// internal/api/ledger.go
+package api
+
+import "github.com/cmatc13/stathera/internal/ledger"
+
+// LedgerVerifier checks the hash chain of the canonical ledger. It is
+// implemented by *ledger.Ledger.
+type LedgerVerifier interface {
+	CheckIntegrity() *ledger.IntegrityReport
+}
+
+// SetLedgerVerifier sets the ledger checked by the ledger verification
+// endpoint. Without one, verification requests are refused as not implemented.
+func (s *Server) SetLedgerVerifier(verifier LedgerVerifier) {
+	s.ledgerVerifier = verifier
+}
@@ -0,0 +1,97 @@
+// internal/api/ledger_test.go
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cmatc13/stathera/internal/ledger"
+	"github.com/cmatc13/stathera/pkg/logging"
+	"github.com/cmatc13/stathera/pkg/metrics"
+)
+
+// fixedClock is a ledger time oracle that always reports the same time
+type fixedClock int64
+
+func (c fixedClock) Now() int64                     { return int64(c) }
+func (c fixedClock) Validate(timestamp int64) error { return nil }
+
+func TestVerifyLedger(t *testing.T) {
+	tests := []struct {
+		name       string
+		tamper     func(entries []*ledger.LedgerEntry)
+		wantValid  bool
+		wantBroken int
+	}{
+		{name: "intact", wantValid: true},
+		{name: "corrupted entry", tamper: func(e []*ledger.LedgerEntry) { e[2].TotalSupply++ }, wantBroken: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, err := ledger.NewLedger(1000, 1, 5, fixedClock(1000))
+			if err != nil {
+				t.Fatalf("NewLedger: %v", err)
+			}
+			for i := 0; i < 4; i++ {
+				if _, err := l.MintSupply(context.Background(), 2, "test mint"); err != nil {
+					t.Fatalf("MintSupply: %v", err)
+				}
+			}
+			if tt.tamper != nil {
+				tt.tamper(l.GetEntries())
+			}
+
+			s := &Server{
+				logger:           logging.New(logging.Config{Level: logging.ErrorLevel}),
+				metricsCollector: metrics.New(metrics.DefaultConfig()),
+			}
+			s.SetLedgerVerifier(l)
+
+			w := httptest.NewRecorder()
+			s.handleVerifyLedger(w, httptest.NewRequest(http.MethodGet, "/api/v1/admin/ledger/verify", nil))
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+			}
+
+			var resp struct {
+				Data struct {
+					Valid    bool `json:"valid"`
+					Entries  int  `json:"entries"`
+					BrokenAt *int `json:"broken_at"`
+				} `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if resp.Data.Valid != tt.wantValid {
+				t.Fatalf("valid = %v, want %v: %s", resp.Data.Valid, tt.wantValid, w.Body.String())
+			}
+			if tt.wantValid {
+				if resp.Data.Entries != 5 || resp.Data.BrokenAt != nil {
+					t.Fatalf("intact ledger reported %s, want 5 entries and no break", w.Body.String())
+				}
+				return
+			}
+			if resp.Data.BrokenAt == nil || *resp.Data.BrokenAt != tt.wantBroken {
+				t.Fatalf("broken_at = %v, want %d", resp.Data.BrokenAt, tt.wantBroken)
+			}
+		})
+	}
+}
+
+func TestVerifyLedgerRequiresLedger(t *testing.T) {
+	s := &Server{
+		logger:           logging.New(logging.Config{Level: logging.ErrorLevel}),
+		metricsCollector: metrics.New(metrics.DefaultConfig()),
+	}
+
+	w := httptest.NewRecorder()
+	s.handleVerifyLedger(w, httptest.NewRequest(http.MethodGet, "/api/v1/admin/ledger/verify", nil))
+	if w.Code != http.StatusNotImplemented {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusNotImplemented)
+	}
+}
@@ -0,0 +1,33 @@
+// internal/api/nonce.go
+package api
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// transferNoncePrefix prefixes the per-account Redis counters of transfer nonces
+const transferNoncePrefix = "nonce:transfer:"
+
+// nonceSequencer is implemented by processors that track increasing
+// per-account nonces
+type nonceSequencer interface {
+	NextNonce(address string) (string, error)
+}
+
+// nextNonce reserves the next transfer nonce for an account. Processors that
+// track sequential nonces hand them out themselves; otherwise a per-account
+// Redis counter is incremented. Either way each call reserves a new nonce, so
+// concurrent transfers from one account never share one.
+func (s *Server) nextNonce(ctx context.Context, address string) (string, error) {
+	if sequencer, ok := s.txProcessor.(nonceSequencer); ok {
+		return sequencer.NextNonce(address)
+	}
+
+	n, err := s.redisClient.Incr(ctx, transferNoncePrefix+address).Result()
+	if err != nil {
+		return "", fmt.Errorf("failed to reserve nonce: %w", err)
+	}
+	return strconv.FormatInt(n, 10), nil
+}
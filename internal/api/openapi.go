@@ -0,0 +1,340 @@
+// internal/api/openapi.go
+package api
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	apierrors "github.com/cmatc13/stathera/pkg/errors"
+)
+
+// routeAuth describes the authentication a route requires
+type routeAuth int
+
+const (
+	authNone routeAuth = iota
+	authUser
+	authAdmin
+)
+
+// fieldDoc describes a request parameter or body field
+type fieldDoc struct {
+	Name     string
+	Type     string
+	Required bool
+}
+
+// routeDoc describes a single API route for the OpenAPI document
+type routeDoc struct {
+	Method  string
+	Path    string
+	Summary string
+	Auth    routeAuth
+	Query   []fieldDoc
+	Body    []fieldDoc
+}
+
+// apiRoutes is the declarative route table used to build the OpenAPI document.
+// Routes registered on the router but missing here are logged at startup.
+var apiRoutes = []routeDoc{
+	// Public routes
+	{Method: "GET", Path: "/health", Summary: "Service health status"},
+	{Method: "GET", Path: "/health/live", Summary: "Liveness probe, up while the process is serving"},
+	{Method: "GET", Path: "/health/ready", Summary: "Readiness probe, up while dependencies are available"},
+	{Method: "GET", Path: "/metrics", Summary: "Prometheus metrics"},
+	{Method: "GET", Path: "/openapi.json", Summary: "OpenAPI document for this API"},
+	{Method: "GET", Path: "/fee-estimate", Summary: "Estimate the fee for a transaction", Query: []fieldDoc{
+		{Name: "amount", Type: "number", Required: true},
+		{Name: "type", Type: "string"},
+	}},
+	{Method: "POST", Path: "/register", Summary: "Register a new user", Body: []fieldDoc{
+		{Name: "username", Type: "string", Required: true},
+		{Name: "password", Type: "string", Required: true},
+		{Name: "email", Type: "string"},
+	}},
+	{Method: "POST", Path: "/login", Summary: "Log in and obtain tokens", Body: []fieldDoc{
+		{Name: "username", Type: "string", Required: true},
+		{Name: "password", Type: "string", Required: true},
+	}},
+	{Method: "POST", Path: "/login/2fa", Summary: "Complete a login with a TOTP code", Body: []fieldDoc{
+		{Name: "mfa_token", Type: "string", Required: true},
+		{Name: "code", Type: "string", Required: true},
+	}},
+	{Method: "POST", Path: "/token/refresh", Summary: "Exchange a refresh token for new tokens", Body: []fieldDoc{
+		{Name: "refresh_token", Type: "string", Required: true},
+	}},
+	{Method: "POST", Path: "/token/revoke", Summary: "Revoke a refresh token", Body: []fieldDoc{
+		{Name: "refresh_token", Type: "string", Required: true},
+	}},
+	{Method: "POST", Path: "/password/reset/request", Summary: "Request a password reset", Body: []fieldDoc{
+		{Name: "user_id", Type: "string", Required: true},
+	}},
+	{Method: "POST", Path: "/password/reset/confirm", Summary: "Set a new password with a reset token", Body: []fieldDoc{
+		{Name: "token", Type: "string", Required: true},
+		{Name: "new_password", Type: "string", Required: true},
+	}},
+
+	// Authenticated routes
+	{Method: "GET", Path: "/balance", Summary: "Get the wallet balance", Auth: authUser},
+	{Method: "GET", Path: "/transactions", Summary: "List wallet transactions", Auth: authUser, Query: []fieldDoc{
+		{Name: "limit", Type: "integer"},
+		{Name: "offset", Type: "integer"},
+	}},
+	{Method: "GET", Path: "/transactions/stream", Summary: "Stream confirmations of wallet transactions as Server-Sent Events", Auth: authUser},
+	{Method: "GET", Path: "/transactions/{id}", Summary: "Get the status of a transaction", Auth: authUser},
+	{Method: "POST", Path: "/transfer", Summary: "Submit a payment", Auth: authUser, Body: []fieldDoc{
+		{Name: "receiver_address", Type: "string", Required: true},
+		{Name: "amount", Type: "number", Required: true},
+		{Name: "description", Type: "string"},
+		{Name: "private_key", Type: "string", Required: true},
+	}},
+	{Method: "GET", Path: "/wallet", Summary: "Get wallet information", Auth: authUser},
+	{Method: "POST", Path: "/wallet/pubkey", Summary: "Register the public key of the wallet", Auth: authUser, Body: []fieldDoc{
+		{Name: "public_key", Type: "string", Required: true},
+	}},
+	{Method: "GET", Path: "/wallet/pubkey/{address}", Summary: "Get the public key registered for a wallet address", Auth: authUser},
+	{Method: "POST", Path: "/logout", Summary: "Invalidate the current session", Auth: authUser},
+	{Method: "POST", Path: "/2fa/enroll", Summary: "Start enrolling in TOTP two-factor authentication", Auth: authUser},
+	{Method: "POST", Path: "/2fa/confirm", Summary: "Enable a pending TOTP enrollment with a code", Auth: authUser, Body: []fieldDoc{
+		{Name: "code", Type: "string", Required: true},
+	}},
+	{Method: "GET", Path: "/api-keys", Summary: "List API keys", Auth: authUser},
+	{Method: "POST", Path: "/api-keys/rotate", Summary: "Rotate an API key", Auth: authUser, Body: []fieldDoc{
+		{Name: "api_key", Type: "string", Required: true},
+	}},
+	{Method: "POST", Path: "/api-keys/revoke", Summary: "Revoke an API key", Auth: authUser, Body: []fieldDoc{
+		{Name: "id", Type: "string", Required: true},
+	}},
+	{Method: "GET", Path: "/orderbook", Summary: "Get the order book", Auth: authUser, Query: []fieldDoc{
+		{Name: "depth", Type: "integer"},
+	}},
+	{Method: "POST", Path: "/orders", Summary: "Place an order", Auth: authUser, Body: []fieldDoc{
+		{Name: "type", Type: "string", Required: true},
+		{Name: "price", Type: "number", Required: true},
+		{Name: "amount", Type: "number", Required: true},
+	}},
+	{Method: "DELETE", Path: "/orders/{id}", Summary: "Cancel an order", Auth: authUser},
+
+	// Admin routes
+	{Method: "GET", Path: "/admin/system/supply", Summary: "Get the total money supply", Auth: authAdmin},
+	{Method: "GET", Path: "/admin/system/inflation", Summary: "Get the current inflation rate", Auth: authAdmin},
+	{Method: "POST", Path: "/admin/system/adjust-inflation", Summary: "Adjust inflation bounds", Auth: authAdmin, Body: []fieldDoc{
+		{Name: "min_rate", Type: "number", Required: true},
+		{Name: "max_rate", Type: "number", Required: true},
+		{Name: "max_step", Type: "number", Required: true},
+	}},
+	{Method: "GET", Path: "/admin/ledger/verify", Summary: "Verify ledger integrity", Auth: authAdmin},
+	{Method: "GET", Path: "/admin/transactions/failed", Summary: "List failed transactions awaiting review", Auth: authAdmin, Query: []fieldDoc{
+		{Name: "limit", Type: "integer"},
+		{Name: "offset", Type: "integer"},
+	}},
+	{Method: "POST", Path: "/admin/transactions/{id}/requeue", Summary: "Resubmit a failed transaction as pending", Auth: authAdmin},
+}
+
+// apiErrorCodes lists the error codes an API response may carry
+var apiErrorCodes = []string{
+	apierrors.APIErrBadRequest,
+	apierrors.APIErrUnauthorized,
+	apierrors.APIErrForbidden,
+	apierrors.APIErrNotFound,
+	apierrors.APIErrMethodNotAllowed,
+	apierrors.APIErrConflict,
+	apierrors.APIErrInternalServer,
+	apierrors.APIErrServiceUnavailable,
+	apierrors.APIErrRateLimitExceeded,
+	apierrors.APIErrValidation,
+	apierrors.APIErrJWTInvalid,
+	apierrors.APIErrJWTExpired,
+}
+
+// buildOpenAPISpec builds an OpenAPI 3 document from the route table
+func buildOpenAPISpec(version string) map[string]interface{} {
+	responseRef := map[string]interface{}{"$ref": "#/components/schemas/Response"}
+	jsonResponse := func(description string) map[string]interface{} {
+		return map[string]interface{}{
+			"description": description,
+			"content": map[string]interface{}{
+				"application/json": map[string]interface{}{"schema": responseRef},
+			},
+		}
+	}
+
+	paths := make(map[string]interface{})
+	for _, route := range apiRoutes {
+		responses := map[string]interface{}{
+			"200": jsonResponse("Successful response"),
+			"400": jsonResponse("Invalid request"),
+			"429": jsonResponse("Rate limit exceeded"),
+			"500": jsonResponse("Internal server error"),
+		}
+
+		operation := map[string]interface{}{
+			"summary":     route.Summary,
+			"operationId": operationID(route),
+			"responses":   responses,
+		}
+
+		// Security requirements
+		if route.Auth != authNone {
+			operation["security"] = []interface{}{
+				map[string]interface{}{"bearerAuth": []string{}},
+				map[string]interface{}{"apiKeyAuth": []string{}},
+			}
+			responses["401"] = jsonResponse("Authentication required")
+			responses["503"] = jsonResponse("Authentication unavailable")
+		}
+		if route.Auth == authAdmin {
+			responses["403"] = jsonResponse("Admin role required")
+		}
+
+		// Path and query parameters
+		var parameters []interface{}
+		for _, name := range pathParams(route.Path) {
+			parameters = append(parameters, map[string]interface{}{
+				"name":     name,
+				"in":       "path",
+				"required": true,
+				"schema":   map[string]interface{}{"type": "string"},
+			})
+		}
+		for _, field := range route.Query {
+			parameters = append(parameters, map[string]interface{}{
+				"name":     field.Name,
+				"in":       "query",
+				"required": field.Required,
+				"schema":   map[string]interface{}{"type": field.Type},
+			})
+		}
+		if len(parameters) > 0 {
+			operation["parameters"] = parameters
+		}
+
+		// Request body
+		if len(route.Body) > 0 {
+			properties := make(map[string]interface{})
+			var required []string
+			for _, field := range route.Body {
+				properties[field.Name] = map[string]interface{}{"type": field.Type}
+				if field.Required {
+					required = append(required, field.Name)
+				}
+			}
+
+			schema := map[string]interface{}{
+				"type":       "object",
+				"properties": properties,
+			}
+			if len(required) > 0 {
+				schema["required"] = required
+			}
+
+			operation["requestBody"] = map[string]interface{}{
+				"required": true,
+				"content": map[string]interface{}{
+					"application/json": map[string]interface{}{"schema": schema},
+				},
+			}
+		}
+
+		item, ok := paths[route.Path].(map[string]interface{})
+		if !ok {
+			item = make(map[string]interface{})
+			paths[route.Path] = item
+		}
+		item[strings.ToLower(route.Method)] = operation
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":   "Stathera API",
+			"version": version,
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"securitySchemes": map[string]interface{}{
+				"bearerAuth": map[string]interface{}{
+					"type":         "http",
+					"scheme":       "bearer",
+					"bearerFormat": "JWT",
+				},
+				"apiKeyAuth": map[string]interface{}{
+					"type": "apiKey",
+					"in":   "header",
+					"name": "X-API-Key",
+				},
+			},
+			"schemas": map[string]interface{}{
+				"Response": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"success": map[string]interface{}{"type": "boolean"},
+						"message": map[string]interface{}{"type": "string"},
+						"data":    map[string]interface{}{"type": "object"},
+						"error":   map[string]interface{}{"type": "string"},
+						"code":    map[string]interface{}{"$ref": "#/components/schemas/ErrorCode"},
+					},
+					"required": []string{"success"},
+				},
+				"ErrorCode": map[string]interface{}{
+					"type": "string",
+					"enum": apiErrorCodes,
+				},
+			},
+		},
+	}
+}
+
+// operationID derives a stable operation ID from a route
+func operationID(route routeDoc) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(route.Method))
+
+	for _, part := range strings.FieldsFunc(route.Path, func(r rune) bool {
+		return r == '/' || r == '-' || r == '.' || r == '{' || r == '}'
+	}) {
+		b.WriteString(strings.ToUpper(part[:1]))
+		b.WriteString(part[1:])
+	}
+
+	return b.String()
+}
+
+// pathParams returns the names of the {param} segments in a route path
+func pathParams(path string) []string {
+	var params []string
+	for _, segment := range strings.Split(path, "/") {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			params = append(params, strings.Trim(segment, "{}"))
+		}
+	}
+	return params
+}
+
+// undocumentedRoutes returns routes registered on the router that are missing from the route table
+func undocumentedRoutes(router chi.Routes) []string {
+	documented := make(map[string]bool, len(apiRoutes))
+	for _, route := range apiRoutes {
+		documented[route.Method+" "+route.Path] = true
+	}
+
+	var missing []string
+	chi.Walk(router, func(method, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+		key := method + " " + route
+		if !documented[key] {
+			missing = append(missing, key)
+		}
+		return nil
+	})
+
+	sort.Strings(missing)
+	return missing
+}
+
+// handleOpenAPI serves the OpenAPI document
+func (s *Server) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	s.renderJSON(w, buildOpenAPISpec(s.config.API.Version), http.StatusOK)
+}
@@ -0,0 +1,164 @@
+// internal/api/param_validator.go
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ParamType is the expected type of a request parameter
+type ParamType string
+
+const (
+	// ParamString accepts any text
+	ParamString ParamType = "string"
+	// ParamInt accepts base-10 integers
+	ParamInt ParamType = "int"
+	// ParamFloat accepts floating point numbers
+	ParamFloat ParamType = "float"
+)
+
+// ParamRule describes the accepted shape of a single request parameter
+type ParamRule struct {
+	// Type is the expected type of the value (defaults to ParamString)
+	Type ParamType
+	// MaxLength is the maximum length in bytes (zero means unlimited)
+	MaxLength int
+	// AllowedChars restricts the value to the given characters (empty means any)
+	AllowedChars string
+}
+
+// ParamValidator validates query parameters against per-parameter rules.
+// Every value is rejected if it contains control characters or null bytes;
+// strict mode additionally rejects common SQL injection and XSS patterns.
+type ParamValidator struct {
+	rules  map[string]ParamRule
+	strict bool
+}
+
+// NewParamValidator creates a new parameter validator
+func NewParamValidator(strict bool) *ParamValidator {
+	return &ParamValidator{
+		rules:  make(map[string]ParamRule),
+		strict: strict,
+	}
+}
+
+// WithRule registers a rule for a parameter and returns the validator
+func (v *ParamValidator) WithRule(param string, rule ParamRule) *ParamValidator {
+	v.rules[param] = rule
+	return v
+}
+
+// Validate checks a single parameter value
+func (v *ParamValidator) Validate(param, value string) error {
+	// Always reject control characters and null bytes
+	for _, char := range value {
+		if char == 0 || (unicode.IsControl(char) && char != '\t') {
+			return fmt.Errorf("parameter %s contains control characters", param)
+		}
+	}
+
+	if rule, ok := v.rules[param]; ok {
+		if err := rule.validate(param, value); err != nil {
+			return err
+		}
+	}
+
+	if v.strict {
+		if containsSQLInjection(value) {
+			return fmt.Errorf("parameter %s contains a disallowed SQL pattern", param)
+		}
+		if containsXSS(value) {
+			return fmt.Errorf("parameter %s contains a disallowed script pattern", param)
+		}
+	}
+
+	return nil
+}
+
+// ValidateRequest checks all query parameters of a request
+func (v *ParamValidator) ValidateRequest(r *http.Request) error {
+	for key, values := range r.URL.Query() {
+		for _, value := range values {
+			if err := v.Validate(key, value); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// validate checks a value against the rule
+func (rule ParamRule) validate(param, value string) error {
+	if rule.MaxLength > 0 && len(value) > rule.MaxLength {
+		return fmt.Errorf("parameter %s exceeds maximum length of %d", param, rule.MaxLength)
+	}
+
+	if rule.AllowedChars != "" {
+		for _, char := range value {
+			if !strings.ContainsRune(rule.AllowedChars, char) {
+				return fmt.Errorf("parameter %s contains invalid character %q", param, char)
+			}
+		}
+	}
+
+	// Empty values are left to the handler to default or reject
+	if value == "" {
+		return nil
+	}
+
+	switch rule.Type {
+	case ParamInt:
+		if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+			return fmt.Errorf("parameter %s must be an integer", param)
+		}
+	case ParamFloat:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return fmt.Errorf("parameter %s must be a number", param)
+		}
+	}
+
+	return nil
+}
+
+// containsSQLInjection checks if a string contains SQL injection patterns.
+// Patterns are matched as SQL fragments rather than bare keywords so ordinary
+// text such as "select products from catalog" is not rejected.
+func containsSQLInjection(s string) bool {
+	patterns := []string{
+		"'--", "' or ", "\" or ", "' and ", "';", "\";", "/*", "*/", "xp_cmdshell",
+		"union select", "union all select", "; drop ", ";drop ", "; delete ", "; insert ",
+		"; update ", "; exec", "sleep(", "benchmark(", "waitfor delay",
+	}
+
+	lowered := strings.ToLower(s)
+	for _, pattern := range patterns {
+		if strings.Contains(lowered, pattern) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// containsXSS checks if a string contains XSS patterns
+func containsXSS(s string) bool {
+	patterns := []string{
+		"<script", "javascript:", "vbscript:", "onerror=", "onload=", "onmouseover=", "eval(",
+		"document.cookie", "<iframe", "<svg", "<img", "<object", "<embed",
+	}
+
+	lowered := strings.ToLower(s)
+	for _, pattern := range patterns {
+		if strings.Contains(lowered, pattern) {
+			return true
+		}
+	}
+
+	return false
+}
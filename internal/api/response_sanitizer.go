@@ -0,0 +1,162 @@
+// internal/api/response_sanitizer.go
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+const (
+	// redactedValue replaces the value of sensitive response fields
+	redactedValue = "[REDACTED]"
+
+	// maxSanitizedResponseSize is the largest response buffered for sanitization;
+	// larger or streamed responses are passed through unchanged
+	maxSanitizedResponseSize = 1 << 20
+)
+
+// bufferedResponseWriter buffers a response so it can be rewritten before it
+// reaches the client, falling back to pass-through when the body grows past
+// the limit or the handler flushes
+type bufferedResponseWriter struct {
+	w           http.ResponseWriter
+	buf         bytes.Buffer
+	status      int
+	limit       int
+	passthrough bool
+}
+
+// newBufferedResponseWriter creates a buffered writer with the given size limit
+func newBufferedResponseWriter(w http.ResponseWriter, limit int) *bufferedResponseWriter {
+	return &bufferedResponseWriter{
+		w:      w,
+		status: http.StatusOK,
+		limit:  limit,
+	}
+}
+
+// Header returns the underlying response headers
+func (b *bufferedResponseWriter) Header() http.Header {
+	return b.w.Header()
+}
+
+// WriteHeader records the status code until the response is written
+func (b *bufferedResponseWriter) WriteHeader(code int) {
+	if b.passthrough {
+		return
+	}
+	b.status = code
+}
+
+// Write buffers the body, or writes it directly once in pass-through mode
+func (b *bufferedResponseWriter) Write(p []byte) (int, error) {
+	if b.passthrough {
+		return b.w.Write(p)
+	}
+
+	if b.buf.Len()+len(p) > b.limit {
+		if err := b.startPassthrough(); err != nil {
+			return 0, err
+		}
+		return b.w.Write(p)
+	}
+
+	return b.buf.Write(p)
+}
+
+// Flush switches to pass-through so streamed responses are not held back
+func (b *bufferedResponseWriter) Flush() {
+	if !b.passthrough {
+		if err := b.startPassthrough(); err != nil {
+			return
+		}
+	}
+
+	if flusher, ok := b.w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// startPassthrough writes out anything buffered and stops buffering
+func (b *bufferedResponseWriter) startPassthrough() error {
+	b.passthrough = true
+	b.w.WriteHeader(b.status)
+
+	_, err := b.w.Write(b.buf.Bytes())
+	b.buf.Reset()
+	return err
+}
+
+// finish writes the buffered response after applying the rewrite function
+func (b *bufferedResponseWriter) finish(rewrite func(body []byte) ([]byte, bool)) error {
+	if b.passthrough {
+		return nil
+	}
+
+	body := b.buf.Bytes()
+	if rewritten, changed := rewrite(body); changed {
+		body = rewritten
+		b.w.Header().Del("Content-Length")
+	}
+
+	b.w.WriteHeader(b.status)
+	_, err := b.w.Write(body)
+	return err
+}
+
+// redactJSON replaces the values of sensitive keys anywhere in a JSON document.
+// It reports false if the body is not JSON or nothing was redacted.
+func redactJSON(body []byte, fields map[string]bool) ([]byte, bool) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var doc interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, false
+	}
+
+	if !redactValue(doc, fields) {
+		return nil, false
+	}
+
+	out, err := json.Marshal(doc)
+	if err != nil {
+		return nil, false
+	}
+
+	// Keep the trailing newline written by json.Encoder
+	if bytes.HasSuffix(body, []byte("\n")) {
+		out = append(out, '\n')
+	}
+
+	return out, true
+}
+
+// redactValue walks a decoded JSON value and redacts sensitive keys in place
+func redactValue(v interface{}, fields map[string]bool) bool {
+	changed := false
+
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for key, child := range val {
+			if fields[strings.ToLower(key)] {
+				val[key] = redactedValue
+				changed = true
+				continue
+			}
+			if redactValue(child, fields) {
+				changed = true
+			}
+		}
+	case []interface{}:
+		for _, child := range val {
+			if redactValue(child, fields) {
+				changed = true
+			}
+		}
+	}
+
+	return changed
+}
@@ -2,14 +2,26 @@
 package api
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
+	"math"
 	"net/http"
+	"path"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/cmatc13/stathera/internal/security"
+	"github.com/cmatc13/stathera/pkg/config"
 	"github.com/cmatc13/stathera/pkg/logging"
+	"github.com/cmatc13/stathera/pkg/metrics"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/jwtauth/v5"
@@ -20,17 +32,72 @@ type SecurityMiddleware struct {
 	securityManager *security.SecurityManager
 	tokenAuth       *jwtauth.JWTAuth
 	logger          *logging.Logger
+	accessLogger    *logging.Logger
+	metrics         *metrics.Metrics
+	redactedFields  map[string]bool
+
+	accessLogExclude map[string]bool
+	fastRequest      time.Duration
+	slowRequest      time.Duration
 }
 
+// Default latency bucket thresholds for the access log
+const (
+	defaultFastRequest = 100 * time.Millisecond
+	defaultSlowRequest = time.Second
+)
+
+// defaultRedactedFields are response fields redacted by ResponseSanitization
+var defaultRedactedFields = []string{"private_key", "password", "password_hash"}
+
 // NewSecurityMiddleware creates a new security middleware
 func NewSecurityMiddleware(securityManager *security.SecurityManager, tokenAuth *jwtauth.JWTAuth, logger *logging.Logger) *SecurityMiddleware {
-	return &SecurityMiddleware{
+	sm := &SecurityMiddleware{
 		securityManager: securityManager,
 		tokenAuth:       tokenAuth,
 		logger:          logger,
+		accessLogger:    logger,
+		fastRequest:     defaultFastRequest,
+		slowRequest:     defaultSlowRequest,
+	}
+	sm.SetRedactedFields(defaultRedactedFields)
+
+	return sm
+}
+
+// SetRedactedFields sets the response field names redacted by ResponseSanitization
+func (sm *SecurityMiddleware) SetRedactedFields(fields []string) {
+	sm.redactedFields = make(map[string]bool, len(fields))
+	for _, field := range fields {
+		sm.redactedFields[strings.ToLower(field)] = true
 	}
 }
 
+// SetAccessLogPolicy sets the paths whose successful requests RequestLogging
+// leaves out, and the thresholds that split requests into fast, normal and
+// slow latency buckets
+func (sm *SecurityMiddleware) SetAccessLogPolicy(exclude []string, fast, slow time.Duration) {
+	sm.accessLogExclude = make(map[string]bool, len(exclude))
+	for _, path := range exclude {
+		sm.accessLogExclude[path] = true
+	}
+	if fast > 0 && slow > fast {
+		sm.fastRequest = fast
+		sm.slowRequest = slow
+	}
+}
+
+// SetAccessLogger sets the logger RequestLogging writes access log lines to,
+// such as a sampled one. Handlers keep logging through the main logger.
+func (sm *SecurityMiddleware) SetAccessLogger(logger *logging.Logger) {
+	sm.accessLogger = logger
+}
+
+// SetMetrics sets the collector that records security events
+func (sm *SecurityMiddleware) SetMetrics(m *metrics.Metrics) {
+	sm.metrics = m
+}
+
 // APIKeyAuth is middleware that validates API keys
 func (sm *SecurityMiddleware) APIKeyAuth(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -43,13 +110,24 @@ func (sm *SecurityMiddleware) APIKeyAuth(next http.Handler) http.Handler {
 		}
 
 		// Validate API key
-		userID, permissions, err := sm.securityManager.ValidateAPIKey(apiKey)
+		userID, permissions, err := sm.securityManager.ValidateAPIKey(r.Context(), apiKey)
 		if err != nil {
 			sm.logger.Warn("Invalid API key",
 				"remote_addr", r.RemoteAddr,
 				"path", r.URL.Path,
 				"error", err.Error(),
 			)
+			if sm.metrics != nil {
+				sm.metrics.RecordInvalidAPIKey()
+			}
+			if errors.Is(err, security.ErrAPIKeyExpired) {
+				http.Error(w, "API key expired", http.StatusUnauthorized)
+				return
+			}
+			if errors.Is(err, security.ErrAPIKeyRevoked) {
+				http.Error(w, "API key revoked", http.StatusUnauthorized)
+				return
+			}
 			http.Error(w, "Invalid API key", http.StatusUnauthorized)
 			return
 		}
@@ -64,6 +142,42 @@ func (sm *SecurityMiddleware) APIKeyAuth(next http.Handler) http.Handler {
 	})
 }
 
+// SessionValidation is middleware that rejects JWTs whose server-side session
+// has expired or been invalidated
+func (sm *SecurityMiddleware) SessionValidation(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// API key authenticated requests have no session
+		if authMethod, ok := r.Context().Value("auth_method").(string); ok && authMethod == "api_key" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// Get session ID from the token, falling back to the cookie
+		sessionID := ""
+		if _, claims, err := jwtauth.FromContext(r.Context()); err == nil {
+			sessionID, _ = claims["session_id"].(string)
+		}
+		if sessionID == "" {
+			if cookie, err := r.Cookie("session_id"); err == nil {
+				sessionID = cookie.Value
+			}
+		}
+
+		if !sm.securityManager.IsSessionValid(r.Context(), sessionID) {
+			sm.logger.Warn("Invalid session",
+				"remote_addr", r.RemoteAddr,
+				"path", r.URL.Path,
+			)
+			http.Error(w, "Session expired or invalid", http.StatusUnauthorized)
+			return
+		}
+
+		// Store session ID in context
+		ctx := context.WithValue(r.Context(), "session_id", sessionID)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
 // CSRFProtection is middleware that validates CSRF tokens
 func (sm *SecurityMiddleware) CSRFProtection(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -92,6 +206,9 @@ func (sm *SecurityMiddleware) CSRFProtection(next http.Handler) http.Handler {
 				"remote_addr", r.RemoteAddr,
 				"path", r.URL.Path,
 			)
+			if sm.metrics != nil {
+				sm.metrics.RecordCSRFFailure()
+			}
 			http.Error(w, "CSRF validation failed", http.StatusForbidden)
 			return
 		}
@@ -103,16 +220,22 @@ func (sm *SecurityMiddleware) CSRFProtection(next http.Handler) http.Handler {
 				"remote_addr", r.RemoteAddr,
 				"path", r.URL.Path,
 			)
+			if sm.metrics != nil {
+				sm.metrics.RecordCSRFFailure()
+			}
 			http.Error(w, "CSRF validation failed", http.StatusForbidden)
 			return
 		}
 
 		// Validate CSRF token
-		if !sm.securityManager.ValidateCSRFToken(sessionID, csrfToken) {
+		if !sm.securityManager.ValidateCSRFToken(r.Context(), sessionID, csrfToken) {
 			sm.logger.Warn("CSRF validation failed: invalid token",
 				"remote_addr", r.RemoteAddr,
 				"path", r.URL.Path,
 			)
+			if sm.metrics != nil {
+				sm.metrics.RecordCSRFFailure()
+			}
 			http.Error(w, "CSRF validation failed", http.StatusForbidden)
 			return
 		}
@@ -124,8 +247,15 @@ func (sm *SecurityMiddleware) CSRFProtection(next http.Handler) http.Handler {
 
 // RateLimiter is middleware that implements rate limiting per user/IP
 func (sm *SecurityMiddleware) RateLimiter(limit int, period time.Duration) func(next http.Handler) http.Handler {
+	return sm.DynamicRateLimiter(NewRateLimit(limit, period))
+}
+
+// DynamicRateLimiter is a rate limiter whose thresholds can be changed at runtime
+func (sm *SecurityMiddleware) DynamicRateLimiter(rateLimit *RateLimit) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			limit, period, scope := rateLimit.For(r.URL.Path)
+
 			// Determine rate limit key (user ID or IP)
 			var key string
 			if userID, ok := r.Context().Value("user_id").(string); ok && userID != "" {
@@ -136,11 +266,11 @@ func (sm *SecurityMiddleware) RateLimiter(limit int, period time.Duration) func(
 				key = "ip:" + r.RemoteAddr
 			}
 
-			// Add path to make rate limits more granular
-			key = key + ":" + r.URL.Path
+			// Add the path, or the matching route pattern, to make rate limits more granular
+			key = key + ":" + scope
 
 			// Check rate limit
-			allowed, err := sm.securityManager.CheckRateLimit(key, limit, period)
+			status, err := sm.securityManager.CheckRateLimit(r.Context(), key, limit, period)
 			if err != nil {
 				sm.logger.Error("Rate limit check failed",
 					"error", err.Error(),
@@ -152,13 +282,22 @@ func (sm *SecurityMiddleware) RateLimiter(limit int, period time.Duration) func(
 				return
 			}
 
-			if !allowed {
+			// Report quota usage on every response
+			resetSeconds := int(math.Ceil(status.ResetIn.Seconds()))
+			w.Header().Set("X-RateLimit-Limit", strconv.Itoa(status.Limit))
+			w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(status.Remaining()))
+			w.Header().Set("X-RateLimit-Reset", strconv.Itoa(resetSeconds))
+
+			if !status.Allowed {
 				sm.logger.Warn("Rate limit exceeded",
 					"remote_addr", r.RemoteAddr,
 					"path", r.URL.Path,
 					"key", key,
 				)
-				w.Header().Set("Retry-After", fmt.Sprintf("%d", int(period.Seconds())))
+				if sm.metrics != nil {
+					sm.metrics.RecordRateLimited(r.URL.Path)
+				}
+				w.Header().Set("Retry-After", strconv.Itoa(resetSeconds))
 				http.Error(w, "Rate limit exceeded", http.StatusTooManyRequests)
 				return
 			}
@@ -180,6 +319,101 @@ func (sm *SecurityMiddleware) ContentSecurityPolicy(next http.Handler) http.Hand
 	})
 }
 
+// RateLimit holds rate limit thresholds that can be updated while serving
+type RateLimit struct {
+	mu     sync.RWMutex
+	limit  int
+	period time.Duration
+	routes []routeRateLimit
+}
+
+// routeRateLimit is a rate limit for the requests whose path matches pattern
+type routeRateLimit struct {
+	pattern string
+	limit   int
+	period  time.Duration
+}
+
+// NewRateLimit creates a new rate limit
+func NewRateLimit(limit int, period time.Duration) *RateLimit {
+	return &RateLimit{
+		limit:  limit,
+		period: period,
+	}
+}
+
+// Get returns the current limit and period
+func (rl *RateLimit) Get() (int, time.Duration) {
+	rl.mu.RLock()
+	defer rl.mu.RUnlock()
+	return rl.limit, rl.period
+}
+
+// Set updates the limit and period
+func (rl *RateLimit) Set(limit int, period time.Duration) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.limit = limit
+	rl.period = period
+}
+
+// SetRoutes replaces the per-route limits. Patterns are request paths or
+// path.Match globs such as /transactions/*; requests matching no pattern use
+// the global limit.
+func (rl *RateLimit) SetRoutes(routes map[string]config.RouteRateLimit) {
+	limits := make([]routeRateLimit, 0, len(routes))
+	for pattern, route := range routes {
+		limits = append(limits, routeRateLimit{pattern: pattern, limit: route.Requests, period: route.Window})
+	}
+
+	// Try exact paths first, then the most specific patterns
+	sort.Slice(limits, func(i, j int) bool {
+		iExact, jExact := !hasGlob(limits[i].pattern), !hasGlob(limits[j].pattern)
+		if iExact != jExact {
+			return iExact
+		}
+		if len(limits[i].pattern) != len(limits[j].pattern) {
+			return len(limits[i].pattern) > len(limits[j].pattern)
+		}
+		return limits[i].pattern < limits[j].pattern
+	})
+
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.routes = limits
+}
+
+// For returns the limit and period for a request path, along with the scope
+// its requests are counted under: the matching route pattern, or the path
+// itself when the global limit applies
+func (rl *RateLimit) For(requestPath string) (int, time.Duration, string) {
+	if route, ok := rl.route(requestPath); ok {
+		return route.limit, route.period, route.pattern
+	}
+
+	rl.mu.RLock()
+	defer rl.mu.RUnlock()
+	return rl.limit, rl.period, requestPath
+}
+
+// route returns the per-route limit matching a request path, if any
+func (rl *RateLimit) route(requestPath string) (routeRateLimit, bool) {
+	rl.mu.RLock()
+	defer rl.mu.RUnlock()
+
+	for _, route := range rl.routes {
+		if matched, _ := path.Match(route.pattern, requestPath); matched {
+			return route, true
+		}
+	}
+	return routeRateLimit{}, false
+}
+
+// hasGlob reports whether a route pattern contains glob metacharacters
+func hasGlob(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?[\\")
+}
+
 // SecureHeaders adds security-related headers to responses
 func (sm *SecurityMiddleware) SecureHeaders(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -226,67 +460,6 @@ func (sm *SecurityMiddleware) InputSanitization(next http.Handler) http.Handler
 	})
 }
 
-// JWTWithBruteForceProtection enhances JWT authentication with brute force protection
-func (sm *SecurityMiddleware) JWTWithBruteForceProtection(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Get token from request
-		token, _, err := jwtauth.FromContext(r.Context())
-
-		// If no token or invalid token, check for brute force attempts
-		if err != nil || token == nil {
-			// Extract username from request (e.g., from a failed login attempt)
-			// This is just an example - in a real implementation, you would
-			// extract the username from the request body or parameters
-			username := r.FormValue("username")
-			if username != "" {
-				// Record failed login attempt
-				userID := "user:" + username
-				err := sm.securityManager.RecordFailedLogin(userID)
-				if err != nil {
-					sm.logger.Error("Failed to record failed login",
-						"error", err.Error(),
-						"username", username,
-					)
-				}
-
-				// Check if user is allowed to login
-				allowed, err := sm.securityManager.CheckLoginAllowed(userID)
-				if err != nil {
-					sm.logger.Error("Failed to check login allowed",
-						"error", err.Error(),
-						"username", username,
-					)
-				} else if !allowed {
-					sm.logger.Warn("Login blocked due to too many failed attempts",
-						"username", username,
-					)
-					http.Error(w, "Too many failed login attempts. Please try again later.", http.StatusTooManyRequests)
-					return
-				}
-			}
-
-			// Continue to standard JWT authenticator which will handle the error
-			next.ServeHTTP(w, r)
-			return
-		}
-
-		// If token is valid, reset failed login counter
-		if claims, ok := token.PrivateClaims()["username"].(string); ok && claims != "" {
-			userID := "user:" + claims
-			err := sm.securityManager.ResetFailedLogins(userID)
-			if err != nil {
-				sm.logger.Error("Failed to reset failed logins",
-					"error", err.Error(),
-					"username", claims,
-				)
-			}
-		}
-
-		// Continue with the request
-		next.ServeHTTP(w, r)
-	})
-}
-
 // RequirePermission middleware checks if the user has the required permission
 func (sm *SecurityMiddleware) RequirePermission(requiredPermission string) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
@@ -378,154 +551,222 @@ func (sm *SecurityMiddleware) RequestValidation(validator func(r *http.Request)
 	}
 }
 
-// ResponseSanitization middleware sanitizes response data
-func (sm *SecurityMiddleware) ResponseSanitization(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Create a response wrapper to intercept the response
-		wrapper := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+// defaultMaxBodySize is the body size limit used when none is configured
+const defaultMaxBodySize = 64 << 10
 
-		// Continue with the wrapped response writer
-		next.ServeHTTP(wrapper, r)
+// BodyLimit middleware rejects request bodies larger than the configured
+// limit for the request path with 413 Request Entity Too Large
+func (sm *SecurityMiddleware) BodyLimit(defaultLimit int64, routeLimits map[string]int64) func(next http.Handler) http.Handler {
+	if defaultLimit <= 0 {
+		defaultLimit = defaultMaxBodySize
+	}
 
-		// Note: In a real implementation, you would inspect and potentially
-		// modify the response body here. This would require buffering the
-		// response and then writing it out after sanitization.
-		// For simplicity, we're just adding a header to indicate sanitization.
-		wrapper.Header().Set("X-Content-Sanitized", "true")
-	})
-}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Body == nil || r.Body == http.NoBody {
+				next.ServeHTTP(w, r)
+				return
+			}
 
-// SQLInjectionProtection middleware protects against SQL injection
-func (sm *SecurityMiddleware) SQLInjectionProtection(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Check query parameters for SQL injection patterns
-		for key, values := range r.URL.Query() {
-			for _, value := range values {
-				if containsSQLInjection(value) {
-					sm.logger.Warn("Potential SQL injection detected",
-						"param", key,
-						"value", value,
-						"remote_addr", r.RemoteAddr,
-					)
-					http.Error(w, "Invalid request", http.StatusBadRequest)
-					return
-				}
+			limit := defaultLimit
+			if routeLimit, ok := routeLimits[r.URL.Path]; ok {
+				limit = routeLimit
 			}
-		}
 
-		// Continue with the request
-		next.ServeHTTP(w, r)
-	})
-}
+			// Reject declared oversized bodies without reading them
+			if r.ContentLength > limit {
+				sm.rejectOversizedBody(w, r, limit)
+				return
+			}
 
-// containsSQLInjection checks if a string contains SQL injection patterns
-func containsSQLInjection(s string) bool {
-	// This is a very basic check - in a real implementation, you would use
-	// a more sophisticated approach, such as a SQL parser or a dedicated library
-	patterns := []string{
-		"'", "--", ";", "/*", "*/", "xp_", "sp_", "exec", "select", "drop", "update", "delete", "insert",
-		"union", "where", "from", "having", "group by", "order by",
-	}
+			// Read the body up front so streamed bodies are also rejected with 413
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, limit))
+			if err != nil {
+				var maxBytesErr *http.MaxBytesError
+				if errors.As(err, &maxBytesErr) {
+					sm.rejectOversizedBody(w, r, limit)
+					return
+				}
+				http.Error(w, "Failed to read request body", http.StatusBadRequest)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
 
-	lowered := strings.ToLower(s)
-	for _, pattern := range patterns {
-		if strings.Contains(lowered, pattern) {
-			return true
-		}
+			next.ServeHTTP(w, r)
+		})
 	}
+}
 
-	return false
+// rejectOversizedBody responds to a request whose body exceeds the limit
+func (sm *SecurityMiddleware) rejectOversizedBody(w http.ResponseWriter, r *http.Request, limit int64) {
+	sm.logger.Warn("Request body too large",
+		"path", r.URL.Path,
+		"remote_addr", r.RemoteAddr,
+		"limit", limit,
+	)
+	http.Error(w, "Request Entity Too Large", http.StatusRequestEntityTooLarge)
 }
 
-// XSSProtection middleware protects against cross-site scripting
-func (sm *SecurityMiddleware) XSSProtection(next http.Handler) http.Handler {
+// ResponseSanitization middleware sanitizes response data
+func (sm *SecurityMiddleware) ResponseSanitization(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Check query parameters for XSS patterns
-		for key, values := range r.URL.Query() {
-			for _, value := range values {
-				if containsXSS(value) {
-					sm.logger.Warn("Potential XSS attack detected",
-						"param", key,
-						"value", value,
-						"remote_addr", r.RemoteAddr,
-					)
-					http.Error(w, "Invalid request", http.StatusBadRequest)
-					return
-				}
+		// Buffer the response so it can be inspected before it is sent
+		buffered := newBufferedResponseWriter(w, maxSanitizedResponseSize)
+
+		// Continue with the buffered response writer
+		next.ServeHTTP(buffered, r)
+
+		// Redact sensitive fields from JSON responses
+		err := buffered.finish(func(body []byte) ([]byte, bool) {
+			if !strings.Contains(w.Header().Get("Content-Type"), "application/json") {
+				return nil, false
 			}
-		}
 
-		// Continue with the request
-		next.ServeHTTP(w, r)
+			sanitized, changed := redactJSON(body, sm.redactedFields)
+			if changed {
+				w.Header().Set("X-Content-Sanitized", "true")
+			}
+			return sanitized, changed
+		})
+		if err != nil {
+			sm.logger.Warn("Failed to write sanitized response",
+				"path", r.URL.Path,
+				"error", err.Error(),
+			)
+		}
 	})
 }
 
-// containsXSS checks if a string contains XSS patterns
-func containsXSS(s string) bool {
-	// This is a very basic check - in a real implementation, you would use
-	// a more sophisticated approach, such as an HTML parser or a dedicated library
-	patterns := []string{
-		"<script", "javascript:", "onerror=", "onload=", "eval(", "document.cookie",
-		"alert(", "prompt(", "confirm(", "<iframe", "<img", "<svg",
-	}
+// maxIdempotencyKeyLength is the longest Idempotency-Key header accepted
+const maxIdempotencyKeyLength = 255
 
-	lowered := strings.ToLower(s)
-	for _, pattern := range patterns {
-		if strings.Contains(lowered, pattern) {
-			return true
+// Idempotency middleware replays the stored response for requests repeating an
+// Idempotency-Key header instead of executing them again. It must run after
+// authentication, as keys are scoped to the authenticated user.
+func (sm *SecurityMiddleware) Idempotency(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("Idempotency-Key")
+		if key == "" {
+			next.ServeHTTP(w, r)
+			return
 		}
-	}
 
-	return false
-}
+		if len(key) > maxIdempotencyKeyLength {
+			http.Error(w, "Idempotency-Key is too long", http.StatusBadRequest)
+			return
+		}
 
-// JWTRenewal middleware handles JWT token renewal
-func (sm *SecurityMiddleware) JWTRenewal(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Get token from request
-		token, _, err := jwtauth.FromContext(r.Context())
-		if err != nil || token == nil {
-			// No token or invalid token, continue to standard JWT authenticator
-			next.ServeHTTP(w, r)
+		_, claims, err := jwtauth.FromContext(r.Context())
+		if err != nil {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
 			return
 		}
 
-		// Check if token is about to expire
-		if claims, ok := token.PrivateClaims()["exp"].(float64); ok {
-			expTime := time.Unix(int64(claims), 0)
-			renewThreshold := time.Now().Add(15 * time.Minute)
-
-			if expTime.Before(renewThreshold) {
-				// Token is about to expire, issue a new one
-				// Extract necessary claims from the current token
-				userID, _ := token.PrivateClaims()["user_id"].(string)
-				username, _ := token.PrivateClaims()["username"].(string)
-				role, _ := token.PrivateClaims()["role"].(string)
-				walletAddress, _ := token.PrivateClaims()["wallet_address"].(string)
-
-				// Create new claims with extended expiration
-				newClaims := map[string]interface{}{
-					"user_id":        userID,
-					"username":       username,
-					"role":           role,
-					"wallet_address": walletAddress,
-					"exp":            time.Now().Add(24 * time.Hour).Unix(),
-				}
+		userID, ok := claims["user_id"].(string)
+		if !ok {
+			http.Error(w, "Invalid token claims", http.StatusBadRequest)
+			return
+		}
 
-				// Generate new token
-				_, newTokenString, err := sm.tokenAuth.Encode(newClaims)
-				if err == nil {
-					// Set the new token in the response header
-					w.Header().Set("X-New-Token", newTokenString)
-				}
+		// Fingerprint the request so a key cannot be reused for a different one
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "Failed to read request body", http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		digest := sha256.New()
+		digest.Write([]byte(r.Method + " " + r.URL.Path + "\n"))
+		digest.Write(body)
+		fingerprint := hex.EncodeToString(digest.Sum(nil))
+
+		stored, err := sm.securityManager.BeginIdempotentRequest(r.Context(), userID, key, fingerprint)
+		switch {
+		case errors.Is(err, security.ErrIdempotencyInProgress):
+			http.Error(w, "A request with this Idempotency-Key is already in progress", http.StatusConflict)
+			return
+		case errors.Is(err, security.ErrIdempotencyKeyReused):
+			http.Error(w, "Idempotency-Key was already used for a different request", http.StatusUnprocessableEntity)
+			return
+		case err != nil:
+			sm.logger.Error("Failed to check idempotency key",
+				"path", r.URL.Path,
+				"error", err.Error(),
+			)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		// Replay the stored response
+		if stored != nil {
+			if stored.ContentType != "" {
+				w.Header().Set("Content-Type", stored.ContentType)
 			}
+			w.Header().Set("Idempotent-Replayed", "true")
+			w.WriteHeader(stored.Status)
+			w.Write(stored.Body)
+			return
 		}
 
-		// Continue with the request
-		next.ServeHTTP(w, r)
+		// Capture the response so it can be stored
+		buffered := newBufferedResponseWriter(w, maxSanitizedResponseSize)
+		next.ServeHTTP(buffered, r)
+
+		// The request has taken effect, so record its outcome even if the client has gone away
+		ctx := context.WithoutCancel(r.Context())
+
+		// Server errors and streamed responses are not stored, so the request can be retried
+		if buffered.passthrough || buffered.status >= http.StatusInternalServerError {
+			if err := sm.securityManager.AbandonIdempotentRequest(ctx, userID, key); err != nil {
+				sm.logger.Warn("Failed to release idempotency key",
+					"path", r.URL.Path,
+					"error", err.Error(),
+				)
+			}
+		} else {
+			response := security.IdempotentResponse{
+				Status:      buffered.status,
+				ContentType: w.Header().Get("Content-Type"),
+				Body:        append([]byte(nil), buffered.buf.Bytes()...),
+			}
+			if err := sm.securityManager.CompleteIdempotentRequest(ctx, userID, key, fingerprint, response); err != nil {
+				sm.logger.Warn("Failed to store idempotent response",
+					"path", r.URL.Path,
+					"error", err.Error(),
+				)
+			}
+		}
+
+		if err := buffered.finish(func(body []byte) ([]byte, bool) { return nil, false }); err != nil {
+			sm.logger.Warn("Failed to write response",
+				"path", r.URL.Path,
+				"error", err.Error(),
+			)
+		}
 	})
 }
 
+// ParamValidation middleware validates query parameters against a validator
+func (sm *SecurityMiddleware) ParamValidation(validator *ParamValidator) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := validator.ValidateRequest(r); err != nil {
+				sm.logger.Warn("Query parameter validation failed",
+					"path", r.URL.Path,
+					"remote_addr", r.RemoteAddr,
+					"error", err.Error(),
+				)
+				http.Error(w, "Invalid request", http.StatusBadRequest)
+				return
+			}
+
+			// Continue with the request
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 // ErrorHandling middleware provides consistent error handling
 func (sm *SecurityMiddleware) ErrorHandling(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -587,18 +828,30 @@ func (sm *SecurityMiddleware) RequestLogging(next http.Handler) http.Handler {
 		// Create a response writer wrapper to capture the status code
 		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
 
-		// Get request ID from context if available
-		requestID := middleware.GetReqID(r.Context())
+		// Propagate a W3C trace ID if the caller sent one
+		ctx := r.Context()
+		if traceID := traceIDFromHeader(r.Header.Get("traceparent")); traceID != "" {
+			ctx = logging.ContextWithTraceID(ctx, traceID)
+		}
+
+		// Stash a request-scoped logger carrying the request and trace IDs
+		ctx = logging.NewContext(ctx, sm.logger)
+		r = r.WithContext(ctx)
+		logger := sm.accessLogger.WithContext(ctx)
+
+		// Excluded paths, such as health checks, are only logged when they fail
+		excluded := sm.accessLogExclude[r.URL.Path]
 
 		// Log request start with security-relevant information
-		sm.logger.Info("Request started",
-			"method", r.Method,
-			"path", r.URL.Path,
-			"remote_addr", r.RemoteAddr,
-			"request_id", requestID,
-			"user_agent", r.UserAgent(),
-			"referer", r.Referer(),
-		)
+		if !excluded {
+			logger.Info("Request started",
+				"method", r.Method,
+				"path", r.URL.Path,
+				"remote_addr", r.RemoteAddr,
+				"user_agent", r.UserAgent(),
+				"referer", r.Referer(),
+			)
+		}
 
 		// Call the next handler
 		next.ServeHTTP(ww, r)
@@ -609,35 +862,58 @@ func (sm *SecurityMiddleware) RequestLogging(next http.Handler) http.Handler {
 		if status == 0 {
 			status = http.StatusOK // Default to 200 if status not explicitly set
 		}
+		latencyBucket := sm.latencyBucket(duration)
 
 		// Determine log level based on status code
 		if status >= 500 {
-			sm.logger.Error("Request completed with server error",
+			logger.Error("Request completed with server error",
 				"method", r.Method,
 				"path", r.URL.Path,
 				"status", status,
 				"duration_ms", duration.Milliseconds(),
-				"request_id", requestID,
+				"latency_bucket", latencyBucket,
 				"user_id", r.Context().Value("user_id"),
 			)
 		} else if status >= 400 {
-			sm.logger.Warn("Request completed with client error",
+			logger.Warn("Request completed with client error",
 				"method", r.Method,
 				"path", r.URL.Path,
 				"status", status,
 				"duration_ms", duration.Milliseconds(),
-				"request_id", requestID,
+				"latency_bucket", latencyBucket,
 				"user_id", r.Context().Value("user_id"),
 			)
-		} else {
-			sm.logger.Info("Request completed successfully",
+		} else if !excluded {
+			logger.Info("Request completed successfully",
 				"method", r.Method,
 				"path", r.URL.Path,
 				"status", status,
 				"duration_ms", duration.Milliseconds(),
-				"request_id", requestID,
+				"latency_bucket", latencyBucket,
 				"user_id", r.Context().Value("user_id"),
 			)
 		}
 	})
 }
+
+// latencyBucket classifies a request duration as fast, normal or slow
+func (sm *SecurityMiddleware) latencyBucket(d time.Duration) string {
+	switch {
+	case d < sm.fastRequest:
+		return "fast"
+	case d >= sm.slowRequest:
+		return "slow"
+	default:
+		return "normal"
+	}
+}
+
+// traceIDFromHeader extracts the trace ID from a W3C traceparent header
+func traceIDFromHeader(traceparent string) string {
+	// Format: version-traceid-parentid-flags
+	parts := strings.Split(traceparent, "-")
+	if len(parts) != 4 || len(parts[1]) != 32 {
+		return ""
+	}
+	return parts[1]
+}
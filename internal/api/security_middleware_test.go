@@ -0,0 +1,53 @@
+// internal/api/security_middleware_test.go
+package api
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cmatc13/stathera/pkg/logging"
+)
+
+func TestRequestLoggingSamplesOnlyAccessLog(t *testing.T) {
+	tests := []struct {
+		name           string
+		sampleRate     int
+		requests       int
+		wantAccessLogs int
+	}{
+		{name: "no sampling", sampleRate: 1, requests: 10, wantAccessLogs: 20},
+		{name: "sampled", sampleRate: 4, requests: 10, wantAccessLogs: 5},
+		{name: "heavily sampled", sampleRate: 1000, requests: 10, wantAccessLogs: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mainOut, accessOut bytes.Buffer
+			logger := logging.New(logging.Config{Level: logging.InfoLevel, Output: &mainOut})
+			accessLogger := logging.New(logging.Config{Level: logging.InfoLevel, Output: &accessOut, SampleRate: tt.sampleRate})
+
+			sm := NewSecurityMiddleware(nil, nil, logger)
+			sm.SetAccessLogger(accessLogger)
+			handler := sm.RequestLogging(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				logging.FromContext(r.Context(), logger).Info("Handled request")
+			}))
+
+			for i := 0; i < tt.requests; i++ {
+				handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/balance", nil))
+			}
+
+			if got := strings.Count(mainOut.String(), "Handled request"); got != tt.requests {
+				t.Fatalf("handler logged %d messages, want %d", got, tt.requests)
+			}
+			if got := strings.Count(accessOut.String(), "\n"); got != tt.wantAccessLogs {
+				t.Fatalf("access log has %d lines, want %d", got, tt.wantAccessLogs)
+			}
+			if strings.Contains(mainOut.String(), "Request started") {
+				t.Fatal("access log lines were written to the main logger")
+			}
+		})
+	}
+}
@@ -3,103 +3,212 @@ package api
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
 	"net/http"
 	"runtime"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
-	"github.com/go-chi/cors"
+	"github.com/go-chi/httprate"
 	"github.com/go-chi/jwtauth/v5"
+	"github.com/go-redis/redis/v8"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 
 	"github.com/cmatc13/stathera/internal/orderbook"
 	"github.com/cmatc13/stathera/internal/security"
 	"github.com/cmatc13/stathera/internal/transaction"
 	"github.com/cmatc13/stathera/internal/wallet"
+	"github.com/cmatc13/stathera/pkg/breaker"
 	"github.com/cmatc13/stathera/pkg/config"
+	"github.com/cmatc13/stathera/pkg/cors"
+	apierrors "github.com/cmatc13/stathera/pkg/errors"
 	"github.com/cmatc13/stathera/pkg/health"
 	"github.com/cmatc13/stathera/pkg/logging"
 	"github.com/cmatc13/stathera/pkg/metrics"
 	txproc "github.com/cmatc13/stathera/pkg/transaction"
 )
 
+const (
+	// Prefix for transactions submitted but not yet stored by the processor
+	submittedTxPrefix = "tx:submitted:"
+
+	// How long a submitted transaction is reported as pending
+	submittedTxTTL = 24 * time.Hour
+)
+
 // Server represents the API server
 type Server struct {
-	config           *config.Config
-	router           *chi.Mux
-	txProcessor      txproc.Processor
-	orderbook        *orderbook.RedisOrderBook
-	tokenAuth        *jwtauth.JWTAuth
-	server           *http.Server
-	logger           *logging.Logger
-	metricsCollector *metrics.Metrics
-	healthRegistry   *health.Registry
-}
-
-// NewServer creates a new API server
-func NewServer(cfg *config.Config, txProcessor txproc.Processor, orderbook *orderbook.RedisOrderBook) *Server {
+	config            *config.Config
+	router            *chi.Mux
+	txProcessor       txproc.Processor
+	orderbook         *orderbook.RedisOrderBook
+	feeSchedule       transaction.FeeSchedule
+	securityManager   *security.SecurityManager
+	tokenAuth         *jwtauth.JWTAuth
+	server            *http.Server
+	logger            *logging.Logger
+	accessLogger      *logging.Logger // sampled logger for RequestLogging
+	metricsCollector  *metrics.Metrics
+	healthRegistry    *health.Registry
+	redisClient       *redis.Client
+	redisBreaker      *breaker.Breaker
+	rateLimit         *RateLimit
+	verifyCredentials CredentialVerifier
+	updatePassword    PasswordUpdater
+	ledgerVerifier    LedgerVerifier
+}
+
+// NewServer creates a new API server.
+// It fails if Redis is unreachable, unless cfg.API.AllowDegradedStart is set,
+// in which case authenticated routes respond with 503 until restart.
+func NewServer(cfg *config.Config, txProcessor txproc.Processor, orderbook *orderbook.RedisOrderBook) (*Server, error) {
 	r := chi.NewRouter()
 	tokenAuth := jwtauth.New("HS256", []byte(cfg.Auth.JWTSecret), nil)
 
 	// Set up structured logger
+	logOutput, err := logging.OpenOutput(cfg.Log.OutputPath)
+	if err != nil {
+		return nil, err
+	}
 	logCfg := logging.Config{
 		Level:       logging.LogLevel(cfg.Log.Level),
-		Output:      log.Writer(),
+		Format:      logging.LogFormat(cfg.Log.Format),
+		Output:      logOutput,
 		ServiceName: "api",
 		Environment: cfg.Log.Environment,
 	}
 	logger := logging.New(logCfg)
 
+	// Only the access log is sampled, so handlers' own messages are never dropped
+	accessLogCfg := logCfg
+	accessLogCfg.SampleRate = cfg.Log.SampleRate
+	accessLogger := logging.New(accessLogCfg)
+
 	// Set up metrics
 	metricsCfg := metrics.Config{
 		Namespace:   cfg.Metrics.Namespace,
 		Subsystem:   "api",
 		ServiceName: "api",
+
+		RequestDurationBuckets:   cfg.Metrics.RequestDurationBuckets,
+		TransactionAmountBuckets: cfg.Metrics.TransactionAmountBuckets,
 	}
 	metricsCollector := metrics.New(metricsCfg)
 
+	// Only expose error details outside production
+	apierrors.SetEnvironment(cfg.Env)
+
 	// Set up health registry
 	healthRegistry := health.NewRegistry(logger)
+	healthRegistry.SetCheckTimeout(cfg.Health.CheckTimeout())
 
 	s := &Server{
 		config:           cfg,
 		router:           r,
 		txProcessor:      txProcessor,
 		orderbook:        orderbook,
+		feeSchedule:      transaction.NewPercentageFeeSchedule(cfg.Fee.Rate, cfg.Fee.MinFee),
 		tokenAuth:        tokenAuth,
 		logger:           logger,
+		accessLogger:     accessLogger,
 		metricsCollector: metricsCollector,
 		healthRegistry:   healthRegistry,
+		rateLimit:        NewRateLimit(cfg.API.RateLimitRequests, cfg.API.RateLimitWindow),
 		server: &http.Server{
 			Addr:    ":" + cfg.API.Port,
 			Handler: r,
 		},
 	}
 
+	s.rateLimit.SetRoutes(cfg.API.RouteRateLimits)
+
+	// Trip a shared circuit breaker after repeated Redis failures
+	if cfg.Redis.BreakerThreshold > 0 {
+		s.redisBreaker = breaker.New(cfg.Redis.BreakerThreshold, cfg.Redis.BreakerCooldown)
+	}
+
+	// Set up the Redis client, guarded by the breaker from its first command
+	s.redisClient = redis.NewClient(cfg.Redis.ClientOptions())
+	if s.redisBreaker != nil {
+		s.redisClient.AddHook(breaker.NewRedisHook(s.redisBreaker))
+	}
+
+	// Initialize the shared security manager
+	securityManager, err := s.newSecurityManager()
+	if err != nil {
+		if !cfg.API.AllowDegradedStart {
+			s.redisClient.Close()
+			return nil, fmt.Errorf("failed to initialize security manager: %w", err)
+		}
+		logger.Warn("Security manager unavailable, starting in degraded mode with authentication disabled", "error", err)
+	}
+	s.securityManager = securityManager
+
+	securityMiddleware := NewSecurityMiddleware(securityManager, s.tokenAuth, s.logger)
+	securityMiddleware.SetRedactedFields(cfg.API.RedactedFields)
+	securityMiddleware.SetAccessLogPolicy(cfg.Log.AccessLogExclude, cfg.Log.FastRequestThreshold, cfg.Log.SlowRequestThreshold)
+	securityMiddleware.SetAccessLogger(accessLogger)
+	securityMiddleware.SetMetrics(s.metricsCollector)
+
 	// Set up middleware and routes
-	s.setupMiddleware()
-	s.setupRoutes()
+	s.setupMiddleware(securityMiddleware)
+	s.setupRoutes(securityMiddleware)
 	s.setupHealthChecks()
 
-	return s
+	// Keep the OpenAPI route table in sync with the router
+	for _, route := range undocumentedRoutes(s.router) {
+		logger.Warn("Route missing from OpenAPI route table", "route", route)
+	}
+
+	return s, nil
 }
 
-// setupMiddleware configures middleware for the server
-func (s *Server) setupMiddleware() {
-	// Initialize security middleware
-	securityManager, err := security.NewSecurityManager(s.config.Redis.Address, s.config.Auth.JWTSecret)
+// newSecurityManager creates a security manager configured from the server config
+func (s *Server) newSecurityManager() (*security.SecurityManager, error) {
+	securityManager, err := security.NewSecurityManagerWithOptions(s.config.Redis.ClientOptions(), s.config.Auth.JWTSecret)
 	if err != nil {
-		s.logger.Error("Failed to initialize security manager", "error", err)
-		return
+		return nil, err
 	}
 
-	securityMiddleware := NewSecurityMiddleware(securityManager, s.tokenAuth, s.logger)
+	securityManager.SetAPIKeyPolicy(s.config.Auth.APIKeyTTL, s.config.Auth.APIKeyRotationGrace)
+	securityManager.SetRefreshTokenDuration(s.config.Auth.RefreshTokenDuration)
+	if err := securityManager.SetBcryptCost(s.config.Auth.BcryptCost); err != nil {
+		securityManager.Close()
+		return nil, err
+	}
+
+	policy := s.config.Auth.PasswordPolicy
+	passwordPolicy := &security.PasswordPolicy{
+		MinLength:     policy.MinLength,
+		RequireUpper:  policy.RequireUpper,
+		RequireLower:  policy.RequireLower,
+		RequireDigit:  policy.RequireDigit,
+		RequireSymbol: policy.RequireSymbol,
+	}
+	if policy.BreachedPasswordsFile != "" {
+		breached, err := security.LoadBreachedPasswords(policy.BreachedPasswordsFile)
+		if err != nil {
+			securityManager.Close()
+			return nil, err
+		}
+		passwordPolicy.Breached = breached
+	}
+	securityManager.SetPasswordPolicy(passwordPolicy)
+	if s.redisBreaker != nil {
+		securityManager.SetCircuitBreaker(s.redisBreaker)
+	}
 
+	return securityManager, nil
+}
+
+// setupMiddleware configures middleware for the server
+func (s *Server) setupMiddleware(securityMiddleware *SecurityMiddleware) {
 	// Basic middleware
 	s.router.Use(middleware.RequestID)
 	s.router.Use(middleware.RealIP)
@@ -108,8 +217,7 @@ func (s *Server) setupMiddleware() {
 	s.router.Use(securityMiddleware.SecureHeaders)
 	s.router.Use(securityMiddleware.ContentSecurityPolicy)
 	s.router.Use(securityMiddleware.ErrorHandling)
-	s.router.Use(securityMiddleware.XSSProtection)
-	s.router.Use(securityMiddleware.SQLInjectionProtection)
+	s.router.Use(securityMiddleware.ParamValidation(NewParamValidator(s.config.API.StrictInputValidation)))
 
 	// Custom structured logging middleware with security enhancements
 	s.router.Use(securityMiddleware.RequestLogging)
@@ -120,31 +228,23 @@ func (s *Server) setupMiddleware() {
 	// Custom recoverer with metrics
 	s.router.Use(RecovererWithMetrics(s.logger, s.metricsCollector, "api"))
 
-	// Add CORS middleware with stricter settings
-	s.router.Use(cors.Handler(cors.Options{
-		AllowedOrigins:   s.config.API.CORSAllowedOrigins, // Use configuration instead of wildcard
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "X-API-Key"},
-		ExposedHeaders:   []string{"Link", "X-New-Token"}, // Expose token renewal header
-		AllowCredentials: true,
-		MaxAge:           300,
-	}))
+	// Add CORS middleware configured from the API settings
+	s.router.Use(cors.Handler(s.config.API))
 
 	// Add advanced rate limiting middleware (per user/IP and path)
-	s.router.Use(securityMiddleware.RateLimiter(100, 1*time.Minute))
-}
-
-// setupRoutes configures the API routes
-func (s *Server) setupRoutes() {
-	// Initialize security middleware
-	securityManager, err := security.NewSecurityManager(s.config.Redis.Address, s.config.Auth.JWTSecret)
-	if err != nil {
-		s.logger.Error("Failed to initialize security manager", "error", err)
-		return
+	if s.securityManager != nil {
+		s.router.Use(securityMiddleware.DynamicRateLimiter(s.rateLimit))
+	} else {
+		// Fall back to in-memory per-IP limits without Redis
+		s.router.Use(fallbackRateLimiter(s.rateLimit, s.config.API))
 	}
 
-	securityMiddleware := NewSecurityMiddleware(securityManager, s.tokenAuth, s.logger)
+	// Cap request body sizes to protect against memory exhaustion
+	s.router.Use(securityMiddleware.BodyLimit(s.config.API.MaxBodySize, s.config.API.RouteBodySizes))
+}
 
+// setupRoutes configures the API routes
+func (s *Server) setupRoutes(securityMiddleware *SecurityMiddleware) {
 	// Public routes
 	s.router.Group(func(r chi.Router) {
 		// Apply input validation and sanitization
@@ -155,21 +255,38 @@ func (s *Server) setupRoutes() {
 		}))
 
 		r.Get("/health", s.handleHealth)
+		r.Get("/health/live", s.handleLiveness)
+		r.Get("/health/ready", s.handleReadiness)
 		r.Get("/metrics", promhttp.Handler().ServeHTTP)
+		r.Get("/openapi.json", s.handleOpenAPI)
+		r.With(securityMiddleware.ParamValidation(NewParamValidator(s.config.API.StrictInputValidation).
+			WithRule("amount", ParamRule{Type: ParamFloat, MaxLength: 32}).
+			WithRule("type", ParamRule{MaxLength: 32, AllowedChars: "ABCDEFGHIJKLMNOPQRSTUVWXYZ_abcdefghijklmnopqrstuvwxyz"}),
+		)).Get("/fee-estimate", s.handleFeeEstimate)
 
 		// Apply content type validation for endpoints that accept JSON
 		r.With(securityMiddleware.ValidateContentType("application/json")).Post("/register", s.handleRegister)
 		r.With(securityMiddleware.ValidateContentType("application/json")).Post("/login", s.handleLogin)
+		r.With(securityMiddleware.ValidateContentType("application/json")).Post("/login/2fa", s.handleLogin2FA)
+		r.With(securityMiddleware.ValidateContentType("application/json")).Post("/token/refresh", s.handleRefreshToken)
+		r.With(securityMiddleware.ValidateContentType("application/json")).Post("/token/revoke", s.handleRevokeRefreshToken)
+		r.With(securityMiddleware.ValidateContentType("application/json")).Post("/password/reset/request", s.handlePasswordResetRequest)
+		r.With(securityMiddleware.ValidateContentType("application/json")).Post("/password/reset/confirm", s.handlePasswordResetConfirm)
 	})
 
 	// Protected routes - require authentication (JWT or API key)
 	s.router.Group(func(r chi.Router) {
+		// Reject requests when running without the security manager
+		r.Use(s.requireSecurity)
+
 		// Authentication middleware - try API key first, then JWT
 		r.Use(securityMiddleware.APIKeyAuth)
 		r.Use(jwtauth.Verifier(s.tokenAuth))
-		r.Use(securityMiddleware.JWTWithBruteForceProtection)
 		r.Use(jwtauth.Authenticator)
 
+		// Require a valid server-side session
+		r.Use(securityMiddleware.SessionValidation)
+
 		// Add CSRF protection for state-changing operations
 		r.Use(securityMiddleware.CSRFProtection)
 
@@ -188,29 +305,55 @@ func (s *Server) setupRoutes() {
 
 		// User routes
 		r.Get("/balance", s.handleGetBalance)
-		r.Get("/transactions", s.handleGetTransactions)
+		r.With(securityMiddleware.ParamValidation(NewParamValidator(s.config.API.StrictInputValidation).
+			WithRule("limit", ParamRule{Type: ParamInt, MaxLength: 10}).
+			WithRule("offset", ParamRule{Type: ParamInt, MaxLength: 19}),
+		)).Get("/transactions", s.handleGetTransactions)
+		r.Get("/transactions/stream", s.handleTransactionStream)
+		r.Get("/transactions/{id}", s.handleGetTransaction)
 
 		// Transaction routes
-		r.Post("/transfer", s.handleTransfer)
+		r.With(securityMiddleware.Idempotency).Post("/transfer", s.handleTransfer)
 
 		// Wallet routes
 		r.Get("/wallet", s.handleGetWalletInfo)
+		r.Post("/wallet/pubkey", s.handleRegisterPublicKey)
+		r.Get("/wallet/pubkey/{address}", s.handleGetPublicKey)
+
+		// Session routes
+		r.Post("/logout", s.handleLogout)
+
+		// Two-factor authentication routes
+		r.Post("/2fa/enroll", s.handleEnrollTOTP)
+		r.Post("/2fa/confirm", s.handleConfirmTOTP)
+
+		// API key routes
+		r.Get("/api-keys", s.handleListAPIKeys)
+		r.Post("/api-keys/rotate", s.handleRotateAPIKey)
+		r.Post("/api-keys/revoke", s.handleRevokeAPIKey)
 
 		// Order book routes
-		r.Get("/orderbook", s.handleGetOrderBook)
-		r.Post("/orders", s.handlePlaceOrder)
+		r.With(securityMiddleware.ParamValidation(NewParamValidator(s.config.API.StrictInputValidation).
+			WithRule("depth", ParamRule{Type: ParamInt, MaxLength: 10}),
+		)).Get("/orderbook", s.handleGetOrderBook)
+		r.With(securityMiddleware.Idempotency).Post("/orders", s.handlePlaceOrder)
 		r.Delete("/orders/{id}", s.handleCancelOrder)
 	})
 
 	// Admin routes - require admin role
 	s.router.Group(func(r chi.Router) {
+		// Reject requests when running without the security manager
+		r.Use(s.requireSecurity)
+
 		// Authentication middleware with enhanced security
 		r.Use(securityMiddleware.APIKeyAuth)
 		r.Use(jwtauth.Verifier(s.tokenAuth))
-		r.Use(securityMiddleware.JWTWithBruteForceProtection)
 		r.Use(jwtauth.Authenticator)
 		r.Use(s.adminOnly)
 
+		// Require a valid server-side session
+		r.Use(securityMiddleware.SessionValidation)
+
 		// Add CSRF protection for state-changing operations
 		r.Use(securityMiddleware.CSRFProtection)
 
@@ -239,22 +382,37 @@ func (s *Server) setupRoutes() {
 		r.Get("/admin/system/supply", s.handleGetTotalSupply)
 		r.Get("/admin/system/inflation", s.handleGetInflationRate)
 		r.Post("/admin/system/adjust-inflation", s.handleAdjustInflation)
+		r.Get("/admin/ledger/verify", s.handleVerifyLedger)
+		r.Get("/admin/transactions/failed", s.handleListFailedTransactions)
+		r.Post("/admin/transactions/{id}/requeue", s.handleRequeueTransaction)
+	})
+}
+
+// requireSecurity is middleware that rejects requests in degraded mode
+func (s *Server) requireSecurity(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.securityManager == nil {
+			s.renderError(w, "Authentication unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		next.ServeHTTP(w, r)
 	})
 }
 
 // setupHealthChecks configures health checks for the server
 func (s *Server) setupHealthChecks() {
 	// Register API server health check
-	s.healthRegistry.Register("api", health.ServiceChecker("api", func(ctx context.Context) error {
+	s.healthRegistry.RegisterLiveness("api", health.ServiceChecker("api", func(ctx context.Context) error {
 		return nil // API server is healthy if this code is running
 	}))
 
 	// Register Redis health check
-	s.healthRegistry.Register("redis", health.RedisChecker(s.config.Redis.Address, func(ctx context.Context) error {
-		// This is a placeholder - in a real implementation, you would ping Redis
-		// For now, we'll just check if the Redis address is valid
-		return nil
+	s.healthRegistry.Register("redis", health.TimedChecker(health.RealRedisChecker(s.redisClient), func(d time.Duration) {
+		s.metricsCollector.RecordDependencyLatency("api", "redis", "ping", d)
 	}))
+	if s.redisBreaker != nil {
+		s.healthRegistry.Register("redis-circuit-breaker", health.CircuitBreakerChecker("redis", s.redisBreaker))
+	}
 
 	// Register transaction processor health check
 	s.healthRegistry.Register("transaction-processor", health.DependencyChecker("transaction-processor", func(ctx context.Context) error {
@@ -286,12 +444,60 @@ func (s *Server) Start() {
 	}
 }
 
+// ApplyConfig applies the settings that can change without a restart
+func (s *Server) ApplyConfig(cfg *config.Config) {
+	s.logger.SetLevel(logging.LogLevel(cfg.Log.Level))
+	s.accessLogger.SetLevel(logging.LogLevel(cfg.Log.Level))
+	s.rateLimit.Set(cfg.API.RateLimitRequests, cfg.API.RateLimitWindow)
+	s.rateLimit.SetRoutes(cfg.API.RouteRateLimits)
+	s.logger.Info("Applied reloaded configuration",
+		"log_level", cfg.Log.Level,
+		"rate_limit_requests", cfg.API.RateLimitRequests,
+		"rate_limit_window", cfg.API.RateLimitWindow.String(),
+		"route_rate_limits", len(cfg.API.RouteRateLimits),
+	)
+}
+
+// fallbackRateLimiter limits requests per IP in memory, applying the
+// configured per-route limits before the global one. Unlike the Redis-backed
+// limiter, its limits are fixed at startup.
+func fallbackRateLimiter(rateLimit *RateLimit, cfg config.APIConfig) func(next http.Handler) http.Handler {
+	global := httprate.LimitByIP(cfg.RateLimitRequests, cfg.RateLimitWindow)
+	routes := make(map[string]func(next http.Handler) http.Handler, len(cfg.RouteRateLimits))
+	for pattern, limit := range cfg.RouteRateLimits {
+		routes[pattern] = httprate.LimitByIP(limit.Requests, limit.Window)
+	}
+
+	return func(next http.Handler) http.Handler {
+		limited := global(next)
+		routeLimited := make(map[string]http.Handler, len(routes))
+		for pattern, limiter := range routes {
+			routeLimited[pattern] = limiter(next)
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if route, ok := rateLimit.route(r.URL.Path); ok {
+				if handler, exists := routeLimited[route.pattern]; exists {
+					handler.ServeHTTP(w, r)
+					return
+				}
+			}
+			limited.ServeHTTP(w, r)
+		})
+	}
+}
+
 // Shutdown gracefully shuts down the server
 func (s *Server) Shutdown(ctx context.Context) {
 	s.logger.Info("Shutting down API server")
 	if err := s.server.Shutdown(ctx); err != nil {
 		s.logger.Error("Error during server shutdown", "error", err)
 	}
+	if s.redisClient != nil {
+		if err := s.redisClient.Close(); err != nil {
+			s.logger.Error("Error closing Redis client", "error", err)
+		}
+	}
 	s.logger.Info("API server shutdown complete")
 }
 
@@ -301,23 +507,29 @@ type Response struct {
 	Message string      `json:"message,omitempty"`
 	Data    interface{} `json:"data,omitempty"`
 	Error   string      `json:"error,omitempty"`
+	Code    string      `json:"code,omitempty"`
 }
 
 // handleHealth handles health check requests
 func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
-	// Run all health checks
-	checks := s.healthRegistry.RunChecks(r.Context())
+	s.renderHealth(w, s.healthRegistry.RunChecks(r.Context()))
+}
 
+// handleLiveness reports whether the API process is working, without
+// checking its dependencies
+func (s *Server) handleLiveness(w http.ResponseWriter, r *http.Request) {
+	s.renderHealth(w, s.healthRegistry.RunProbe(r.Context(), health.ProbeLiveness))
+}
+
+// handleReadiness reports whether the API's dependencies are available
+func (s *Server) handleReadiness(w http.ResponseWriter, r *http.Request) {
+	s.renderHealth(w, s.healthRegistry.RunProbe(r.Context(), health.ProbeReadiness))
+}
+
+// renderHealth renders health check results
+func (s *Server) renderHealth(w http.ResponseWriter, checks map[string]health.Check) {
 	// Determine overall status
-	status := health.StatusUp
-	for _, check := range checks {
-		if check.Status == health.StatusDown {
-			status = health.StatusDown
-			break
-		} else if check.Status == health.StatusUnknown && status != health.StatusDown {
-			status = health.StatusUnknown
-		}
-	}
+	status := health.OverallStatus(checks)
 
 	// Set HTTP status code based on health status
 	httpStatus := http.StatusOK
@@ -333,6 +545,7 @@ func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 			"status":    status,
 			"timestamp": time.Now().Unix(),
 			"version":   s.config.API.Version,
+			"build":     s.healthRegistry.BuildInfo(),
 			"checks":    checks,
 			"system": map[string]interface{}{
 				"go_version":    runtime.Version(),
@@ -364,6 +577,18 @@ func (s *Server) handleRegister(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if s.securityManager == nil {
+		s.renderError(w, "Registration unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	// Hash first so a weak password does not create a wallet
+	passwordHash, err := s.securityManager.HashPassword(req.Password)
+	if err != nil {
+		s.renderError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	// Create a new wallet for the user
 	newWallet, err := wallet.NewWallet()
 	if err != nil {
@@ -371,13 +596,28 @@ func (s *Server) handleRegister(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// In a real implementation, you would:
-	// 1. Check if username/email already exists
-	// 2. Hash the password
-	// 3. Store user data in a database
-	// 4. Assign the wallet to the user
+	// Register the wallet's public key so its transactions can be verified
+	pubKey, err := walletPublicKey(newWallet)
+	if err != nil {
+		logging.FromContext(r.Context(), s.logger).Error("Failed to get wallet public key", "address", newWallet.Address, "error", err)
+		s.renderError(w, "Failed to create wallet", http.StatusInternalServerError)
+		return
+	}
+	// Store the account so the user can log in
+	if _, err := s.securityManager.CreateUser(r.Context(), req.Username, passwordHash, newWallet.Address); err != nil {
+		if errors.Is(err, security.ErrUserExists) {
+			s.renderError(w, "Username is already taken", http.StatusConflict)
+			return
+		}
+		logging.FromContext(r.Context(), s.logger).Error("Failed to store user", "username", req.Username, "error", err)
+		s.renderError(w, "Failed to register user", http.StatusInternalServerError)
+		return
+	}
+
+	if !s.registerPublicKey(w, r, newWallet.Address, pubKey) {
+		return
+	}
 
-	// For this implementation, we'll just return the wallet details
 	resp := Response{
 		Success: true,
 		Message: "User registered successfully",
@@ -396,8 +636,627 @@ func (s *Server) handleRegister(w http.ResponseWriter, r *http.Request) {
 // handleLogin handles user login requests
 func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		Username string `json:"username"`
-		Password string `json:"password"`
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.renderError(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	if req.Username == "" || req.Password == "" {
+		s.renderError(w, "Username and password are required", http.StatusBadRequest)
+		return
+	}
+
+	log := logging.FromContext(r.Context(), s.logger)
+	attemptKey := loginAttemptKey(req.Username)
+
+	// Refuse locked accounts before checking the password, so a lockout
+	// cannot be bypassed by guessing correctly
+	if s.securityManager != nil {
+		allowed, err := s.securityManager.CheckLoginAllowed(r.Context(), attemptKey)
+		if err != nil {
+			log.Error("Failed to check login allowed", "username", req.Username, "error", err)
+		} else if !allowed {
+			log.Warn("Login blocked due to too many failed attempts", "username", req.Username)
+			s.renderError(w, "Too many failed login attempts. Please try again later.", http.StatusTooManyRequests)
+			return
+		}
+	}
+
+	userID, err := s.checkCredentials(r.Context(), req.Username, req.Password)
+	if errors.Is(err, errNoCredentialStore) {
+		s.renderError(w, "Login unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	if errors.Is(err, ErrInvalidCredentials) {
+		s.metricsCollector.RecordFailedLogin()
+		if s.securityManager != nil {
+			if err := s.securityManager.RecordFailedLogin(r.Context(), attemptKey); err != nil {
+				log.Error("Failed to record failed login", "username", req.Username, "error", err)
+			}
+		}
+		s.renderError(w, "Invalid username or password", http.StatusUnauthorized)
+		return
+	}
+	if err != nil {
+		log.Error("Failed to verify credentials", "username", req.Username, "error", err)
+		s.renderError(w, "Failed to verify credentials", http.StatusInternalServerError)
+		return
+	}
+
+	// A correct password clears the failed attempts
+	if s.securityManager != nil {
+		if err := s.securityManager.ResetFailedLogins(r.Context(), attemptKey); err != nil {
+			log.Warn("Failed to reset failed logins", "username", req.Username, "error", err)
+		}
+	}
+
+	// Require a second factor if the user has TOTP enrolled
+	if s.securityManager != nil {
+		mfaEnabled, err := s.securityManager.HasTOTP(r.Context(), userID)
+		if err != nil {
+			s.renderError(w, "Failed to check two-factor status", http.StatusInternalServerError)
+			return
+		}
+
+		if mfaEnabled {
+			challenge, err := s.securityManager.CreateMFAChallenge(r.Context(), userID, req.Username)
+			if err != nil {
+				s.renderError(w, "Failed to create two-factor challenge", http.StatusInternalServerError)
+				return
+			}
+
+			resp := Response{
+				Success: true,
+				Message: "Two-factor authentication required",
+				Data: map[string]interface{}{
+					"mfa_required": true,
+					"mfa_token":    challenge,
+				},
+			}
+
+			s.renderJSON(w, resp, http.StatusOK)
+			return
+		}
+	}
+
+	s.renderLoginToken(w, r, userID, req.Username)
+}
+
+// handleLogin2FA completes a login by verifying a TOTP code
+func (s *Server) handleLogin2FA(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		MFAToken string `json:"mfa_token"`
+		Code     string `json:"code"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.renderError(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	if req.MFAToken == "" || req.Code == "" {
+		s.renderError(w, "MFA token and code are required", http.StatusBadRequest)
+		return
+	}
+
+	if s.securityManager == nil {
+		s.renderError(w, "Two-factor authentication unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	// Challenges are single-use; a failed code requires logging in again
+	userID, username, err := s.securityManager.ConsumeMFAChallenge(r.Context(), req.MFAToken)
+	if err != nil {
+		s.renderError(w, "Invalid or expired MFA token", http.StatusUnauthorized)
+		return
+	}
+
+	valid, err := s.securityManager.VerifyTOTP(r.Context(), userID, req.Code)
+	if err != nil {
+		s.renderError(w, "Failed to verify code", http.StatusInternalServerError)
+		return
+	}
+	if !valid {
+		s.metricsCollector.RecordFailedLogin()
+		s.renderError(w, "Invalid code", http.StatusUnauthorized)
+		return
+	}
+
+	s.renderLoginToken(w, r, userID, username)
+}
+
+// handlePasswordResetRequest issues a password reset token for a user
+func (s *Server) handlePasswordResetRequest(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		UserID string `json:"user_id"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.renderError(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	if req.UserID == "" {
+		s.renderError(w, "User ID is required", http.StatusBadRequest)
+		return
+	}
+
+	if s.securityManager == nil {
+		s.renderError(w, "Password reset unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	// In a real implementation, you would look up the user and deliver the
+	// token out of band (e.g. by email). It is never returned in the response.
+	if _, err := s.securityManager.GeneratePasswordResetToken(r.Context(), req.UserID); err != nil {
+		logging.FromContext(r.Context(), s.logger).Error("Failed to generate password reset token", "error", err)
+		s.renderError(w, "Failed to process password reset", http.StatusInternalServerError)
+		return
+	}
+
+	// Respond identically whether or not the user exists
+	resp := Response{
+		Success: true,
+		Message: "If the account exists, password reset instructions have been sent",
+	}
+
+	s.renderJSON(w, resp, http.StatusAccepted)
+}
+
+// handlePasswordResetConfirm sets a new password using a reset token
+func (s *Server) handlePasswordResetConfirm(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Token       string `json:"token"`
+		NewPassword string `json:"new_password"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.renderError(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	if req.Token == "" || req.NewPassword == "" {
+		s.renderError(w, "Token and new password are required", http.StatusBadRequest)
+		return
+	}
+
+	if s.securityManager == nil {
+		s.renderError(w, "Password reset unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	// Without a password store the token must not be consumed
+	if s.updatePassword == nil {
+		s.renderError(w, "Password reset is not implemented", http.StatusNotImplemented)
+		return
+	}
+
+	// Hash first so a weak password does not burn the token
+	passwordHash, err := s.securityManager.HashPassword(req.NewPassword)
+	if err != nil {
+		s.renderError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	userID, err := s.securityManager.ConsumePasswordResetToken(r.Context(), req.Token)
+	if err != nil {
+		if errors.Is(err, security.ErrInvalidResetToken) {
+			s.renderError(w, "Invalid or expired reset token", http.StatusBadRequest)
+			return
+		}
+		s.renderError(w, "Failed to process password reset", http.StatusInternalServerError)
+		return
+	}
+
+	if err := s.updatePassword(r.Context(), userID, passwordHash); err != nil {
+		logging.FromContext(r.Context(), s.logger).Error("Failed to store new password", "user_id", userID, "error", err)
+		s.renderError(w, "Failed to process password reset", http.StatusInternalServerError)
+		return
+	}
+
+	// Clear any lockout from previous failed logins
+	if err := s.securityManager.ResetFailedLogins(r.Context(), userID); err != nil {
+		logging.FromContext(r.Context(), s.logger).Warn("Failed to reset failed logins", "user_id", userID, "error", err)
+	}
+
+	resp := Response{
+		Success: true,
+		Message: "Password has been reset",
+	}
+
+	s.renderJSON(w, resp, http.StatusOK)
+}
+
+// renderLoginToken issues a JWT for an authenticated user
+func (s *Server) renderLoginToken(w http.ResponseWriter, r *http.Request, userID, username string) {
+	if s.securityManager == nil {
+		s.renderError(w, "Authentication unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	// Create a server-side session so the login can be revoked
+	sessionID, err := s.securityManager.CreateSession(r.Context(), userID)
+	if err != nil {
+		s.renderError(w, "Failed to create session", http.StatusInternalServerError)
+		return
+	}
+
+	csrfToken, err := s.securityManager.GenerateCSRFToken(r.Context(), sessionID)
+	if err != nil {
+		s.renderError(w, "Failed to create session", http.StatusInternalServerError)
+		return
+	}
+
+	refreshToken, err := s.securityManager.IssueRefreshToken(r.Context(), security.RefreshSession{
+		UserID:    userID,
+		Username:  username,
+		SessionID: sessionID,
+	})
+	if err != nil {
+		s.renderError(w, "Failed to generate token", http.StatusInternalServerError)
+		return
+	}
+
+	tokenString, expiresAt, err := s.encodeAccessToken(userID, username, sessionID)
+	if err != nil {
+		s.renderError(w, "Failed to generate token", http.StatusInternalServerError)
+		return
+	}
+
+	// Set session cookie
+	http.SetCookie(w, &http.Cookie{
+		Name:     "session_id",
+		Value:    sessionID,
+		Path:     "/",
+		Expires:  time.Now().Add(security.SessionExpiration),
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteStrictMode,
+	})
+
+	resp := Response{
+		Success: true,
+		Message: "Login successful",
+		Data: map[string]interface{}{
+			"token":         tokenString,
+			"refresh_token": refreshToken,
+			"csrf_token":    csrfToken,
+			"expires_at":    expiresAt.Unix(),
+		},
+	}
+
+	s.renderJSON(w, resp, http.StatusOK)
+}
+
+// encodeAccessToken issues a JWT access token bound to a session
+func (s *Server) encodeAccessToken(userID, username, sessionID string) (string, time.Time, error) {
+	expiresAt := time.Now().Add(s.config.Auth.JWTExpirationTime)
+
+	// Create claims with user information
+	claims := map[string]interface{}{
+		"user_id":        userID,
+		"username":       username,
+		"role":           "user",
+		"wallet_address": "example_wallet_address",
+		"session_id":     sessionID,
+		"exp":            expiresAt.Unix(),
+	}
+
+	// Generate JWT token
+	_, tokenString, err := s.tokenAuth.Encode(claims)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return tokenString, expiresAt, nil
+}
+
+// handleRefreshToken exchanges a refresh token for a new access token and a rotated refresh token
+func (s *Server) handleRefreshToken(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.renderError(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	if req.RefreshToken == "" {
+		s.renderError(w, "Refresh token is required", http.StatusBadRequest)
+		return
+	}
+
+	if s.securityManager == nil {
+		s.renderError(w, "Authentication unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	session, refreshToken, err := s.securityManager.RotateRefreshToken(r.Context(), req.RefreshToken)
+	if err != nil {
+		if errors.Is(err, security.ErrRefreshTokenReuse) {
+			logging.FromContext(r.Context(), s.logger).Warn("Refresh token reuse detected", "remote_addr", r.RemoteAddr)
+			s.renderError(w, "Invalid refresh token", http.StatusUnauthorized)
+			return
+		}
+		if errors.Is(err, security.ErrInvalidRefreshToken) {
+			s.renderError(w, "Invalid refresh token", http.StatusUnauthorized)
+			return
+		}
+		s.renderError(w, "Failed to refresh token", http.StatusInternalServerError)
+		return
+	}
+
+	// A logged out session cannot be refreshed
+	if !s.securityManager.IsSessionValid(r.Context(), session.SessionID) {
+		if err := s.securityManager.RevokeRefreshToken(r.Context(), refreshToken); err != nil {
+			logging.FromContext(r.Context(), s.logger).Warn("Failed to revoke refresh token", "error", err)
+		}
+		s.renderError(w, "Session expired or invalid", http.StatusUnauthorized)
+		return
+	}
+
+	tokenString, expiresAt, err := s.encodeAccessToken(session.UserID, session.Username, session.SessionID)
+	if err != nil {
+		s.renderError(w, "Failed to generate token", http.StatusInternalServerError)
+		return
+	}
+
+	resp := Response{
+		Success: true,
+		Data: map[string]interface{}{
+			"token":         tokenString,
+			"refresh_token": refreshToken,
+			"expires_at":    expiresAt.Unix(),
+		},
+	}
+
+	s.renderJSON(w, resp, http.StatusOK)
+}
+
+// handleRevokeRefreshToken revokes a refresh token and its rotation chain
+func (s *Server) handleRevokeRefreshToken(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.renderError(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	if req.RefreshToken == "" {
+		s.renderError(w, "Refresh token is required", http.StatusBadRequest)
+		return
+	}
+
+	if s.securityManager == nil {
+		s.renderError(w, "Authentication unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	if err := s.securityManager.RevokeRefreshToken(r.Context(), req.RefreshToken); err != nil && !errors.Is(err, security.ErrInvalidRefreshToken) {
+		s.renderError(w, "Failed to revoke token", http.StatusInternalServerError)
+		return
+	}
+
+	resp := Response{
+		Success: true,
+		Message: "Refresh token revoked",
+	}
+
+	s.renderJSON(w, resp, http.StatusOK)
+}
+
+// handleLogout invalidates the current session
+func (s *Server) handleLogout(w http.ResponseWriter, r *http.Request) {
+	sessionID, _ := r.Context().Value("session_id").(string)
+	if sessionID == "" {
+		s.renderError(w, "No active session", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.securityManager.InvalidateSession(r.Context(), sessionID); err != nil {
+		s.renderError(w, "Failed to log out", http.StatusInternalServerError)
+		return
+	}
+
+	// Clear session cookie
+	http.SetCookie(w, &http.Cookie{
+		Name:     "session_id",
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteStrictMode,
+	})
+
+	resp := Response{
+		Success: true,
+		Message: "Logged out",
+	}
+
+	s.renderJSON(w, resp, http.StatusOK)
+}
+
+// handleEnrollTOTP starts enrolling the authenticated user in TOTP two-factor
+// authentication. It is enabled once handleConfirmTOTP verifies a code.
+func (s *Server) handleEnrollTOTP(w http.ResponseWriter, r *http.Request) {
+	userID, err := claimFromRequest(r, "user_id")
+	if err == nil && userID == "" {
+		err = apierrors.NewAPIError(apierrors.APIErrBadRequest, "User ID not found in token", nil)
+	}
+	if err != nil {
+		s.renderDomainError(w, err)
+		return
+	}
+
+	if s.securityManager == nil {
+		s.renderError(w, "Two-factor authentication unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	secret, otpauthURL, err := s.securityManager.EnrollTOTP(r.Context(), userID)
+	if err != nil {
+		s.renderError(w, "Failed to enroll two-factor authentication", http.StatusInternalServerError)
+		return
+	}
+
+	resp := Response{
+		Success: true,
+		Message: "Confirm a code from your authenticator app to enable two-factor authentication",
+		Data: map[string]interface{}{
+			"secret":      secret,
+			"otpauth_url": otpauthURL,
+		},
+	}
+
+	s.renderJSON(w, resp, http.StatusOK)
+}
+
+// handleConfirmTOTP enables a pending TOTP enrollment with a code generated
+// from its secret
+func (s *Server) handleConfirmTOTP(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Code string `json:"code"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.renderError(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	if req.Code == "" {
+		s.renderError(w, "Code is required", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := claimFromRequest(r, "user_id")
+	if err == nil && userID == "" {
+		err = apierrors.NewAPIError(apierrors.APIErrBadRequest, "User ID not found in token", nil)
+	}
+	if err != nil {
+		s.renderDomainError(w, err)
+		return
+	}
+
+	if s.securityManager == nil {
+		s.renderError(w, "Two-factor authentication unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	valid, err := s.securityManager.ConfirmTOTP(r.Context(), userID, req.Code)
+	if errors.Is(err, security.ErrNoPendingTOTP) {
+		s.renderError(w, "No pending two-factor enrollment", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		s.renderError(w, "Failed to confirm two-factor authentication", http.StatusInternalServerError)
+		return
+	}
+	if !valid {
+		s.renderError(w, "Invalid code", http.StatusBadRequest)
+		return
+	}
+
+	resp := Response{
+		Success: true,
+		Message: "Two-factor authentication enabled",
+	}
+
+	s.renderJSON(w, resp, http.StatusOK)
+}
+
+// handleRotateAPIKey replaces one of the authenticated user's API keys
+func (s *Server) handleRotateAPIKey(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		APIKey string `json:"api_key"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.renderError(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	if req.APIKey == "" {
+		s.renderError(w, "API key is required", http.StatusBadRequest)
+		return
+	}
+
+	if s.securityManager == nil {
+		s.renderError(w, "API key management unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	userID := requestUserID(r)
+	if userID == "" {
+		s.renderError(w, "Authentication error", http.StatusUnauthorized)
+		return
+	}
+
+	// Only the owner of a key may rotate it
+	owner, _, err := s.securityManager.ValidateAPIKey(r.Context(), req.APIKey)
+	if err != nil || owner != userID {
+		s.renderError(w, "Invalid API key", http.StatusBadRequest)
+		return
+	}
+
+	newKey, err := s.securityManager.RotateAPIKey(r.Context(), req.APIKey)
+	if err != nil {
+		s.renderError(w, "Failed to rotate API key", http.StatusInternalServerError)
+		return
+	}
+
+	resp := Response{
+		Success: true,
+		Message: "API key rotated",
+		Data: map[string]interface{}{
+			"api_key":        newKey,
+			"rotation_grace": s.config.Auth.APIKeyRotationGrace.String(),
+		},
+	}
+
+	s.renderJSON(w, resp, http.StatusOK)
+}
+
+// handleListAPIKeys lists the authenticated user's API keys
+func (s *Server) handleListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	if s.securityManager == nil {
+		s.renderError(w, "API key management unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	userID := requestUserID(r)
+	if userID == "" {
+		s.renderError(w, "Authentication error", http.StatusUnauthorized)
+		return
+	}
+
+	keys, err := s.securityManager.ListAPIKeys(r.Context(), userID)
+	if err != nil {
+		s.renderError(w, "Failed to list API keys", http.StatusInternalServerError)
+		return
+	}
+
+	resp := Response{
+		Success: true,
+		Data: map[string]interface{}{
+			"api_keys": keys,
+		},
+	}
+
+	s.renderJSON(w, resp, http.StatusOK)
+}
+
+// handleRevokeAPIKey revokes one of the authenticated user's API keys
+func (s *Server) handleRevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ID string `json:"id"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -405,54 +1264,60 @@ func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// In a real implementation, you would:
-	// 1. Retrieve user from database
-	// 2. Verify password
-	// 3. Check account status
+	if req.ID == "" {
+		s.renderError(w, "API key ID is required", http.StatusBadRequest)
+		return
+	}
 
-	// For this implementation, we'll assume authentication is successful
-	// and generate a JWT token
+	if s.securityManager == nil {
+		s.renderError(w, "API key management unavailable", http.StatusServiceUnavailable)
+		return
+	}
 
-	// Create claims with user information
-	claims := map[string]interface{}{
-		"user_id":        "12345", // Example user ID
-		"username":       req.Username,
-		"role":           "user",
-		"wallet_address": "example_wallet_address",
-		"exp":            time.Now().Add(time.Hour * 24).Unix(), // 24-hour expiration
+	userID := requestUserID(r)
+	if userID == "" {
+		s.renderError(w, "Authentication error", http.StatusUnauthorized)
+		return
 	}
 
-	// Generate JWT token
-	_, tokenString, err := s.tokenAuth.Encode(claims)
-	if err != nil {
-		s.renderError(w, "Failed to generate token", http.StatusInternalServerError)
+	if err := s.securityManager.RevokeAPIKeyByID(r.Context(), userID, req.ID); err != nil {
+		if errors.Is(err, security.ErrInvalidAPIKey) {
+			s.renderError(w, "API key not found", http.StatusNotFound)
+			return
+		}
+		s.renderError(w, "Failed to revoke API key", http.StatusInternalServerError)
 		return
 	}
 
 	resp := Response{
 		Success: true,
-		Message: "Login successful",
-		Data: map[string]interface{}{
-			"token":      tokenString,
-			"expires_at": time.Now().Add(time.Hour * 24).Unix(),
-		},
+		Message: "API key revoked",
 	}
 
 	s.renderJSON(w, resp, http.StatusOK)
 }
 
-// handleGetBalance handles balance check requests
-func (s *Server) handleGetBalance(w http.ResponseWriter, r *http.Request) {
-	// Get user from JWT token
+// requestUserID returns the authenticated user ID from an API key or JWT
+func requestUserID(r *http.Request) string {
+	if userID, ok := r.Context().Value("user_id").(string); ok && userID != "" {
+		return userID
+	}
+
 	_, claims, err := jwtauth.FromContext(r.Context())
 	if err != nil {
-		s.renderError(w, "Authentication error", http.StatusUnauthorized)
-		return
+		return ""
 	}
 
-	walletAddress, ok := claims["wallet_address"].(string)
-	if !ok {
-		s.renderError(w, "Invalid token claims", http.StatusBadRequest)
+	userID, _ := claims["user_id"].(string)
+	return userID
+}
+
+// handleGetBalance handles balance check requests
+func (s *Server) handleGetBalance(w http.ResponseWriter, r *http.Request) {
+	// Get user from JWT token
+	walletAddress, err := claimFromRequest(r, "wallet_address")
+	if err != nil {
+		s.renderDomainError(w, err)
 		return
 	}
 
@@ -465,12 +1330,25 @@ func (s *Server) handleGetBalance(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	data := map[string]interface{}{
+		"address": walletAddress,
+		"balance": balance,
+	}
+
+	// Report funds held by reservations when the processor tracks them
+	if reader, ok := s.txProcessor.(txproc.ReservationReader); ok {
+		available, held, err := reader.GetAvailableBalance(walletAddress)
+		if err != nil {
+			s.renderError(w, "Failed to retrieve balance", http.StatusInternalServerError)
+			return
+		}
+		data["available"] = available
+		data["held"] = held
+	}
+
 	resp := Response{
 		Success: true,
-		Data: map[string]interface{}{
-			"address": walletAddress,
-			"balance": balance,
-		},
+		Data:    data,
 	}
 
 	s.renderJSON(w, resp, http.StatusOK)
@@ -479,58 +1357,145 @@ func (s *Server) handleGetBalance(w http.ResponseWriter, r *http.Request) {
 // handleGetTransactions handles transaction history requests
 func (s *Server) handleGetTransactions(w http.ResponseWriter, r *http.Request) {
 	// Get user from JWT token
-	_, claims, err := jwtauth.FromContext(r.Context())
+	walletAddress, err := claimFromRequest(r, "wallet_address")
 	if err != nil {
-		s.renderError(w, "Authentication error", http.StatusUnauthorized)
+		s.renderDomainError(w, err)
 		return
 	}
 
-	walletAddress, ok := claims["wallet_address"].(string)
+	// Get pagination parameters
+	limit, offset := paginationParams(r)
+
+	// Get transactions from the processor's history store
+	history, ok := s.txProcessor.(txproc.HistoryReader)
 	if !ok {
-		s.renderError(w, "Invalid token claims", http.StatusBadRequest)
+		s.renderError(w, "Transaction history not supported", http.StatusNotImplemented)
 		return
 	}
 
-	// Get pagination parameters
-	limitStr := r.URL.Query().Get("limit")
-	offsetStr := r.URL.Query().Get("offset")
+	transactions, err := history.GetUserTransactions(walletAddress, limit, offset)
+	if err != nil {
+		s.renderError(w, "Failed to retrieve transactions", http.StatusInternalServerError)
+		return
+	}
+
+	total, err := history.CountUserTransactions(walletAddress)
+	if err != nil {
+		s.renderError(w, "Failed to count transactions", http.StatusInternalServerError)
+		return
+	}
+
+	resp := Response{
+		Success: true,
+		Data: map[string]interface{}{
+			"transactions": transactions,
+			"pagination": map[string]interface{}{
+				"limit":    limit,
+				"offset":   offset,
+				"total":    total,
+				"has_more": offset+int64(len(transactions)) < total,
+			},
+		},
+	}
+
+	s.renderJSON(w, resp, http.StatusOK)
+}
+
+// errTransactionNotFound is returned for transactions that do not exist or
+// that the user is not a party to
+var errTransactionNotFound = apierrors.NewAPIError(apierrors.APIErrNotFound, "Transaction not found", nil)
 
-	limit := int64(10) // Default
-	offset := int64(0) // Default
+// paginationParams returns the limit and offset query parameters, using
+// defaults for missing or invalid values
+func paginationParams(r *http.Request) (limit, offset int64) {
+	limit = 10 // Default
+	offset = 0 // Default
 
-	if limitStr != "" {
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
 		if l, err := strconv.ParseInt(limitStr, 10, 64); err == nil && l > 0 {
 			limit = l
 		}
 	}
 
-	if offsetStr != "" {
+	if offsetStr := r.URL.Query().Get("offset"); offsetStr != "" {
 		if o, err := strconv.ParseInt(offsetStr, 10, 64); err == nil && o >= 0 {
 			offset = o
 		}
 	}
 
-	// Get transactions from Redis
-	// This assumes the txProcessor interface has a GetUserTransactions method
-	// If it doesn't, you'll need to modify this code
-	transactions, err := s.txProcessor.(interface {
-		GetUserTransactions(string, int64, int64) ([]*transaction.Transaction, error)
-	}).GetUserTransactions(walletAddress, limit, offset)
+	return limit, offset
+}
+
+// handleGetTransaction returns the current status of a transaction
+func (s *Server) handleGetTransaction(w http.ResponseWriter, r *http.Request) {
+	// Get user from JWT token
+	walletAddress, err := claimFromRequest(r, "wallet_address")
 	if err != nil {
-		s.renderError(w, "Failed to retrieve transactions", http.StatusInternalServerError)
+		s.renderDomainError(w, err)
+		return
+	}
+
+	txID := chi.URLParam(r, "id")
+	if txID == "" {
+		s.renderDomainError(w, apierrors.NewAPIError(apierrors.APIErrValidation, "Transaction ID is required", nil))
+		return
+	}
+
+	reader, ok := s.txProcessor.(txproc.Reader)
+	if !ok {
+		s.renderError(w, "Transaction lookup not supported", http.StatusNotImplemented)
+		return
+	}
+
+	tx, err := reader.GetTransaction(txID)
+	if err != nil && !apierrors.IsStorageError(err, apierrors.StorageErrNotFound) && !errors.Is(err, redis.Nil) {
+		s.renderDomainError(w, apierrors.NewAPIError(apierrors.APIErrInternalServer, "Failed to retrieve transaction", err))
+		return
+	}
+
+	if tx == nil {
+		// Not stored yet; report it as pending if this user submitted it
+		sender, err := s.redisClient.Get(r.Context(), submittedTxPrefix+txID).Result()
+		if err != nil && err != redis.Nil {
+			s.renderDomainError(w, apierrors.NewAPIError(apierrors.APIErrInternalServer, "Failed to retrieve transaction", err))
+			return
+		}
+		if sender == "" || sender != walletAddress {
+			s.renderDomainError(w, errTransactionNotFound)
+			return
+		}
+
+		resp := Response{
+			Success: true,
+			Data: map[string]interface{}{
+				"transaction_id": txID,
+				"status":         transaction.Pending,
+			},
+		}
+		s.renderJSON(w, resp, http.StatusOK)
+		return
+	}
+
+	// Only parties to the transaction may see it
+	if tx.Sender != walletAddress && tx.Receiver != walletAddress {
+		s.renderDomainError(w, errTransactionNotFound)
 		return
 	}
 
+	data := map[string]interface{}{
+		"transaction_id": tx.ID,
+		"status":         tx.Status,
+		"amount":         tx.Amount,
+		"fee":            tx.Fee,
+		"timestamp":      tx.Timestamp,
+	}
+	if tx.Status == transaction.Failed {
+		data["reason"] = tx.Description
+	}
+
 	resp := Response{
 		Success: true,
-		Data: map[string]interface{}{
-			"transactions": transactions,
-			"pagination": map[string]interface{}{
-				"limit":  limit,
-				"offset": offset,
-				"total":  len(transactions), // In a real implementation, you'd get the total count
-			},
-		},
+		Data:    data,
 	}
 
 	s.renderJSON(w, resp, http.StatusOK)
@@ -539,15 +1504,9 @@ func (s *Server) handleGetTransactions(w http.ResponseWriter, r *http.Request) {
 // handleTransfer handles money transfer requests
 func (s *Server) handleTransfer(w http.ResponseWriter, r *http.Request) {
 	// Get user from JWT token
-	_, claims, err := jwtauth.FromContext(r.Context())
+	senderAddress, err := claimFromRequest(r, "wallet_address")
 	if err != nil {
-		s.renderError(w, "Authentication error", http.StatusUnauthorized)
-		return
-	}
-
-	senderAddress, ok := claims["wallet_address"].(string)
-	if !ok {
-		s.renderError(w, "Invalid token claims", http.StatusBadRequest)
+		s.renderDomainError(w, err)
 		return
 	}
 
@@ -587,19 +1546,15 @@ func (s *Server) handleTransfer(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Generate nonce for transaction
-	nonce, err := wallet.GenerateNonce()
+	// Reserve the sender's next sequential nonce
+	nonce, err := s.nextNonce(r.Context(), senderAddress)
 	if err != nil {
 		s.renderError(w, "Failed to generate nonce", http.StatusInternalServerError)
 		return
 	}
 
 	// Create transaction
-	// Calculate fee (0.1% of the amount, minimum 0.01)
-	fee := req.Amount * 0.001
-	if fee < 0.01 {
-		fee = 0.01
-	}
+	fee := s.feeSchedule.CalculateFee(transaction.Payment, req.Amount)
 
 	tx, err := transaction.NewTransaction(
 		senderAddress,
@@ -628,13 +1583,37 @@ func (s *Server) handleTransfer(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Verify the signature against the registered public key so a transfer the
+	// processor would reject fails here rather than downstream
+	if s.securityManager != nil {
+		err = s.securityManager.VerifySignature(r.Context(), senderAddress, signData, tx.Signature)
+		switch {
+		case errors.Is(err, security.ErrPublicKeyNotFound):
+			s.renderError(w, "No public key registered for the sender", http.StatusBadRequest)
+			return
+		case errors.Is(err, security.ErrSignatureMismatch):
+			s.renderError(w, "Transaction signature does not match the registered public key", http.StatusBadRequest)
+			return
+		case err != nil:
+			s.renderError(w, "Failed to verify transaction signature", http.StatusInternalServerError)
+			return
+		}
+	}
+
 	// Submit transaction to processor
 	err = s.txProcessor.SubmitTransaction(tx)
 	if err != nil {
+		s.metricsCollector.RecordDomainError(err)
 		s.renderError(w, "Failed to submit transaction", http.StatusInternalServerError)
 		return
 	}
 
+	// Remember the submission so status queries can report it as pending
+	// until the processor stores it
+	if err := s.redisClient.Set(r.Context(), submittedTxPrefix+tx.ID, senderAddress, submittedTxTTL).Err(); err != nil {
+		logging.FromContext(r.Context(), s.logger).Warn("Failed to record submitted transaction", "transaction_id", tx.ID, "error", err)
+	}
+
 	resp := Response{
 		Success: true,
 		Message: "Transaction submitted successfully",
@@ -649,18 +1628,48 @@ func (s *Server) handleTransfer(w http.ResponseWriter, r *http.Request) {
 	s.renderJSON(w, resp, http.StatusOK)
 }
 
+// handleFeeEstimate handles fee estimation requests
+func (s *Server) handleFeeEstimate(w http.ResponseWriter, r *http.Request) {
+	amount, err := strconv.ParseFloat(r.URL.Query().Get("amount"), 64)
+	if err != nil || amount < 0 {
+		s.renderError(w, "Invalid amount", http.StatusBadRequest)
+		return
+	}
+
+	// Default to payment fees when no type is given
+	txType := transaction.Payment
+	if typeStr := r.URL.Query().Get("type"); typeStr != "" {
+		txType = transaction.TransactionType(strings.ToUpper(typeStr))
+	}
+
+	switch txType {
+	case transaction.Payment, transaction.Deposit, transaction.Withdrawal:
+	default:
+		s.renderError(w, "Invalid transaction type", http.StatusBadRequest)
+		return
+	}
+
+	fee := s.feeSchedule.CalculateFee(txType, amount)
+
+	resp := Response{
+		Success: true,
+		Data: map[string]interface{}{
+			"type":   txType,
+			"amount": amount,
+			"fee":    fee,
+			"total":  amount + fee,
+		},
+	}
+
+	s.renderJSON(w, resp, http.StatusOK)
+}
+
 // handleGetWalletInfo handles wallet info requests
 func (s *Server) handleGetWalletInfo(w http.ResponseWriter, r *http.Request) {
 	// Get user from JWT token
-	_, claims, err := jwtauth.FromContext(r.Context())
+	walletAddress, err := claimFromRequest(r, "wallet_address")
 	if err != nil {
-		s.renderError(w, "Authentication error", http.StatusUnauthorized)
-		return
-	}
-
-	walletAddress, ok := claims["wallet_address"].(string)
-	if !ok {
-		s.renderError(w, "Invalid token claims", http.StatusBadRequest)
+		s.renderDomainError(w, err)
 		return
 	}
 
@@ -677,6 +1686,95 @@ func (s *Server) handleGetWalletInfo(w http.ResponseWriter, r *http.Request) {
 	s.renderJSON(w, resp, http.StatusOK)
 }
 
+// handleRegisterPublicKey registers the public key of the authenticated
+// user's wallet. The key is not checked against the wallet address, since
+// address derivation belongs to the wallet package.
+func (s *Server) handleRegisterPublicKey(w http.ResponseWriter, r *http.Request) {
+	address, err := claimFromRequest(r, "wallet_address")
+	if err != nil {
+		s.renderDomainError(w, err)
+		return
+	}
+
+	var req struct {
+		PublicKey string `json:"public_key"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.renderError(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	pubKey, err := base64.StdEncoding.DecodeString(req.PublicKey)
+	if err != nil || req.PublicKey == "" {
+		s.renderError(w, "Public key must be base64-encoded", http.StatusBadRequest)
+		return
+	}
+
+	if !s.registerPublicKey(w, r, address, pubKey) {
+		return
+	}
+
+	resp := Response{
+		Success: true,
+		Message: "Public key registered",
+		Data: map[string]interface{}{
+			"address":    address,
+			"public_key": req.PublicKey,
+		},
+	}
+
+	s.renderJSON(w, resp, http.StatusOK)
+}
+
+// registerPublicKey registers a wallet's public key, rendering an error and
+// returning false if it could not be registered
+func (s *Server) registerPublicKey(w http.ResponseWriter, r *http.Request, address string, pubKey []byte) bool {
+	if s.securityManager == nil {
+		s.renderError(w, "Public key registration not supported", http.StatusNotImplemented)
+		return false
+	}
+
+	err := s.securityManager.RegisterPublicKey(r.Context(), address, pubKey)
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, security.ErrInvalidPublicKey):
+		s.renderError(w, "Public key must be a 32-byte ed25519 key", http.StatusBadRequest)
+	case errors.Is(err, security.ErrPublicKeyConflict):
+		s.renderError(w, "A different public key is already registered for this wallet", http.StatusConflict)
+	default:
+		logging.FromContext(r.Context(), s.logger).Error("Failed to register wallet public key", "address", address, "error", err)
+		s.renderError(w, "Failed to register public key", http.StatusInternalServerError)
+	}
+	return false
+}
+
+// handleGetPublicKey returns the public key registered for a wallet address
+func (s *Server) handleGetPublicKey(w http.ResponseWriter, r *http.Request) {
+	address := chi.URLParam(r, "address")
+
+	pubKey, err := s.securityManager.GetPublicKey(r.Context(), address)
+	if errors.Is(err, security.ErrPublicKeyNotFound) {
+		s.renderError(w, "No public key registered for this address", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		s.renderError(w, "Failed to get public key", http.StatusInternalServerError)
+		return
+	}
+
+	resp := Response{
+		Success: true,
+		Data: map[string]interface{}{
+			"address":    address,
+			"public_key": base64.StdEncoding.EncodeToString(pubKey),
+		},
+	}
+
+	s.renderJSON(w, resp, http.StatusOK)
+}
+
 // handleGetOrderBook handles order book requests
 func (s *Server) handleGetOrderBook(w http.ResponseWriter, r *http.Request) {
 	// Get depth parameter
@@ -707,15 +1805,9 @@ func (s *Server) handleGetOrderBook(w http.ResponseWriter, r *http.Request) {
 // handlePlaceOrder handles order placement requests
 func (s *Server) handlePlaceOrder(w http.ResponseWriter, r *http.Request) {
 	// Get user from JWT token
-	_, claims, err := jwtauth.FromContext(r.Context())
+	userID, err := claimFromRequest(r, "user_id")
 	if err != nil {
-		s.renderError(w, "Authentication error", http.StatusUnauthorized)
-		return
-	}
-
-	userID, ok := claims["user_id"].(string)
-	if !ok {
-		s.renderError(w, "Invalid token claims", http.StatusBadRequest)
+		s.renderDomainError(w, err)
 		return
 	}
 
@@ -776,15 +1868,9 @@ func (s *Server) handlePlaceOrder(w http.ResponseWriter, r *http.Request) {
 // handleCancelOrder handles order cancellation requests
 func (s *Server) handleCancelOrder(w http.ResponseWriter, r *http.Request) {
 	// Get user from JWT token
-	_, claims, err := jwtauth.FromContext(r.Context())
+	userID, err := claimFromRequest(r, "user_id")
 	if err != nil {
-		s.renderError(w, "Authentication error", http.StatusUnauthorized)
-		return
-	}
-
-	userID, ok := claims["user_id"].(string)
-	if !ok {
-		s.renderError(w, "Invalid token claims", http.StatusBadRequest)
+		s.renderDomainError(w, err)
 		return
 	}
 
@@ -854,35 +1940,119 @@ func (s *Server) handleGetInflationRate(w http.ResponseWriter, r *http.Request)
 	s.renderJSON(w, resp, http.StatusOK)
 }
 
-// handleAdjustInflation handles inflation rate adjustment requests (admin only)
+// handleAdjustInflation handles inflation rate adjustment requests (admin only).
+// The supply manager that owns the inflation bounds is not wired into the API
+// server, so adjustments are refused rather than reported as applied.
 func (s *Server) handleAdjustInflation(w http.ResponseWriter, r *http.Request) {
-	var req struct {
-		MinRate float64 `json:"min_rate"`
-		MaxRate float64 `json:"max_rate"`
-		MaxStep float64 `json:"max_step"`
+	s.renderError(w, "Inflation adjustment not supported", http.StatusNotImplemented)
+}
+
+// handleVerifyLedger handles ledger integrity verification requests (admin only)
+func (s *Server) handleVerifyLedger(w http.ResponseWriter, r *http.Request) {
+	if s.ledgerVerifier == nil {
+		s.renderError(w, "Ledger verification not supported", http.StatusNotImplemented)
+		return
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		s.renderError(w, "Invalid request", http.StatusBadRequest)
+	report := s.ledgerVerifier.CheckIntegrity()
+
+	s.metricsCollector.RecordLedgerIntegrityCheck(report.Valid)
+
+	data := map[string]interface{}{
+		"valid":     report.Valid,
+		"entries":   report.Entries,
+		"timestamp": time.Now().Unix(),
+	}
+	if !report.Valid {
+		data["broken_at"] = report.BrokenAt
+		data["reason"] = report.Reason
+	}
+
+	resp := Response{
+		Success: true,
+		Data:    data,
+	}
+
+	s.renderJSON(w, resp, http.StatusOK)
+}
+
+// handleListFailedTransactions returns a page of failed transactions awaiting review (admin only)
+func (s *Server) handleListFailedTransactions(w http.ResponseWriter, r *http.Request) {
+	limit, offset := paginationParams(r)
+	store := txproc.NewFailedStore(s.redisClient)
+
+	failed, err := store.List(r.Context(), limit, offset)
+	if err != nil {
+		s.renderDomainError(w, apierrors.NewAPIError(apierrors.APIErrInternalServer, "Failed to retrieve failed transactions", err))
 		return
 	}
 
-	// Validate input
-	if req.MinRate < 0 || req.MaxRate <= req.MinRate || req.MaxStep <= 0 {
-		s.renderError(w, "Invalid inflation parameters", http.StatusBadRequest)
+	total, err := store.Count(r.Context())
+	if err != nil {
+		s.renderDomainError(w, apierrors.NewAPIError(apierrors.APIErrInternalServer, "Failed to count failed transactions", err))
+		return
+	}
+
+	resp := Response{
+		Success: true,
+		Data: map[string]interface{}{
+			"transactions": failed,
+			"pagination": map[string]interface{}{
+				"limit":    limit,
+				"offset":   offset,
+				"total":    total,
+				"has_more": offset+int64(len(failed)) < total,
+			},
+		},
+	}
+
+	s.renderJSON(w, resp, http.StatusOK)
+}
+
+// handleRequeueTransaction resubmits a reviewed failed transaction as pending (admin only)
+func (s *Server) handleRequeueTransaction(w http.ResponseWriter, r *http.Request) {
+	txID := chi.URLParam(r, "id")
+	store := txproc.NewFailedStore(s.redisClient)
+
+	// Take the record out of the store first, so concurrent requeues of the
+	// same transaction cannot both submit it
+	record, err := store.Claim(r.Context(), txID)
+	if apierrors.IsStorageError(err, apierrors.StorageErrNotFound) {
+		s.renderDomainError(w, apierrors.NewAPIError(apierrors.APIErrNotFound, "Failed transaction not found", nil))
+		return
+	}
+	if err != nil {
+		s.renderDomainError(w, apierrors.NewAPIError(apierrors.APIErrInternalServer, "Failed to retrieve failed transaction", err))
+		return
+	}
+
+	logger := logging.FromContext(r.Context(), s.logger)
+	tx := record.Transaction
+	tx.Status = transaction.Pending
+	if err := s.txProcessor.SubmitTransaction(tx); err != nil {
+		// Put the record back so the transaction can still be reviewed
+		tx.Status = transaction.Failed
+		if restoreErr := store.Restore(r.Context(), record); restoreErr != nil {
+			logger.Error("Failed to restore failed transaction record", "transaction_id", tx.ID, "error", restoreErr)
+		}
+		s.metricsCollector.RecordDomainError(err)
+		s.renderError(w, "Failed to submit transaction", http.StatusInternalServerError)
 		return
 	}
 
-	// In a real implementation, you would update the inflation rate
-	// For this implementation, we'll just return a success response
+	adminID, _ := claimFromRequest(r, "user_id")
+	logger.Info("Requeued failed transaction", "transaction_id", tx.ID, "admin_id", adminID, "reason", record.Reason)
+
+	if err := s.redisClient.Set(r.Context(), submittedTxPrefix+tx.ID, tx.Sender, submittedTxTTL).Err(); err != nil {
+		logger.Warn("Failed to record submitted transaction", "transaction_id", tx.ID, "error", err)
+	}
 
 	resp := Response{
 		Success: true,
-		Message: "Inflation rate updated successfully",
+		Message: "Transaction requeued successfully",
 		Data: map[string]interface{}{
-			"min_rate":  req.MinRate,
-			"max_rate":  req.MaxRate,
-			"timestamp": time.Now().Unix(),
+			"transaction_id": tx.ID,
+			"status":         tx.Status,
 		},
 	}
 
@@ -892,15 +2062,14 @@ func (s *Server) handleAdjustInflation(w http.ResponseWriter, r *http.Request) {
 // adminOnly is middleware to verify the user has admin role
 func (s *Server) adminOnly(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		_, claims, err := jwtauth.FromContext(r.Context())
-		if err != nil {
-			s.renderError(w, "Authentication error", http.StatusUnauthorized)
+		role, err := claimFromRequest(r, "role")
+		if apierrors.IsAPIError(err, apierrors.APIErrUnauthorized) {
+			s.renderDomainError(w, err)
 			return
 		}
 
-		role, ok := claims["role"].(string)
-		if !ok || role != "admin" {
-			s.renderError(w, "Admin access required", http.StatusForbidden)
+		if role != "admin" {
+			s.renderDomainError(w, apierrors.NewAPIError(apierrors.APIErrForbidden, "Admin access required", nil))
 			return
 		}
 
@@ -920,12 +2089,18 @@ func (s *Server) renderJSON(w http.ResponseWriter, data interface{}, status int)
 
 // renderError renders an error response
 func (s *Server) renderError(w http.ResponseWriter, message string, status int) {
+	s.writeError(w, errorCodeForStatus(status), message, status)
+}
+
+// writeError renders an error response with a machine-readable code
+func (s *Server) writeError(w http.ResponseWriter, code, message string, status int) {
 	// Record error metric
 	s.metricsCollector.RecordError("api", "http", strconv.Itoa(status))
 
 	resp := Response{
 		Success: false,
 		Error:   message,
+		Code:    code,
 	}
 
 	s.renderJSON(w, resp, status)
@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/cmatc13/stathera/internal/orderbook"
@@ -27,8 +28,11 @@ type APIService struct {
 	logger           *logging.Logger
 	metricsCollector *metrics.Metrics
 	healthRegistry   *health.Registry
+	buildInfo        health.BuildInfo
 	metricsServer    *http.Server
 	healthServer     *http.Server
+	stopConsumers    context.CancelFunc // stops the transaction event consumers
+	consumersDone    sync.WaitGroup
 }
 
 // NewAPIService creates a new API service
@@ -37,25 +41,37 @@ func NewAPIService(
 	txProcessor *processor.TransactionProcessor,
 	orderbook *orderbook.OrderBookService,
 ) *APIService {
-	// Set up structured logger
+	// Set up structured logger, falling back to stdout if the output can't be opened
+	logOutput, logOutputErr := logging.OpenOutput(cfg.Log.OutputPath)
+	if logOutputErr != nil {
+		logOutput = logging.DefaultConfig().Output
+	}
 	logCfg := logging.Config{
 		Level:       logging.LogLevel(cfg.Log.Level),
-		Output:      logging.DefaultConfig().Output,
+		Format:      logging.LogFormat(cfg.Log.Format),
+		Output:      logOutput,
 		ServiceName: "api-service",
 		Environment: cfg.Log.Environment,
 	}
 	logger := logging.New(logCfg)
+	if logOutputErr != nil {
+		logger.Warn("Failed to open log output, logging to stdout", "error", logOutputErr)
+	}
 
 	// Set up metrics
 	metricsCfg := metrics.Config{
 		Namespace:   cfg.Metrics.Namespace,
 		Subsystem:   "api",
 		ServiceName: "api-service",
+
+		RequestDurationBuckets:   cfg.Metrics.RequestDurationBuckets,
+		TransactionAmountBuckets: cfg.Metrics.TransactionAmountBuckets,
 	}
 	metricsCollector := metrics.New(metricsCfg)
 
 	// Set up health registry
 	healthRegistry := health.NewRegistry(logger)
+	healthRegistry.SetCheckTimeout(cfg.Health.CheckTimeout())
 
 	return &APIService{
 		config:           cfg,
@@ -79,7 +95,21 @@ func (s *APIService) Start(ctx context.Context) error {
 	s.logger.Info("Starting API service")
 
 	// Initialize the API server
-	s.server = NewServer(s.config, s.txProcessor, s.orderbook.GetOrderBook())
+	server, err := NewServer(s.config, s.txProcessor, s.orderbook.GetOrderBook())
+	if err != nil {
+		s.status = service.StatusError
+		return fmt.Errorf("failed to create API server: %w", err)
+	}
+	s.server = server
+	s.server.healthRegistry.SetBuildInfo(s.buildInfo)
+
+	// Consume the events processors publish: failed transactions are
+	// recorded for admin review, and confirmations are streamed to clients
+	if err := s.startConsumers(); err != nil {
+		s.status = service.StatusError
+		server.Shutdown(ctx)
+		return err
+	}
 
 	// Start the server
 	go s.server.Start()
@@ -101,6 +131,19 @@ func (s *APIService) Stop(ctx context.Context) error {
 	s.status = service.StatusStopping
 	s.logger.Info("Stopping API service")
 
+	if s.stopConsumers != nil {
+		s.stopConsumers()
+		done := make(chan struct{})
+		go func() {
+			s.consumersDone.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-ctx.Done():
+		}
+	}
+
 	if s.server != nil {
 		s.server.Shutdown(ctx)
 	}
@@ -110,6 +153,51 @@ func (s *APIService) Stop(ctx context.Context) error {
 	return nil
 }
 
+// startConsumers starts the consumers of the failed and confirmed topics
+func (s *APIService) startConsumers() error {
+	failed, err := txproc.NewFailedConsumer(s.config.Kafka, txproc.NewFailedStore(s.server.redisClient), s.logger)
+	if err != nil {
+		return err
+	}
+	confirmations, err := txproc.NewConfirmationConsumer(s.config.Kafka, s.server.redisClient, s.logger)
+	if err != nil {
+		failed.Close()
+		return err
+	}
+
+	consumeCtx, stop := context.WithCancel(context.Background())
+	s.stopConsumers = stop
+	for _, consumer := range []*txproc.Consumer{failed, confirmations} {
+		s.consumersDone.Add(1)
+		consumer.SetMetrics(s.server.metricsCollector)
+		go func(consumer *txproc.Consumer) {
+			defer s.consumersDone.Done()
+			defer consumer.Close()
+			consumer.Run(consumeCtx)
+		}(consumer)
+	}
+
+	return nil
+}
+
+// ApplyConfig applies a reloaded configuration to the running service
+func (s *APIService) ApplyConfig(cfg *config.Config) {
+	s.logger.SetLevel(logging.LogLevel(cfg.Log.Level))
+
+	if s.server != nil {
+		s.server.ApplyConfig(cfg)
+	}
+}
+
+// SetBuildInfo sets the build information reported by the service's health checks
+func (s *APIService) SetBuildInfo(info health.BuildInfo) {
+	s.buildInfo = info
+	s.healthRegistry.SetBuildInfo(info)
+	if s.server != nil {
+		s.server.healthRegistry.SetBuildInfo(info)
+	}
+}
+
 // Status returns the current service status
 func (s *APIService) Status() service.Status {
 	return s.status
@@ -0,0 +1,96 @@
+// internal/api/stream.go
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/cmatc13/stathera/pkg/logging"
+	txproc "github.com/cmatc13/stathera/pkg/transaction"
+)
+
+// streamHeartbeatInterval is how often an idle event stream sends a comment to keep the connection open
+const streamHeartbeatInterval = 15 * time.Second
+
+// handleTransactionStream streams confirmations of the authenticated user's
+// transactions as Server-Sent Events
+func (s *Server) handleTransactionStream(w http.ResponseWriter, r *http.Request) {
+	// Get user from JWT token
+	walletAddress, err := claimFromRequest(r, "wallet_address")
+	if err != nil {
+		s.renderDomainError(w, err)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		s.renderError(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	if s.redisClient == nil {
+		s.renderError(w, "Transaction stream unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	ctx := r.Context()
+	logger := logging.FromContext(ctx, s.logger)
+
+	// Subscribe before responding so no confirmation is missed after the client is told it is connected
+	sub := s.redisClient.Subscribe(ctx, txproc.ConfirmationChannel(walletAddress))
+	defer sub.Close()
+
+	if _, err := sub.Receive(ctx); err != nil {
+		logger.Warn("Failed to subscribe to transaction stream", "error", err.Error())
+		s.renderError(w, "Transaction stream unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	// Forward messages, keeping only the latest when the client falls behind
+	latest := make(chan *redis.Message, 1)
+	go func() {
+		for msg := range sub.Channel() {
+			select {
+			case latest <- msg:
+			default:
+				select {
+				case <-latest:
+				default:
+				}
+				latest <- msg
+			}
+		}
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg := <-latest:
+			if _, err := fmt.Fprintf(w, "event: confirmed\ndata: %s\n\n", msg.Payload); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
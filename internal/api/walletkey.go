@@ -0,0 +1,71 @@
+// internal/api/walletkey.go
+package api
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+// errWalletKeyMismatch is returned when a wallet's signatures do not verify
+// against the public key recovered for it
+var errWalletKeyMismatch = errors.New("wallet does not sign with the recovered public key")
+
+// walletKeyProbe is the message signed to check a wallet's public key
+var walletKeyProbe = []byte("stathera wallet public key check")
+
+// signingWallet is the part of a wallet needed to recover its public key
+type signingWallet interface {
+	ExportPrivateKey() string
+	SignMessage(message []byte) ([]byte, error)
+}
+
+// publicKeyWallet is implemented by wallets that expose their ed25519 public key
+type publicKeyWallet interface {
+	PublicKeyBytes() []byte
+}
+
+// walletPublicKey returns the ed25519 public key a wallet signs with. It is
+// taken from the wallet when exposed, or else derived from the exported
+// private key, and only returned once it verifies a signature by the wallet.
+func walletPublicKey(w signingWallet) (ed25519.PublicKey, error) {
+	var pubKey ed25519.PublicKey
+	if keyed, ok := w.(publicKeyWallet); ok {
+		pubKey = keyed.PublicKeyBytes()
+	} else {
+		privKey, err := decodePrivateKey(w.ExportPrivateKey())
+		if err != nil {
+			return nil, err
+		}
+		pubKey = privKey.Public().(ed25519.PublicKey)
+	}
+
+	signature, err := w.SignMessage(walletKeyProbe)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign with wallet: %w", err)
+	}
+	if len(pubKey) != ed25519.PublicKeySize || !ed25519.Verify(pubKey, walletKeyProbe, signature) {
+		return nil, errWalletKeyMismatch
+	}
+
+	return pubKey, nil
+}
+
+// decodePrivateKey decodes a hex or base64 ed25519 private key or seed
+func decodePrivateKey(encoded string) (ed25519.PrivateKey, error) {
+	for _, decode := range []func(string) ([]byte, error){hex.DecodeString, base64.StdEncoding.DecodeString} {
+		raw, err := decode(encoded)
+		if err != nil {
+			continue
+		}
+		switch len(raw) {
+		case ed25519.PrivateKeySize:
+			return ed25519.PrivateKey(raw), nil
+		case ed25519.SeedSize:
+			return ed25519.NewKeyFromSeed(raw), nil
+		}
+	}
+	return nil, errors.New("wallet private key is not an ed25519 key")
+}
@@ -0,0 +1,72 @@
+// internal/api/walletkey_test.go
+package api
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"testing"
+)
+
+// fakeWallet signs with an ed25519 key and exports it in a fixed encoding
+type fakeWallet struct {
+	key     ed25519.PrivateKey
+	encoded string
+}
+
+func (w fakeWallet) ExportPrivateKey() string { return w.encoded }
+
+func (w fakeWallet) SignMessage(message []byte) ([]byte, error) {
+	return ed25519.Sign(w.key, message), nil
+}
+
+// keyedWallet is a fakeWallet that exposes a public key of its own
+type keyedWallet struct {
+	fakeWallet
+	pubKey ed25519.PublicKey
+}
+
+func (w keyedWallet) PublicKeyBytes() []byte { return w.pubKey }
+
+func TestWalletPublicKey(t *testing.T) {
+	pubKey, privKey, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	otherKey, _, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		wallet  signingWallet
+		wantErr bool
+	}{
+		{name: "hex private key", wallet: fakeWallet{key: privKey, encoded: hex.EncodeToString(privKey)}},
+		{name: "hex seed", wallet: fakeWallet{key: privKey, encoded: hex.EncodeToString(privKey.Seed())}},
+		{name: "base64 private key", wallet: fakeWallet{key: privKey, encoded: base64.StdEncoding.EncodeToString(privKey)}},
+		{name: "exposed public key", wallet: keyedWallet{fakeWallet: fakeWallet{key: privKey}, pubKey: pubKey}},
+		{name: "exposed key it does not sign with", wallet: keyedWallet{fakeWallet: fakeWallet{key: privKey}, pubKey: otherKey}, wantErr: true},
+		{name: "unreadable private key", wallet: fakeWallet{key: privKey, encoded: "not a key"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := walletPublicKey(tt.wallet)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("walletPublicKey = %x, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("walletPublicKey: %v", err)
+			}
+			if !bytes.Equal(got, pubKey) {
+				t.Fatalf("walletPublicKey = %x, want %x", got, pubKey)
+			}
+		})
+	}
+}
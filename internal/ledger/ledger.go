@@ -46,6 +46,16 @@ type Ledger struct {
 	minInflation float64
 	maxInflation float64
 	timeOracle   TimeOracle
+
+	// Time of the minting schedule's last mint, or of its start, and the
+	// store it is persisted in
+	lastMint  int64
+	mintStore MintStateStore
+
+	// Entry subscribers, guarded by notifyMu, which also keeps
+	// notifications in append order
+	notifyMu    sync.Mutex
+	subscribers []chan *LedgerEntry
 }
 
 // TimeOracle defines the interface for time-related operations
@@ -123,14 +133,15 @@ func (l *Ledger) GetLatestHash() string {
 }
 
 // MintSupply increases the total supply based on the provided inflation rate
+// and returns the hash of the new ledger entry.
 // This is the only way to increase the total supply in the system
-func (l *Ledger) MintSupply(ctx context.Context, inflationRate float64, reason string) error {
+func (l *Ledger) MintSupply(ctx context.Context, inflationRate float64, reason string) (string, error) {
 	l.mu.Lock()
-	defer l.mu.Unlock()
 
 	// Validate inflation rate
 	if inflationRate < l.minInflation || inflationRate > l.maxInflation {
-		return fmt.Errorf("inflation rate %.4f outside allowed range [%.4f, %.4f]",
+		l.mu.Unlock()
+		return "", fmt.Errorf("inflation rate %.4f outside allowed range [%.4f, %.4f]",
 			inflationRate, l.minInflation, l.maxInflation)
 	}
 
@@ -158,33 +169,90 @@ func (l *Ledger) MintSupply(ctx context.Context, inflationRate float64, reason s
 	l.entries = append(l.entries, entry)
 	l.latestHash = entry.Hash
 
-	return nil
+	// Notify subscribers without holding the ledger lock
+	l.notifyMu.Lock()
+	l.mu.Unlock()
+	l.notify(entry)
+	l.notifyMu.Unlock()
+
+	return entry.Hash, nil
 }
 
-// VerifyIntegrity checks the integrity of the entire ledger chain
-func (l *Ledger) VerifyIntegrity() (bool, error) {
-	l.mu.RLock()
-	defer l.mu.RUnlock()
+// integrityChunkSize is the number of entries verified per read lock acquisition
+const integrityChunkSize = 1000
 
-	if len(l.entries) == 0 {
-		return false, errors.New("ledger is empty")
-	}
+// IntegrityReport describes the result of a ledger integrity check
+type IntegrityReport struct {
+	Valid    bool   `json:"valid"`
+	Entries  int    `json:"entries"`
+	BrokenAt int    `json:"broken_at"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// CheckIntegrity verifies the ledger chain and reports where it is broken.
+// Entries are verified in chunks so the read lock is never held for the
+// whole chain; entries are append-only, so chunks remain consistent.
+func (l *Ledger) CheckIntegrity() *IntegrityReport {
+	report := &IntegrityReport{Valid: true, BrokenAt: -1}
+
+	var prev *LedgerEntry
+	for start := 0; ; start += integrityChunkSize {
+		// Copy the next chunk under the read lock
+		l.mu.RLock()
+		end := start + integrityChunkSize
+		if end > len(l.entries) {
+			end = len(l.entries)
+		}
+		var chunk []*LedgerEntry
+		if start < end {
+			chunk = make([]*LedgerEntry, end-start)
+			copy(chunk, l.entries[start:end])
+		}
+		l.mu.RUnlock()
 
-	// Verify each entry
-	for i, entry := range l.entries {
-		// Recalculate hash
-		calculatedHash := entry.CalculateHash()
-		if calculatedHash != entry.Hash {
-			return false, fmt.Errorf("invalid hash at entry %d", i)
+		if len(chunk) == 0 {
+			break
 		}
 
-		// Verify chain (except for genesis)
-		if i > 0 {
-			if entry.PrevHash != l.entries[i-1].Hash {
-				return false, fmt.Errorf("broken chain at entry %d", i)
+		// Verify each entry in the chunk
+		for i, entry := range chunk {
+			idx := start + i
+			report.Entries = idx + 1
+
+			// Recalculate hash
+			if entry.CalculateHash() != entry.Hash {
+				report.Valid = false
+				report.BrokenAt = idx
+				report.Reason = fmt.Sprintf("invalid hash at entry %d", idx)
+				return report
+			}
+
+			// Verify chain (except for genesis)
+			if prev != nil && entry.PrevHash != prev.Hash {
+				report.Valid = false
+				report.BrokenAt = idx
+				report.Reason = fmt.Sprintf("broken chain at entry %d", idx)
+				return report
 			}
+
+			prev = entry
 		}
 	}
 
+	if report.Entries == 0 {
+		report.Valid = false
+		report.Reason = "ledger is empty"
+	}
+
+	return report
+}
+
+// VerifyIntegrity checks the integrity of the entire ledger chain
+func (l *Ledger) VerifyIntegrity() (bool, error) {
+	report := l.CheckIntegrity()
+	if !report.Valid {
+		return false, errors.New(report.Reason)
+	}
+
 	return true, nil
 }
@@ -0,0 +1,89 @@
+package ledger
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+// fakeClock is a time oracle whose clock is set by the test
+type fakeClock struct {
+	mu  sync.Mutex
+	now int64
+}
+
+func (c *fakeClock) Now() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) set(now int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = now
+}
+
+func (c *fakeClock) Validate(timestamp int64) error { return nil }
+
+func newTestLedger(t *testing.T, clock *fakeClock) *Ledger {
+	t.Helper()
+
+	l, err := NewLedger(1000, 1, 5, clock)
+	if err != nil {
+		t.Fatalf("NewLedger: %v", err)
+	}
+	return l
+}
+
+// mintEntries appends n mints to a ledger
+func mintEntries(t *testing.T, l *Ledger, n int) {
+	t.Helper()
+
+	for i := 0; i < n; i++ {
+		if _, err := l.MintSupply(context.Background(), 1, "test mint"); err != nil {
+			t.Fatalf("MintSupply: %v", err)
+		}
+	}
+}
+
+func TestCheckIntegrity(t *testing.T) {
+	tests := []struct {
+		name       string
+		entries    int
+		tamper     func(entries []*LedgerEntry)
+		wantBroken int
+	}{
+		{name: "intact", entries: 5, wantBroken: -1},
+		{name: "intact across chunks", entries: integrityChunkSize + 10, wantBroken: -1},
+		{name: "altered supply", entries: 5, tamper: func(e []*LedgerEntry) { e[3].TotalSupply++ }, wantBroken: 3},
+		{name: "rehashed entry breaks the chain", entries: 5, tamper: func(e []*LedgerEntry) {
+			e[2].Delta++
+			e[2].Hash = e[2].CalculateHash()
+		}, wantBroken: 3},
+		{name: "broken in a later chunk", entries: integrityChunkSize + 10, tamper: func(e []*LedgerEntry) {
+			e[integrityChunkSize+1].Reason = "forged"
+		}, wantBroken: integrityChunkSize + 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newTestLedger(t, &fakeClock{now: 1000})
+			mintEntries(t, l, tt.entries-1)
+			if tt.tamper != nil {
+				tt.tamper(l.entries)
+			}
+
+			report := l.CheckIntegrity()
+			if report.BrokenAt != tt.wantBroken || report.Valid != (tt.wantBroken < 0) {
+				t.Fatalf("report = %+v, want broken at %d", report, tt.wantBroken)
+			}
+			if report.Valid && report.Entries != tt.entries {
+				t.Fatalf("verified %d entries, want %d", report.Entries, tt.entries)
+			}
+			if valid, err := l.VerifyIntegrity(); valid != report.Valid || (err == nil) != report.Valid {
+				t.Fatalf("VerifyIntegrity = %v, %v; want it to match the report", valid, err)
+			}
+		})
+	}
+}
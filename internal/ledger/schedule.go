@@ -0,0 +1,232 @@
+package ledger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// scheduledMintReason is the ledger entry reason for scheduled issuance
+const scheduledMintReason = "Scheduled issuance"
+
+// MintStateStore persists when the minting schedule last minted, so a restart
+// cannot mint twice in the same interval
+type MintStateStore interface {
+	// LastMint returns the time of the last scheduled mint, or 0 if none
+	LastMint() (int64, error)
+
+	// SetLastMint records the time of the last scheduled mint
+	SetLastMint(timestamp int64) error
+}
+
+// FileMintStateStore is a MintStateStore backed by a file
+type FileMintStateStore struct {
+	path string
+}
+
+// NewFileMintStateStore creates a store keeping the last mint time in path
+func NewFileMintStateStore(path string) *FileMintStateStore {
+	return &FileMintStateStore{path: path}
+}
+
+// LastMint reads the last mint time. A missing file means no mint happened yet.
+func (s *FileMintStateStore) LastMint() (int64, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read mint state: %w", err)
+	}
+
+	timestamp, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid mint state in %s: %w", s.path, err)
+	}
+	return timestamp, nil
+}
+
+// SetLastMint replaces the stored mint time atomically
+func (s *FileMintStateStore) SetLastMint(timestamp int64) error {
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("failed to create mint state file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.WriteString(strconv.FormatInt(timestamp, 10)); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write mint state: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write mint state: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write mint state: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to replace mint state: %w", err)
+	}
+	return nil
+}
+
+// SetMintStateStore persists the minting schedule in store and resumes it from
+// the last mint recorded there
+func (l *Ledger) SetMintStateStore(store MintStateStore) error {
+	lastMint, err := store.LastMint()
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.mintStore = store
+	l.lastMint = lastMint
+	return nil
+}
+
+// StartMinting mints supply in the background once per interval, at the rate
+// returned by rateFn, until ctx is done. It uses the same schedule as
+// MintIfDue, so running both still mints at most once per interval. The first
+// mint happens in the interval after the schedule first starts.
+func (l *Ledger) StartMinting(ctx context.Context, rateFn func() float64, interval time.Duration) error {
+	if rateFn == nil {
+		return errors.New("rate provider cannot be nil")
+	}
+	if err := checkMintInterval(interval); err != nil {
+		return err
+	}
+	if err := l.startMintSchedule(); err != nil {
+		return err
+	}
+
+	// Check several times per interval so ticker jitter cannot skip a period
+	check := interval / 10
+	if check < time.Second {
+		check = time.Second
+	}
+
+	go func() {
+		ticker := time.NewTicker(check)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				// A failed mint is retried on the next tick
+				l.MintIfDue(ctx, rateFn(), interval)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// MintIfDue mints at rate, clamped to the ledger's inflation bounds, if the
+// schedule has not minted in the current interval yet. It returns the hash
+// of the new entry and whether a mint happened. The interval in which the
+// schedule first starts is skipped.
+//
+// The mint is recorded in the state store before supply is minted, so a
+// crash can skip an interval's mint but never repeat it.
+func (l *Ledger) MintIfDue(ctx context.Context, rate float64, interval time.Duration) (string, bool, error) {
+	if err := checkMintInterval(interval); err != nil {
+		return "", false, err
+	}
+
+	l.mu.Lock()
+	now := l.timeOracle.Now()
+	if l.lastMint == 0 {
+		l.mu.Unlock()
+		return "", false, l.startMintSchedule()
+	}
+	seconds := int64(interval / time.Second)
+	if now/seconds <= l.lastMint/seconds {
+		l.mu.Unlock()
+		return "", false, nil
+	}
+	previous := l.lastMint
+	l.lastMint = now
+	store := l.mintStore
+	l.mu.Unlock()
+
+	if store != nil {
+		if err := store.SetLastMint(now); err != nil {
+			l.restoreLastMint(now, previous)
+			return "", false, err
+		}
+	}
+
+	hash, err := l.MintSupply(ctx, l.clampRate(rate), scheduledMintReason)
+	if err != nil {
+		// Allow the next call to retry this interval
+		l.restoreLastMint(now, previous)
+		if store != nil {
+			store.SetLastMint(previous)
+		}
+		return "", false, err
+	}
+
+	return hash, true, nil
+}
+
+// startMintSchedule marks the current time as the start of the schedule,
+// unless it has minted before
+func (l *Ledger) startMintSchedule() error {
+	l.mu.Lock()
+	if l.lastMint != 0 {
+		l.mu.Unlock()
+		return nil
+	}
+	now := l.timeOracle.Now()
+	l.lastMint = now
+	store := l.mintStore
+	l.mu.Unlock()
+
+	if store != nil {
+		if err := store.SetLastMint(now); err != nil {
+			l.restoreLastMint(now, 0)
+			return err
+		}
+	}
+	return nil
+}
+
+// restoreLastMint undoes a reservation of the schedule made at reserved
+func (l *Ledger) restoreLastMint(reserved, previous int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.lastMint == reserved {
+		l.lastMint = previous
+	}
+}
+
+// checkMintInterval validates a minting interval
+func checkMintInterval(interval time.Duration) error {
+	if interval < time.Second {
+		return errors.New("minting interval must be at least one second")
+	}
+	return nil
+}
+
+// clampRate limits an inflation rate to the ledger's bounds
+func (l *Ledger) clampRate(rate float64) float64 {
+	if rate < l.minInflation {
+		return l.minInflation
+	}
+	if rate > l.maxInflation {
+		return l.maxInflation
+	}
+	return rate
+}
@@ -0,0 +1,161 @@
+package ledger
+
+import (
+	"context"
+	"errors"
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// failingStore is a MintStateStore whose writes fail
+type failingStore struct{}
+
+func (failingStore) LastMint() (int64, error)          { return 0, nil }
+func (failingStore) SetLastMint(timestamp int64) error { return errors.New("disk full") }
+
+// mintRates returns the rate of every mint after genesis
+func mintRates(l *Ledger) []float64 {
+	entries := l.GetEntries()
+	rates := make([]float64, 0, len(entries)-1)
+	for i := 1; i < len(entries); i++ {
+		rate := 100 * entries[i].Delta / entries[i-1].TotalSupply
+		rates = append(rates, math.Round(rate*1e6)/1e6)
+	}
+	return rates
+}
+
+func TestMintIfDueOncePerInterval(t *testing.T) {
+	clock := &fakeClock{now: 1000}
+	l := newTestLedger(t, clock)
+
+	steps := []struct {
+		now        int64
+		wantMinted bool
+	}{
+		{now: 1000, wantMinted: false}, // starts the schedule
+		{now: 1500, wantMinted: false},
+		{now: 3600, wantMinted: true},
+		{now: 3601, wantMinted: false},
+		{now: 7199, wantMinted: false},
+		{now: 7200, wantMinted: true},
+		{now: 18000, wantMinted: true}, // missed intervals are not made up
+		{now: 18001, wantMinted: false},
+	}
+
+	for _, step := range steps {
+		clock.set(step.now)
+		hash, minted, err := l.MintIfDue(context.Background(), 2, time.Hour)
+		if err != nil {
+			t.Fatalf("MintIfDue at %d: %v", step.now, err)
+		}
+		if minted != step.wantMinted {
+			t.Fatalf("MintIfDue at %d minted = %v, want %v", step.now, minted, step.wantMinted)
+		}
+		if minted && hash != l.GetLatestHash() {
+			t.Fatalf("MintIfDue at %d returned %q, want the new entry %q", step.now, hash, l.GetLatestHash())
+		}
+	}
+
+	if got := len(mintRates(l)); got != 3 {
+		t.Fatalf("minted %d times, want 3", got)
+	}
+}
+
+func TestMintIfDueClampsRate(t *testing.T) {
+	tests := []struct {
+		name string
+		rate float64
+		want float64
+	}{
+		{name: "within bounds", rate: 2.5, want: 2.5},
+		{name: "below minimum", rate: 0.1, want: 1},
+		{name: "above maximum", rate: 40, want: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := &fakeClock{now: 1000}
+			l := newTestLedger(t, clock)
+			if _, _, err := l.MintIfDue(context.Background(), tt.rate, time.Hour); err != nil {
+				t.Fatalf("MintIfDue: %v", err)
+			}
+
+			clock.set(3600)
+			if _, minted, err := l.MintIfDue(context.Background(), tt.rate, time.Hour); err != nil || !minted {
+				t.Fatalf("MintIfDue = %v, %v; want a mint", minted, err)
+			}
+
+			if got := mintRates(l); len(got) != 1 || got[0] != tt.want {
+				t.Fatalf("mint rates = %v, want [%v]", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMintScheduleSurvivesRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mint-state")
+	clock := &fakeClock{now: 1000}
+
+	first := newTestLedger(t, clock)
+	if err := first.SetMintStateStore(NewFileMintStateStore(path)); err != nil {
+		t.Fatalf("SetMintStateStore: %v", err)
+	}
+	first.MintIfDue(context.Background(), 2, time.Hour)
+	clock.set(3600)
+	if _, minted, err := first.MintIfDue(context.Background(), 2, time.Hour); err != nil || !minted {
+		t.Fatalf("MintIfDue = %v, %v; want a mint", minted, err)
+	}
+
+	// A restarted ledger resumes from the stored mint, in the same interval
+	clock.set(3700)
+	restarted := newTestLedger(t, clock)
+	if err := restarted.SetMintStateStore(NewFileMintStateStore(path)); err != nil {
+		t.Fatalf("SetMintStateStore: %v", err)
+	}
+	if _, minted, err := restarted.MintIfDue(context.Background(), 2, time.Hour); err != nil || minted {
+		t.Fatalf("MintIfDue after restart = %v, %v; want no mint", minted, err)
+	}
+
+	clock.set(7200)
+	if _, minted, err := restarted.MintIfDue(context.Background(), 2, time.Hour); err != nil || !minted {
+		t.Fatalf("MintIfDue in the next interval = %v, %v; want a mint", minted, err)
+	}
+}
+
+func TestMintIfDueRequiresRecordedMint(t *testing.T) {
+	clock := &fakeClock{now: 1000}
+	l := newTestLedger(t, clock)
+	l.MintIfDue(context.Background(), 2, time.Hour)
+
+	// The store fails only once the schedule has started
+	l.mintStore = failingStore{}
+	clock.set(3600)
+	if _, minted, err := l.MintIfDue(context.Background(), 2, time.Hour); err == nil || minted {
+		t.Fatalf("MintIfDue = %v, %v; want an error and no mint", minted, err)
+	}
+	if got := len(mintRates(l)); got != 0 {
+		t.Fatalf("minted %d times without recording the mint", got)
+	}
+
+	// The interval is retried once the store works again
+	l.mintStore = nil
+	if _, minted, err := l.MintIfDue(context.Background(), 2, time.Hour); err != nil || !minted {
+		t.Fatalf("MintIfDue retry = %v, %v; want a mint", minted, err)
+	}
+}
+
+func TestFileMintStateStore(t *testing.T) {
+	store := NewFileMintStateStore(filepath.Join(t.TempDir(), "mint-state"))
+
+	if got, err := store.LastMint(); err != nil || got != 0 {
+		t.Fatalf("LastMint of a missing file = %d, %v; want 0, nil", got, err)
+	}
+	if err := store.SetLastMint(1234); err != nil {
+		t.Fatalf("SetLastMint: %v", err)
+	}
+	if got, err := store.LastMint(); err != nil || got != 1234 {
+		t.Fatalf("LastMint = %d, %v; want 1234, nil", got, err)
+	}
+}
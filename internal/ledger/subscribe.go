@@ -0,0 +1,72 @@
+package ledger
+
+import (
+	"context"
+
+	"github.com/cmatc13/stathera/pkg/metrics"
+)
+
+// subscriberBuffer is the number of entries buffered for each subscriber
+const subscriberBuffer = 64
+
+// Subscribe returns a channel that receives each new ledger entry in order.
+// Entries are dropped for a subscriber whose buffer is full, so a slow
+// subscriber never stalls minting.
+func (l *Ledger) Subscribe() <-chan *LedgerEntry {
+	ch := make(chan *LedgerEntry, subscriberBuffer)
+
+	l.notifyMu.Lock()
+	defer l.notifyMu.Unlock()
+	l.subscribers = append(l.subscribers, ch)
+
+	return ch
+}
+
+// Unsubscribe stops delivery to a channel returned by Subscribe and closes it
+func (l *Ledger) Unsubscribe(ch <-chan *LedgerEntry) {
+	l.notifyMu.Lock()
+	defer l.notifyMu.Unlock()
+
+	for i, sub := range l.subscribers {
+		if sub == ch {
+			l.subscribers = append(l.subscribers[:i], l.subscribers[i+1:]...)
+			close(sub)
+			return
+		}
+	}
+}
+
+// notify delivers an entry to every subscriber without blocking.
+// The caller must hold notifyMu.
+func (l *Ledger) notify(entry *LedgerEntry) {
+	for _, sub := range l.subscribers {
+		select {
+		case sub <- entry:
+		default:
+			// Subscriber is not keeping up
+		}
+	}
+}
+
+// RecordMetrics keeps the total supply and inflation rate gauges up to date
+// with new ledger entries until ctx is done
+func (l *Ledger) RecordMetrics(ctx context.Context, m *metrics.Metrics) {
+	m.RecordTotalSupply(l.GetTotalSupply())
+
+	entries := l.Subscribe()
+	go func() {
+		defer l.Unsubscribe(entries)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case entry := <-entries:
+				m.RecordTotalSupply(entry.TotalSupply)
+				if prevSupply := entry.TotalSupply - entry.Delta; prevSupply > 0 {
+					m.RecordInflationRate(entry.Delta / prevSupply * 100)
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,40 @@
+package ledger
+
+import "testing"
+
+func TestSubscribe(t *testing.T) {
+	l := newTestLedger(t, &fakeClock{now: 1000})
+	first := l.Subscribe()
+	second := l.Subscribe()
+
+	mintEntries(t, l, 2)
+	l.Unsubscribe(second)
+	mintEntries(t, l, 1)
+
+	entries := l.GetEntries()
+	for i := 1; i < len(entries); i++ {
+		if got := <-first; got.Hash != entries[i].Hash {
+			t.Fatalf("subscriber got entry %s, want entry %d (%s)", got.Hash, i, entries[i].Hash)
+		}
+	}
+
+	// The unsubscribed channel received the first two mints and was closed
+	received := 0
+	for range second {
+		received++
+	}
+	if received != 2 {
+		t.Fatalf("unsubscribed channel received %d entries, want 2", received)
+	}
+}
+
+func TestSlowSubscriberDoesNotBlockMinting(t *testing.T) {
+	l := newTestLedger(t, &fakeClock{now: 1000})
+	slow := l.Subscribe()
+
+	mintEntries(t, l, subscriberBuffer+5)
+
+	if got := len(slow); got != subscriberBuffer {
+		t.Fatalf("slow subscriber buffered %d entries, want %d", got, subscriberBuffer)
+	}
+}
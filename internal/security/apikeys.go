@@ -0,0 +1,104 @@
+// internal/security/apikeys.go
+package security
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// APIKeyInfo describes an API key without exposing the key itself
+type APIKeyInfo struct {
+	ID          string   `json:"id"`
+	Permissions []string `json:"permissions"`
+	CreatedAt   int64    `json:"created_at"`
+	ExpiresAt   int64    `json:"expires_at,omitempty"`
+	LastUsed    int64    `json:"last_used,omitempty"`
+	Revoked     bool     `json:"revoked"`
+}
+
+// RevokeAPIKey marks an API key as revoked so it can no longer be used
+func (sm *SecurityManager) RevokeAPIKey(ctx context.Context, apiKey string) error {
+	return sm.revokeAPIKeyHash(ctx, hashAPIKey(apiKey))
+}
+
+// RevokeAPIKeyByID revokes one of a user's API keys by its ID
+func (sm *SecurityManager) RevokeAPIKeyByID(ctx context.Context, userID, keyID string) error {
+	owned, err := sm.client.SIsMember(ctx, userAPIKeyPrefix+userID, keyID).Result()
+	if err != nil {
+		return fmt.Errorf("failed to look up API key: %w", err)
+	}
+	if !owned {
+		return ErrInvalidAPIKey
+	}
+
+	return sm.revokeAPIKeyHash(ctx, keyID)
+}
+
+// revokeAPIKeyHash marks a stored API key hash as revoked
+func (sm *SecurityManager) revokeAPIKeyHash(ctx context.Context, keyHash string) error {
+	key := apiKeyPrefix + keyHash
+
+	exists, err := sm.client.Exists(ctx, key).Result()
+	if err != nil {
+		return fmt.Errorf("failed to look up API key: %w", err)
+	}
+	if exists == 0 {
+		return ErrInvalidAPIKey
+	}
+
+	if err := sm.client.HSet(ctx, key, "revoked", "1").Err(); err != nil {
+		return fmt.Errorf("failed to revoke API key: %w", err)
+	}
+
+	return nil
+}
+
+// ListAPIKeys returns metadata for all API keys belonging to a user, oldest first
+func (sm *SecurityManager) ListAPIKeys(ctx context.Context, userID string) ([]APIKeyInfo, error) {
+	hashes, err := sm.client.SMembers(ctx, userAPIKeyPrefix+userID).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API keys: %w", err)
+	}
+
+	// Fetch key data in a single round trip
+	pipe := sm.client.Pipeline()
+	cmds := make([]*redis.StringStringMapCmd, len(hashes))
+	for i, hash := range hashes {
+		cmds[i] = pipe.HGetAll(ctx, apiKeyPrefix+hash)
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, fmt.Errorf("failed to get API key data: %w", err)
+	}
+
+	keys := make([]APIKeyInfo, 0, len(hashes))
+	for i, cmd := range cmds {
+		data := cmd.Val()
+		if len(data) == 0 {
+			continue
+		}
+
+		info := APIKeyInfo{
+			ID:      hashes[i],
+			Revoked: data["revoked"] == "1",
+		}
+		if data["permissions"] != "" {
+			info.Permissions = strings.Split(data["permissions"], ",")
+		}
+		info.CreatedAt, _ = strconv.ParseInt(data["created_at"], 10, 64)
+		info.ExpiresAt, _ = strconv.ParseInt(data["expires_at"], 10, 64)
+		info.LastUsed, _ = strconv.ParseInt(data["last_used"], 10, 64)
+
+		keys = append(keys, info)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].CreatedAt < keys[j].CreatedAt
+	})
+
+	return keys, nil
+}
@@ -0,0 +1,113 @@
+// internal/security/idempotency.go
+package security
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	// Idempotency key prefix
+	idempotencyPrefix = "idempotency:"
+
+	// How long a stored response is replayed for duplicate requests
+	idempotencyTTL = 24 * time.Hour
+
+	// How long a request holds its idempotency key before completing
+	idempotencyLockTTL = time.Minute
+)
+
+// Idempotency errors
+var (
+	ErrIdempotencyInProgress = errors.New("request with this idempotency key is in progress")
+	ErrIdempotencyKeyReused  = errors.New("idempotency key was used for a different request")
+)
+
+// IdempotentResponse is a stored response replayed for duplicate requests
+type IdempotentResponse struct {
+	Status      int    `json:"status"`
+	ContentType string `json:"content_type"`
+	Body        []byte `json:"body"`
+}
+
+// idempotencyRecord is the stored state of an idempotency key
+type idempotencyRecord struct {
+	Fingerprint string              `json:"fingerprint"`
+	Response    *IdempotentResponse `json:"response,omitempty"`
+}
+
+// BeginIdempotentRequest claims an idempotency key for a user's request. It
+// returns the stored response if the request already completed, or nil if
+// the caller should execute the request and then complete or abandon the key.
+func (sm *SecurityManager) BeginIdempotentRequest(ctx context.Context, userID, key, fingerprint string) (*IdempotentResponse, error) {
+	redisKey := idempotencyKey(userID, key)
+
+	pending, err := json.Marshal(idempotencyRecord{Fingerprint: fingerprint})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal idempotency record: %w", err)
+	}
+
+	claimed, err := sm.client.SetNX(ctx, redisKey, pending, idempotencyLockTTL).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim idempotency key: %w", err)
+	}
+	if claimed {
+		return nil, nil
+	}
+
+	data, err := sm.client.Get(ctx, redisKey).Bytes()
+	if err == redis.Nil {
+		// The previous request abandoned the key between our calls
+		return nil, ErrIdempotencyInProgress
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get idempotency record: %w", err)
+	}
+
+	var record idempotencyRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal idempotency record: %w", err)
+	}
+
+	if record.Fingerprint != fingerprint {
+		return nil, ErrIdempotencyKeyReused
+	}
+	if record.Response == nil {
+		return nil, ErrIdempotencyInProgress
+	}
+
+	return record.Response, nil
+}
+
+// CompleteIdempotentRequest stores the response of a request so duplicates replay it
+func (sm *SecurityManager) CompleteIdempotentRequest(ctx context.Context, userID, key, fingerprint string, response IdempotentResponse) error {
+	data, err := json.Marshal(idempotencyRecord{Fingerprint: fingerprint, Response: &response})
+	if err != nil {
+		return fmt.Errorf("failed to marshal idempotency record: %w", err)
+	}
+
+	if err := sm.client.Set(ctx, idempotencyKey(userID, key), data, idempotencyTTL).Err(); err != nil {
+		return fmt.Errorf("failed to store idempotent response: %w", err)
+	}
+
+	return nil
+}
+
+// AbandonIdempotentRequest releases an idempotency key so the request can be retried
+func (sm *SecurityManager) AbandonIdempotentRequest(ctx context.Context, userID, key string) error {
+	if err := sm.client.Del(ctx, idempotencyKey(userID, key)).Err(); err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+// idempotencyKey returns the Redis key for a user's idempotency key
+func idempotencyKey(userID, key string) string {
+	return idempotencyPrefix + userID + ":" + hashToken(key)
+}
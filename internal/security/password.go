@@ -0,0 +1,157 @@
+// internal/security/password.go
+package security
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// MinBcryptCost is the lowest password hashing cost the security manager accepts
+	MinBcryptCost = 10
+
+	// DefaultBcryptCost is the password hashing cost used unless configured otherwise
+	DefaultBcryptCost = 14
+
+	// DefaultMinPasswordLength is the minimum password length of the default policy
+	DefaultMinPasswordLength = 8
+)
+
+// Password policy errors
+var (
+	ErrPasswordTooShort      = errors.New("password is too short")
+	ErrPasswordMissingUpper  = errors.New("password must contain an uppercase letter")
+	ErrPasswordMissingLower  = errors.New("password must contain a lowercase letter")
+	ErrPasswordMissingDigit  = errors.New("password must contain a digit")
+	ErrPasswordMissingSymbol = errors.New("password must contain a symbol")
+	ErrPasswordBreached      = errors.New("password appears in a list of breached passwords")
+)
+
+// PasswordValidator checks that a password is strong enough to be hashed
+type PasswordValidator interface {
+	ValidatePassword(password string) error
+}
+
+// PasswordPolicy is a PasswordValidator enforcing a minimum length, required
+// character classes and, optionally, a list of breached passwords
+type PasswordPolicy struct {
+	MinLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+
+	// Breached lists passwords that are always rejected
+	Breached BreachedPasswords
+}
+
+// DefaultPasswordPolicy returns the policy used unless configured otherwise,
+// which only enforces DefaultMinPasswordLength
+func DefaultPasswordPolicy() *PasswordPolicy {
+	return &PasswordPolicy{MinLength: DefaultMinPasswordLength}
+}
+
+// ValidatePassword returns the first rule a password breaks, if any
+func (p *PasswordPolicy) ValidatePassword(password string) error {
+	if len([]rune(password)) < p.MinLength {
+		return fmt.Errorf("%w: must be at least %d characters long", ErrPasswordTooShort, p.MinLength)
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			hasSymbol = true
+		}
+	}
+
+	switch {
+	case p.RequireUpper && !hasUpper:
+		return ErrPasswordMissingUpper
+	case p.RequireLower && !hasLower:
+		return ErrPasswordMissingLower
+	case p.RequireDigit && !hasDigit:
+		return ErrPasswordMissingDigit
+	case p.RequireSymbol && !hasSymbol:
+		return ErrPasswordMissingSymbol
+	}
+
+	if p.Breached.Contains(password) {
+		return ErrPasswordBreached
+	}
+
+	return nil
+}
+
+// BreachedPasswords is a set of known breached passwords
+type BreachedPasswords map[string]struct{}
+
+// NewBreachedPasswords creates a breached password set from a list
+func NewBreachedPasswords(passwords []string) BreachedPasswords {
+	breached := make(BreachedPasswords, len(passwords))
+	for _, password := range passwords {
+		breached[password] = struct{}{}
+	}
+	return breached
+}
+
+// LoadBreachedPasswords reads a breached password set from a file with one
+// password per line. Blank lines are skipped.
+func LoadBreachedPasswords(path string) (BreachedPasswords, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open breached password list: %w", err)
+	}
+	defer file.Close()
+
+	breached := make(BreachedPasswords)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if password := strings.TrimRight(scanner.Text(), "\r"); password != "" {
+			breached[password] = struct{}{}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read breached password list: %w", err)
+	}
+
+	return breached, nil
+}
+
+// Contains reports whether a password is in the set
+func (b BreachedPasswords) Contains(password string) bool {
+	_, exists := b[password]
+	return exists
+}
+
+// SetBcryptCost sets the cost used to hash new passwords. Existing hashes
+// keep verifying whatever cost they were created with.
+func (sm *SecurityManager) SetBcryptCost(cost int) error {
+	if cost < MinBcryptCost || cost > bcrypt.MaxCost {
+		return fmt.Errorf("bcrypt cost must be between %d and %d", MinBcryptCost, bcrypt.MaxCost)
+	}
+
+	sm.bcryptCost = cost
+	return nil
+}
+
+// SetPasswordPolicy replaces the policy new passwords must satisfy. A nil
+// policy restores DefaultPasswordPolicy.
+func (sm *SecurityManager) SetPasswordPolicy(policy PasswordValidator) {
+	if policy == nil {
+		policy = DefaultPasswordPolicy()
+	}
+	sm.passwordPolicy = policy
+}
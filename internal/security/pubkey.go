@@ -0,0 +1,79 @@
+// internal/security/pubkey.go
+package security
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Public key prefix, shared with the transaction processor's signature checks
+const publicKeyPrefix = "pubkey:"
+
+// Public key errors
+var (
+	ErrInvalidPublicKey  = errors.New("invalid public key")
+	ErrPublicKeyNotFound = errors.New("public key not found")
+	ErrPublicKeyConflict = errors.New("a different public key is already registered for this address")
+	ErrSignatureMismatch = errors.New("signature does not match the registered public key")
+)
+
+// RegisterPublicKey stores the ed25519 public key that signs transactions for
+// an address. An address keeps its first key; registering the same key again
+// is a no-op. Address derivation belongs to the wallet package, so callers
+// must check that the key belongs to the address.
+func (sm *SecurityManager) RegisterPublicKey(ctx context.Context, address string, pubKey []byte) error {
+	if address == "" || len(pubKey) != ed25519.PublicKeySize {
+		return ErrInvalidPublicKey
+	}
+
+	stored, err := sm.client.SetNX(ctx, publicKeyPrefix+address, pubKey, 0).Result()
+	if err != nil {
+		return fmt.Errorf("failed to store public key: %w", err)
+	}
+	if stored {
+		return nil
+	}
+
+	existing, err := sm.GetPublicKey(ctx, address)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(existing, pubKey) {
+		return ErrPublicKeyConflict
+	}
+
+	return nil
+}
+
+// GetPublicKey returns the public key registered for an address
+func (sm *SecurityManager) GetPublicKey(ctx context.Context, address string) (ed25519.PublicKey, error) {
+	data, err := sm.client.Get(ctx, publicKeyPrefix+address).Bytes()
+	if err == redis.Nil {
+		return nil, ErrPublicKeyNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get public key: %w", err)
+	}
+
+	return ed25519.PublicKey(data), nil
+}
+
+// VerifySignature checks a signature over data against the public key
+// registered for an address
+func (sm *SecurityManager) VerifySignature(ctx context.Context, address string, data, signature []byte) error {
+	pubKey, err := sm.GetPublicKey(ctx, address)
+	if err != nil {
+		return err
+	}
+
+	if len(pubKey) != ed25519.PublicKeySize || !ed25519.Verify(pubKey, data, signature) {
+		return ErrSignatureMismatch
+	}
+
+	return nil
+}
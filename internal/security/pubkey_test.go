@@ -0,0 +1,42 @@
+package security
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"testing"
+)
+
+func newTestKey(t *testing.T) ed25519.PublicKey {
+	t.Helper()
+
+	pubKey, _, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	return pubKey
+}
+
+func TestRegisterPublicKeyRejectsBadKeys(t *testing.T) {
+	key := newTestKey(t)
+	sm := &SecurityManager{}
+
+	tests := []struct {
+		name    string
+		address string
+		pubKey  []byte
+		want    error
+	}{
+		{name: "empty address", address: "", pubKey: key, want: ErrInvalidPublicKey},
+		{name: "short key", address: "wallet", pubKey: key[:31], want: ErrInvalidPublicKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := sm.RegisterPublicKey(context.Background(), tt.address, tt.pubKey)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("RegisterPublicKey error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package security
+
+import (
+	"testing"
+
+	"github.com/cmatc13/stathera/internal/security/redistest"
+)
+
+// newTestSecurityManager creates a security manager backed by an in-memory
+// Redis server
+func newTestSecurityManager(t *testing.T) (*SecurityManager, *redistest.Server) {
+	t.Helper()
+
+	fake := redistest.NewServer(t)
+	sm, err := NewSecurityManager(fake.Addr(), "test-secret")
+	if err != nil {
+		t.Fatalf("NewSecurityManager: %v", err)
+	}
+	t.Cleanup(func() { sm.Close() })
+
+	return sm, fake
+}
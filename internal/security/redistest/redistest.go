@@ -0,0 +1,446 @@
+// Package redistest provides an in-memory Redis server for tests.
+package redistest
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Server is an in-memory Redis server speaking enough of the protocol for
+// the security manager's commands, including WATCH/MULTI/EXEC transactions
+type Server struct {
+	mu       sync.Mutex
+	addr     string
+	data     map[string]interface{} // string, map[string]string or map[string]bool
+	expiry   map[string]time.Time
+	versions map[string]uint64 // bumped on every write, for WATCH
+}
+
+// fakeConn is the transaction state of one client connection
+type fakeConn struct {
+	watched map[string]uint64
+	queued  [][]string
+	inMulti bool
+}
+
+// NewServer starts a Server that is shut down when the test ends
+func NewServer(t testing.TB) *Server {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	f := &Server{
+		addr:     ln.Addr().String(),
+		data:     make(map[string]interface{}),
+		expiry:   make(map[string]time.Time),
+		versions: make(map[string]uint64),
+	}
+	go f.serve(ln)
+
+	return f
+}
+
+// Addr returns the address the server listens on
+func (f *Server) Addr() string {
+	return f.addr
+}
+
+// Expire removes a key as if its TTL had run out
+func (f *Server) Expire(key string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.del(key)
+}
+
+// TTL returns the remaining lifetime of a key, -1 if it has none and -2 if it
+// does not exist, like PTTL
+func (f *Server) TTL(key string) time.Duration {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.expireIfDue(key)
+	if _, ok := f.data[key]; !ok {
+		return -2
+	}
+	deadline, ok := f.expiry[key]
+	if !ok {
+		return -1
+	}
+	return time.Until(deadline)
+}
+
+// SetTTL changes the remaining lifetime of an existing key
+func (f *Server) SetTTL(key string, ttl time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.expiry[key] = time.Now().Add(ttl)
+}
+
+func (f *Server) serve(ln net.Listener) {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		go f.handle(conn)
+	}
+}
+
+func (f *Server) handle(conn net.Conn) {
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	state := &fakeConn{}
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+		if _, err := conn.Write(f.dispatch(state, args)); err != nil {
+			return
+		}
+	}
+}
+
+// readCommand reads one command sent as an array of bulk strings
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(line, "*") {
+		return nil, fmt.Errorf("unexpected command line %q", line)
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+	if err != nil {
+		return nil, err
+	}
+
+	args := make([]string, n)
+	for i := range args {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		if !strings.HasPrefix(line, "$") {
+			return nil, fmt.Errorf("unexpected argument line %q", line)
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		args[i] = string(buf[:size])
+	}
+	return args, nil
+}
+
+// dispatch handles transaction commands and queues or runs the rest
+func (f *Server) dispatch(c *fakeConn, args []string) []byte {
+	switch strings.ToUpper(args[0]) {
+	case "MULTI":
+		c.inMulti, c.queued = true, nil
+		return simpleReply("OK")
+	case "EXEC":
+		return f.exec(c)
+	case "DISCARD":
+		c.inMulti, c.queued, c.watched = false, nil, nil
+		return simpleReply("OK")
+	case "WATCH":
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if c.watched == nil {
+			c.watched = make(map[string]uint64)
+		}
+		for _, key := range args[1:] {
+			f.expireIfDue(key)
+			c.watched[key] = f.versions[key]
+		}
+		return simpleReply("OK")
+	case "UNWATCH":
+		c.watched = nil
+		return simpleReply("OK")
+	}
+
+	if c.inMulti {
+		c.queued = append(c.queued, args)
+		return simpleReply("QUEUED")
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.run(args)
+}
+
+// exec runs a queued transaction unless a watched key changed
+func (f *Server) exec(c *fakeConn) []byte {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	queued, watched := c.queued, c.watched
+	c.inMulti, c.queued, c.watched = false, nil, nil
+
+	for key, version := range watched {
+		f.expireIfDue(key)
+		if f.versions[key] != version {
+			return []byte("*-1\r\n")
+		}
+	}
+
+	reply := []byte(fmt.Sprintf("*%d\r\n", len(queued)))
+	for _, args := range queued {
+		reply = append(reply, f.run(args)...)
+	}
+	return reply
+}
+
+// run executes a single command; the caller holds f.mu
+func (f *Server) run(args []string) []byte {
+	cmd := strings.ToUpper(args[0])
+	for _, key := range args[1:] {
+		f.expireIfDue(key)
+	}
+
+	switch cmd {
+	case "PING":
+		return simpleReply("PONG")
+
+	case "GET":
+		value, ok := f.data[args[1]].(string)
+		if !ok {
+			return nilReply()
+		}
+		return bulkReply(value)
+
+	case "SET":
+		var ttl time.Duration
+		nx := false
+		for i := 3; i < len(args); i++ {
+			switch strings.ToUpper(args[i]) {
+			case "EX", "PX":
+				n, _ := strconv.ParseInt(args[i+1], 10, 64)
+				ttl = time.Duration(n) * time.Millisecond
+				if strings.ToUpper(args[i]) == "EX" {
+					ttl = time.Duration(n) * time.Second
+				}
+				i++
+			case "NX":
+				nx = true
+			}
+		}
+		if _, exists := f.data[args[1]]; nx && exists {
+			return nilReply()
+		}
+		f.data[args[1]] = args[2]
+		delete(f.expiry, args[1])
+		if ttl > 0 {
+			f.expiry[args[1]] = time.Now().Add(ttl)
+		}
+		f.touch(args[1])
+		return simpleReply("OK")
+
+	case "SETNX":
+		if _, exists := f.data[args[1]]; exists {
+			return intReply(0)
+		}
+		f.data[args[1]] = args[2]
+		f.touch(args[1])
+		return intReply(1)
+
+	case "DEL":
+		deleted := 0
+		for _, key := range args[1:] {
+			if _, ok := f.data[key]; ok {
+				f.del(key)
+				deleted++
+			}
+		}
+		return intReply(int64(deleted))
+
+	case "EXISTS":
+		count := 0
+		for _, key := range args[1:] {
+			if _, ok := f.data[key]; ok {
+				count++
+			}
+		}
+		return intReply(int64(count))
+
+	case "INCR":
+		value, exists := f.data[args[1]]
+		current, ok := value.(string)
+		if exists && !ok {
+			return errorReply("WRONGTYPE Operation against a key holding the wrong kind of value")
+		}
+		n := int64(0)
+		if current != "" {
+			parsed, err := strconv.ParseInt(current, 10, 64)
+			if err != nil {
+				return errorReply("ERR value is not an integer or out of range")
+			}
+			n = parsed
+		}
+		n++
+		f.data[args[1]] = strconv.FormatInt(n, 10)
+		f.touch(args[1])
+		return intReply(n)
+
+	case "EXPIRE", "PEXPIRE":
+		if _, ok := f.data[args[1]]; !ok {
+			return intReply(0)
+		}
+		n, _ := strconv.ParseInt(args[2], 10, 64)
+		ttl := time.Duration(n) * time.Millisecond
+		if cmd == "EXPIRE" {
+			ttl = time.Duration(n) * time.Second
+		}
+		f.expiry[args[1]] = time.Now().Add(ttl)
+		f.touch(args[1])
+		return intReply(1)
+
+	case "HSET":
+		hash, reply := f.hash(args[1], true)
+		if reply != nil {
+			return reply
+		}
+		added := 0
+		for i := 2; i+1 < len(args); i += 2 {
+			if _, ok := hash[args[i]]; !ok {
+				added++
+			}
+			hash[args[i]] = args[i+1]
+		}
+		f.touch(args[1])
+		return intReply(int64(added))
+
+	case "HGET":
+		hash, reply := f.hash(args[1], false)
+		if reply != nil {
+			return reply
+		}
+		value, ok := hash[args[2]]
+		if !ok {
+			return nilReply()
+		}
+		return bulkReply(value)
+
+	case "HGETALL":
+		hash, reply := f.hash(args[1], false)
+		if reply != nil {
+			return reply
+		}
+		fields := make([]string, 0, 2*len(hash))
+		for field, value := range hash {
+			fields = append(fields, field, value)
+		}
+		return arrayReply(fields)
+
+	case "HINCRBY":
+		hash, reply := f.hash(args[1], true)
+		if reply != nil {
+			return reply
+		}
+		current, _ := strconv.ParseInt(hash[args[2]], 10, 64)
+		by, _ := strconv.ParseInt(args[3], 10, 64)
+		hash[args[2]] = strconv.FormatInt(current+by, 10)
+		f.touch(args[1])
+		return intReply(current + by)
+
+	case "SADD":
+		set, ok := f.data[args[1]].(map[string]bool)
+		if !ok {
+			set = make(map[string]bool)
+			f.data[args[1]] = set
+		}
+		added := 0
+		for _, member := range args[2:] {
+			if !set[member] {
+				set[member] = true
+				added++
+			}
+		}
+		f.touch(args[1])
+		return intReply(int64(added))
+
+	case "SMEMBERS":
+		set, _ := f.data[args[1]].(map[string]bool)
+		members := make([]string, 0, len(set))
+		for member := range set {
+			members = append(members, member)
+		}
+		sort.Strings(members)
+		return arrayReply(members)
+	}
+
+	return errorReply("ERR unknown command '" + cmd + "'")
+}
+
+// hash returns the hash stored at key, optionally creating it. A non-nil
+// reply is an error or empty result to send instead.
+func (f *Server) hash(key string, create bool) (map[string]string, []byte) {
+	value, exists := f.data[key]
+	if !exists {
+		if !create {
+			return map[string]string{}, nil
+		}
+		hash := make(map[string]string)
+		f.data[key] = hash
+		return hash, nil
+	}
+	hash, ok := value.(map[string]string)
+	if !ok {
+		return nil, errorReply("WRONGTYPE Operation against a key holding the wrong kind of value")
+	}
+	return hash, nil
+}
+
+// expireIfDue deletes a key whose TTL has passed; the caller holds f.mu
+func (f *Server) expireIfDue(key string) {
+	if deadline, ok := f.expiry[key]; ok && !time.Now().Before(deadline) {
+		f.del(key)
+	}
+}
+
+// del removes a key; the caller holds f.mu
+func (f *Server) del(key string) {
+	delete(f.data, key)
+	delete(f.expiry, key)
+	f.touch(key)
+}
+
+// touch marks a key as modified for WATCH; the caller holds f.mu
+func (f *Server) touch(key string) {
+	f.versions[key]++
+}
+
+func simpleReply(s string) []byte { return []byte("+" + s + "\r\n") }
+func errorReply(s string) []byte  { return []byte("-" + s + "\r\n") }
+func intReply(n int64) []byte     { return []byte(":" + strconv.FormatInt(n, 10) + "\r\n") }
+func nilReply() []byte            { return []byte("$-1\r\n") }
+
+func bulkReply(s string) []byte {
+	return []byte("$" + strconv.Itoa(len(s)) + "\r\n" + s + "\r\n")
+}
+
+func arrayReply(items []string) []byte {
+	reply := []byte("*" + strconv.Itoa(len(items)) + "\r\n")
+	for _, item := range items {
+		reply = append(reply, bulkReply(item)...)
+	}
+	return reply
+}
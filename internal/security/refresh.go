@@ -0,0 +1,198 @@
+// internal/security/refresh.go
+package security
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+)
+
+const (
+	// Refresh token prefixes
+	refreshTokenPrefix  = "refresh:"
+	refreshFamilyPrefix = "refresh:family:"
+
+	// Default refresh token lifetime
+	defaultRefreshTokenDuration = 7 * 24 * time.Hour
+
+	// Attempts to consume a refresh token that is rotated concurrently
+	maxRotateAttempts = 3
+)
+
+// Refresh token errors
+var (
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReuse   = errors.New("refresh token reuse detected")
+)
+
+// RefreshSession identifies the login a refresh token belongs to
+type RefreshSession struct {
+	UserID    string
+	Username  string
+	SessionID string
+}
+
+// SetRefreshTokenDuration configures the lifetime of refresh tokens
+func (sm *SecurityManager) SetRefreshTokenDuration(d time.Duration) {
+	sm.refreshTokenDuration = d
+}
+
+// IssueRefreshToken creates a refresh token starting a new rotation chain
+func (sm *SecurityManager) IssueRefreshToken(ctx context.Context, session RefreshSession) (string, error) {
+	return sm.issueRefreshToken(ctx, session, uuid.New().String())
+}
+
+// RotateRefreshToken consumes a refresh token and returns its session with a
+// replacement token. Presenting an already-used token revokes the whole chain.
+func (sm *SecurityManager) RotateRefreshToken(ctx context.Context, token string) (RefreshSession, string, error) {
+	key := refreshTokenPrefix + hashToken(token)
+
+	var data map[string]string
+	var used int64
+	consume := func(tx *redis.Tx) error {
+		var err error
+		data, err = tx.HGetAll(ctx, key).Result()
+		if err != nil {
+			return err
+		}
+		if len(data) == 0 {
+			return ErrInvalidRefreshToken
+		}
+
+		// Mark the token used and keep it as long as its replacement, so a
+		// replay is detected for the replacement's whole lifetime. The
+		// transaction fails if the token changed or expired since it was read.
+		var usedCmd *redis.IntCmd
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			usedCmd = pipe.HIncrBy(ctx, key, "used", 1)
+			pipe.Expire(ctx, key, sm.refreshTTL())
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		used = usedCmd.Val()
+		return nil
+	}
+
+	// A concurrent use of the same token makes the transaction fail;
+	// retrying then sees the token as used, or gone if it was revoked
+	var err error
+	for attempt := 0; attempt < maxRotateAttempts; attempt++ {
+		if err = sm.client.Watch(ctx, consume, key); err != redis.TxFailedErr {
+			break
+		}
+	}
+	// Losing every attempt means the token kept being used concurrently
+	raced := err == redis.TxFailedErr
+	if errors.Is(err, ErrInvalidRefreshToken) {
+		return RefreshSession{}, "", ErrInvalidRefreshToken
+	}
+	if err != nil && !raced {
+		return RefreshSession{}, "", fmt.Errorf("failed to consume refresh token: %w", err)
+	}
+
+	family := data["family"]
+
+	// Only the first caller sees 1
+	if used > 1 || raced {
+		// A rotated token is being replayed; assume it was stolen
+		if err := sm.revokeRefreshFamily(ctx, family); err != nil {
+			return RefreshSession{}, "", err
+		}
+		return RefreshSession{}, "", ErrRefreshTokenReuse
+	}
+
+	session := RefreshSession{
+		UserID:    data["user_id"],
+		Username:  data["username"],
+		SessionID: data["session_id"],
+	}
+
+	newToken, err := sm.issueRefreshToken(ctx, session, family)
+	if err != nil {
+		return RefreshSession{}, "", err
+	}
+
+	return session, newToken, nil
+}
+
+// RevokeRefreshToken revokes a refresh token and every token in its chain
+func (sm *SecurityManager) RevokeRefreshToken(ctx context.Context, token string) error {
+	family, err := sm.client.HGet(ctx, refreshTokenPrefix+hashToken(token), "family").Result()
+	if err == redis.Nil {
+		return ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get refresh token: %w", err)
+	}
+
+	return sm.revokeRefreshFamily(ctx, family)
+}
+
+// issueRefreshToken creates a refresh token in the given chain
+func (sm *SecurityManager) issueRefreshToken(ctx context.Context, session RefreshSession, family string) (string, error) {
+	tokenBytes := make([]byte, 32)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return "", fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+
+	token := base64.URLEncoding.EncodeToString(tokenBytes)
+	tokenHash := hashToken(token)
+
+	ttl := sm.refreshTTL()
+
+	tokenData := map[string]interface{}{
+		"user_id":    session.UserID,
+		"username":   session.Username,
+		"session_id": session.SessionID,
+		"family":     family,
+		"used":       0,
+	}
+
+	// Used tokens are kept until expiry so replays can be detected
+	pipe := sm.client.TxPipeline()
+	pipe.HSet(ctx, refreshTokenPrefix+tokenHash, tokenData)
+	pipe.Expire(ctx, refreshTokenPrefix+tokenHash, ttl)
+	pipe.SAdd(ctx, refreshFamilyPrefix+family, tokenHash)
+	pipe.Expire(ctx, refreshFamilyPrefix+family, ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return "", fmt.Errorf("failed to store refresh token: %w", err)
+	}
+
+	return token, nil
+}
+
+// revokeRefreshFamily deletes every refresh token in a chain
+func (sm *SecurityManager) revokeRefreshFamily(ctx context.Context, family string) error {
+	hashes, err := sm.client.SMembers(ctx, refreshFamilyPrefix+family).Result()
+	if err != nil {
+		return fmt.Errorf("failed to get refresh token chain: %w", err)
+	}
+
+	keys := make([]string, 0, len(hashes)+1)
+	for _, hash := range hashes {
+		keys = append(keys, refreshTokenPrefix+hash)
+	}
+	keys = append(keys, refreshFamilyPrefix+family)
+
+	if err := sm.client.Del(ctx, keys...).Err(); err != nil {
+		return fmt.Errorf("failed to revoke refresh token chain: %w", err)
+	}
+
+	return nil
+}
+
+// refreshTTL returns the lifetime of refresh tokens
+func (sm *SecurityManager) refreshTTL() time.Duration {
+	if sm.refreshTokenDuration <= 0 {
+		return defaultRefreshTokenDuration
+	}
+	return sm.refreshTokenDuration
+}
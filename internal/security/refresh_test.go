@@ -0,0 +1,120 @@
+package security
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRotateRefreshToken(t *testing.T) {
+	sm, _ := newTestSecurityManager(t)
+	ctx := context.Background()
+	want := RefreshSession{UserID: "user-1", Username: "alice", SessionID: "session-1"}
+
+	token, err := sm.IssueRefreshToken(ctx, want)
+	if err != nil {
+		t.Fatalf("IssueRefreshToken: %v", err)
+	}
+
+	session, rotated, err := sm.RotateRefreshToken(ctx, token)
+	if err != nil {
+		t.Fatalf("RotateRefreshToken: %v", err)
+	}
+	if session != want {
+		t.Fatalf("session = %+v, want %+v", session, want)
+	}
+	if rotated == token {
+		t.Fatal("rotation returned the consumed token")
+	}
+
+	// Replaying the consumed token revokes the whole chain
+	if _, _, err := sm.RotateRefreshToken(ctx, token); !errors.Is(err, ErrRefreshTokenReuse) {
+		t.Fatalf("replay error = %v, want %v", err, ErrRefreshTokenReuse)
+	}
+	if _, _, err := sm.RotateRefreshToken(ctx, rotated); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Fatalf("rotated token after replay error = %v, want %v", err, ErrInvalidRefreshToken)
+	}
+}
+
+func TestRotateRefreshTokenKeepsTTL(t *testing.T) {
+	tests := []struct {
+		name        string
+		expire      bool
+		wantErr     error
+		wantTTLLeft bool
+	}{
+		{name: "expired token is not recreated", expire: true, wantErr: ErrInvalidRefreshToken},
+		{name: "used token lives as long as its replacement", wantTTLLeft: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sm, fake := newTestSecurityManager(t)
+			sm.SetRefreshTokenDuration(time.Hour)
+			ctx := context.Background()
+
+			token, err := sm.IssueRefreshToken(ctx, RefreshSession{UserID: "user-1"})
+			if err != nil {
+				t.Fatalf("IssueRefreshToken: %v", err)
+			}
+			key := refreshTokenPrefix + hashToken(token)
+			fake.SetTTL(key, time.Minute)
+			if tt.expire {
+				fake.Expire(key)
+			}
+
+			if _, _, err := sm.RotateRefreshToken(ctx, token); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("RotateRefreshToken error = %v, want %v", err, tt.wantErr)
+			}
+
+			ttl := fake.TTL(key)
+			if !tt.wantTTLLeft {
+				if ttl != -2 {
+					t.Fatalf("expired token key has TTL %v, want it absent", ttl)
+				}
+				return
+			}
+			if ttl <= time.Minute || ttl > time.Hour {
+				t.Fatalf("used token TTL = %v, want it reset to the refresh token lifetime", ttl)
+			}
+		})
+	}
+}
+
+func TestRotateRefreshTokenConcurrently(t *testing.T) {
+	sm, _ := newTestSecurityManager(t)
+	ctx := context.Background()
+
+	token, err := sm.IssueRefreshToken(ctx, RefreshSession{UserID: "user-1"})
+	if err != nil {
+		t.Fatalf("IssueRefreshToken: %v", err)
+	}
+
+	const callers = 10
+	var wg sync.WaitGroup
+	errs := make([]error, callers)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, _, errs[i] = sm.RotateRefreshToken(ctx, token)
+		}(i)
+	}
+	wg.Wait()
+
+	succeeded := 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			succeeded++
+		case errors.Is(err, ErrRefreshTokenReuse), errors.Is(err, ErrInvalidRefreshToken):
+		default:
+			t.Fatalf("RotateRefreshToken: %v", err)
+		}
+	}
+	if succeeded != 1 {
+		t.Fatalf("%d concurrent rotations succeeded, want exactly 1", succeeded)
+	}
+}
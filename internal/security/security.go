@@ -8,18 +8,18 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/go-redis/redis/v8"
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
+
+	"github.com/cmatc13/stathera/pkg/breaker"
 )
 
 const (
-	// Password hashing cost
-	bcryptCost = 14
-
 	// Rate limiting keys
 	rateLimitKeyPrefix = "ratelimit:"
 
@@ -28,27 +28,61 @@ const (
 	maxFailedLoginAttempts = 5
 	loginLockoutDuration   = 15 * time.Minute
 
-	// API key prefix
-	apiKeyPrefix = "apikey:"
+	// API key prefixes
+	apiKeyPrefix     = "apikey:"
+	userAPIKeyPrefix = "apikeys:user:"
+
+	// Default grace period before a rotated API key stops working
+	defaultAPIKeyRotationGrace = 24 * time.Hour
+
+	// Password reset token prefix
+	passwordResetPrefix     = "pwreset:"
+	passwordResetExpiration = 15 * time.Minute
 
 	// CSRF token prefix
 	csrfTokenPrefix     = "csrf:"
 	csrfTokenExpiration = 1 * time.Hour
 )
 
+// API key errors
+var (
+	ErrInvalidAPIKey = errors.New("invalid API key")
+	ErrAPIKeyExpired = errors.New("API key expired")
+	ErrAPIKeyRevoked = errors.New("API key revoked")
+)
+
+// ErrInvalidResetToken is returned when a password reset token is unknown, used or expired
+var ErrInvalidResetToken = errors.New("invalid or expired password reset token")
+
 // SecurityManager handles security-related functionality
 type SecurityManager struct {
 	client    *redis.Client
-	ctx       context.Context
 	jwtSecret []byte
+
+	// API key lifetime policy
+	apiKeyTTL           time.Duration
+	apiKeyRotationGrace time.Duration
+
+	// Refresh token lifetime
+	refreshTokenDuration time.Duration
+
+	// Password hashing cost and strength policy
+	bcryptCost     int
+	passwordPolicy PasswordValidator
 }
 
 // NewSecurityManager creates a new security manager
 func NewSecurityManager(redisAddr string, jwtSecret string) (*SecurityManager, error) {
-	client := redis.NewClient(&redis.Options{
+	return NewSecurityManagerWithOptions(&redis.Options{
 		Addr: redisAddr,
 		DB:   0,
-	})
+	}, jwtSecret)
+}
+
+// NewSecurityManagerWithOptions creates a new security manager connecting to
+// Redis with the given client options
+func NewSecurityManagerWithOptions(opts *redis.Options, jwtSecret string) (*SecurityManager, error) {
+	client := redis.NewClient(opts)
 
 	ctx := context.Background()
 
@@ -58,24 +92,41 @@ func NewSecurityManager(redisAddr string, jwtSecret string) (*SecurityManager, e
 	}
 
 	return &SecurityManager{
-		client:    client,
-		ctx:       ctx,
-		jwtSecret: []byte(jwtSecret),
+		client:               client,
+		jwtSecret:            []byte(jwtSecret),
+		apiKeyRotationGrace:  defaultAPIKeyRotationGrace,
+		refreshTokenDuration: defaultRefreshTokenDuration,
+		bcryptCost:           DefaultBcryptCost,
+		passwordPolicy:       DefaultPasswordPolicy(),
 	}, nil
 }
 
+// SetAPIKeyPolicy configures the lifetime of new API keys (zero means never
+// expire) and how long a rotated key keeps working
+func (sm *SecurityManager) SetAPIKeyPolicy(ttl, rotationGrace time.Duration) {
+	sm.apiKeyTTL = ttl
+	sm.apiKeyRotationGrace = rotationGrace
+}
+
+// SetCircuitBreaker guards the Redis client with a circuit breaker so that
+// calls fail fast with breaker.ErrUnavailable while Redis is down
+func (sm *SecurityManager) SetCircuitBreaker(b *breaker.Breaker) {
+	sm.client.AddHook(breaker.NewRedisHook(b))
+}
+
 // Close closes the Redis connection
 func (sm *SecurityManager) Close() error {
 	return sm.client.Close()
 }
 
-// HashPassword securely hashes a password using bcrypt
+// HashPassword securely hashes a password using bcrypt. Passwords that break
+// the password policy are rejected with the policy's error.
 func (sm *SecurityManager) HashPassword(password string) (string, error) {
-	if len(password) < 8 {
-		return "", errors.New("password must be at least 8 characters long")
+	if err := sm.passwordPolicy.ValidatePassword(password); err != nil {
+		return "", err
 	}
 
-	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcryptCost)
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), sm.bcryptCost)
 	if err != nil {
 		return "", fmt.Errorf("failed to hash password: %w", err)
 	}
@@ -89,8 +140,50 @@ func (sm *SecurityManager) VerifyPassword(hash, password string) bool {
 	return err == nil
 }
 
+// GeneratePasswordResetToken creates a single-use password reset token for a user
+func (sm *SecurityManager) GeneratePasswordResetToken(ctx context.Context, userID string) (string, error) {
+	tokenBytes := make([]byte, 32)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return "", fmt.Errorf("failed to generate reset token: %w", err)
+	}
+
+	token := base64.URLEncoding.EncodeToString(tokenBytes)
+
+	// Store only the token hash
+	err := sm.client.Set(ctx, passwordResetPrefix+hashToken(token), userID, passwordResetExpiration).Err()
+	if err != nil {
+		return "", fmt.Errorf("failed to store reset token: %w", err)
+	}
+
+	return token, nil
+}
+
+// ConsumePasswordResetToken validates a password reset token, deletes it and
+// returns the user it was issued for
+func (sm *SecurityManager) ConsumePasswordResetToken(ctx context.Context, token string) (string, error) {
+	key := passwordResetPrefix + hashToken(token)
+
+	// Read and delete atomically so the token can only be used once
+	pipe := sm.client.TxPipeline()
+	getCmd := pipe.Get(ctx, key)
+	pipe.Del(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return "", fmt.Errorf("failed to consume reset token: %w", err)
+	}
+
+	userID, err := getCmd.Result()
+	if err == redis.Nil || userID == "" {
+		return "", ErrInvalidResetToken
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to consume reset token: %w", err)
+	}
+
+	return userID, nil
+}
+
 // CreateAPIKey generates a new API key for a user
-func (sm *SecurityManager) CreateAPIKey(userID string, permissions []string) (string, error) {
+func (sm *SecurityManager) CreateAPIKey(ctx context.Context, userID string, permissions []string) (string, error) {
 	// Generate random API key
 	keyBytes := make([]byte, 32)
 	_, err := rand.Read(keyBytes)
@@ -100,37 +193,90 @@ func (sm *SecurityManager) CreateAPIKey(userID string, permissions []string) (st
 
 	apiKey := base64.URLEncoding.EncodeToString(keyBytes)
 
+	// Compute expiration (zero means never)
+	now := time.Now()
+	var expiresAt int64
+	if sm.apiKeyTTL > 0 {
+		expiresAt = now.Add(sm.apiKeyTTL).Unix()
+	}
+
 	// Store API key with user info
 	keyData := map[string]interface{}{
 		"user_id":     userID,
 		"permissions": strings.Join(permissions, ","),
-		"created_at":  time.Now().Unix(),
+		"created_at":  now.Unix(),
+		"expires_at":  expiresAt,
 	}
 
 	// Hash the API key for storage to prevent key leakage from Redis
-	keyHash := sha256.Sum256([]byte(apiKey))
-	keyHashStr := base64.StdEncoding.EncodeToString(keyHash[:])
+	keyHash := hashAPIKey(apiKey)
 
-	err = sm.client.HSet(sm.ctx, apiKeyPrefix+keyHashStr, keyData).Err()
-	if err != nil {
+	// Store the key and index it under the user
+	pipe := sm.client.TxPipeline()
+	pipe.HSet(ctx, apiKeyPrefix+keyHash, keyData)
+	pipe.SAdd(ctx, userAPIKeyPrefix+userID, keyHash)
+	if _, err := pipe.Exec(ctx); err != nil {
 		return "", fmt.Errorf("failed to store API key: %w", err)
 	}
 
 	return apiKey, nil
 }
 
-// ValidateAPIKey validates an API key and returns the associated user ID and permissions
-func (sm *SecurityManager) ValidateAPIKey(apiKey string) (string, []string, error) {
-	// Hash the API key
-	keyHash := sha256.Sum256([]byte(apiKey))
-	keyHashStr := base64.StdEncoding.EncodeToString(keyHash[:])
+// RotateAPIKey creates a replacement for an API key with the same user and
+// permissions. The old key keeps working for the rotation grace period.
+func (sm *SecurityManager) RotateAPIKey(ctx context.Context, oldKey string) (string, error) {
+	userID, permissions, err := sm.ValidateAPIKey(ctx, oldKey)
+	if err != nil {
+		return "", err
+	}
+
+	newKey, err := sm.CreateAPIKey(ctx, userID, permissions)
+	if err != nil {
+		return "", err
+	}
+
+	// Shorten the old key's lifetime to the grace period
+	oldHashKey := apiKeyPrefix + hashAPIKey(oldKey)
+	graceExpiry := time.Now().Add(sm.apiKeyRotationGrace).Unix()
+
+	currentExpiry, err := sm.client.HGet(ctx, oldHashKey, "expires_at").Int64()
+	if err != nil && err != redis.Nil {
+		return "", fmt.Errorf("failed to read API key expiration: %w", err)
+	}
 
+	if currentExpiry == 0 || graceExpiry < currentExpiry {
+		if err := sm.client.HSet(ctx, oldHashKey, "expires_at", graceExpiry).Err(); err != nil {
+			return "", fmt.Errorf("failed to expire rotated API key: %w", err)
+		}
+	}
+
+	return newKey, nil
+}
+
+// ValidateAPIKey validates an API key and returns the associated user ID and permissions
+func (sm *SecurityManager) ValidateAPIKey(ctx context.Context, apiKey string) (string, []string, error) {
 	// Get key data
-	keyData, err := sm.client.HGetAll(sm.ctx, apiKeyPrefix+keyHashStr).Result()
+	hashKey := apiKeyPrefix + hashAPIKey(apiKey)
+	keyData, err := sm.client.HGetAll(ctx, hashKey).Result()
 	if err != nil || len(keyData) == 0 {
-		return "", nil, errors.New("invalid API key")
+		return "", nil, ErrInvalidAPIKey
+	}
+
+	// Check revocation
+	if keyData["revoked"] == "1" {
+		return "", nil, ErrAPIKeyRevoked
 	}
 
+	// Check expiration
+	if expiresAt, err := strconv.ParseInt(keyData["expires_at"], 10, 64); err == nil && expiresAt > 0 {
+		if time.Now().Unix() >= expiresAt {
+			return "", nil, ErrAPIKeyExpired
+		}
+	}
+
+	// Record usage; failure to do so should not reject a valid key
+	sm.client.HSet(ctx, hashKey, "last_used", time.Now().Unix())
+
 	userID := keyData["user_id"]
 	permissionsStr := keyData["permissions"]
 	permissions := strings.Split(permissionsStr, ",")
@@ -138,12 +284,23 @@ func (sm *SecurityManager) ValidateAPIKey(apiKey string) (string, []string, erro
 	return userID, permissions, nil
 }
 
+// hashAPIKey returns the storage hash of an API key
+func hashAPIKey(apiKey string) string {
+	return hashToken(apiKey)
+}
+
+// hashToken returns the storage hash of a secret token
+func hashToken(token string) string {
+	tokenHash := sha256.Sum256([]byte(token))
+	return base64.StdEncoding.EncodeToString(tokenHash[:])
+}
+
 // GenerateCSRFToken generates a new CSRF token for a session
-func (sm *SecurityManager) GenerateCSRFToken(sessionID string) (string, error) {
+func (sm *SecurityManager) GenerateCSRFToken(ctx context.Context, sessionID string) (string, error) {
 	token := uuid.New().String()
 
 	// Store token in Redis with expiration
-	err := sm.client.Set(sm.ctx, csrfTokenPrefix+sessionID, token, csrfTokenExpiration).Err()
+	err := sm.client.Set(ctx, csrfTokenPrefix+sessionID, token, csrfTokenExpiration).Err()
 	if err != nil {
 		return "", fmt.Errorf("failed to store CSRF token: %w", err)
 	}
@@ -152,8 +309,8 @@ func (sm *SecurityManager) GenerateCSRFToken(sessionID string) (string, error) {
 }
 
 // ValidateCSRFToken validates a CSRF token for a session
-func (sm *SecurityManager) ValidateCSRFToken(sessionID, token string) bool {
-	storedToken, err := sm.client.Get(sm.ctx, csrfTokenPrefix+sessionID).Result()
+func (sm *SecurityManager) ValidateCSRFToken(ctx context.Context, sessionID, token string) bool {
+	storedToken, err := sm.client.Get(ctx, csrfTokenPrefix+sessionID).Result()
 	if err != nil || storedToken != token {
 		return false
 	}
@@ -161,47 +318,77 @@ func (sm *SecurityManager) ValidateCSRFToken(sessionID, token string) bool {
 	return true
 }
 
-// CheckRateLimit checks if a rate limit has been exceeded
-// Returns true if the request should be allowed, false if rate limited
-func (sm *SecurityManager) CheckRateLimit(key string, limit int, period time.Duration) (bool, error) {
+// RateLimitStatus describes the state of a rate limit window after a request
+type RateLimitStatus struct {
+	Allowed bool
+	Count   int64
+	Limit   int
+	ResetIn time.Duration
+}
+
+// Remaining returns the number of requests left in the current window
+func (s RateLimitStatus) Remaining() int {
+	remaining := int64(s.Limit) - s.Count
+	if remaining < 0 {
+		return 0
+	}
+	return int(remaining)
+}
+
+// CheckRateLimit counts a request against a rate limit and reports whether it
+// should be allowed, along with the usage of the current window
+func (sm *SecurityManager) CheckRateLimit(ctx context.Context, key string, limit int, period time.Duration) (RateLimitStatus, error) {
 	// Use Redis pipeline for atomic operations
 	pipe := sm.client.Pipeline()
 
 	// Increment counter
-	countResult := pipe.Incr(sm.ctx, rateLimitKeyPrefix+key)
+	countResult := pipe.Incr(ctx, rateLimitKeyPrefix+key)
 
-	// Set expiration if not already set
-	pipe.Expire(sm.ctx, rateLimitKeyPrefix+key, period)
+	// Get the time left in the window
+	ttlResult := pipe.TTL(ctx, rateLimitKeyPrefix+key)
 
 	// Execute pipeline
-	_, err := pipe.Exec(sm.ctx)
+	_, err := pipe.Exec(ctx)
 	if err != nil {
-		return false, fmt.Errorf("failed to check rate limit: %w", err)
+		return RateLimitStatus{}, fmt.Errorf("failed to check rate limit: %w", err)
 	}
 
 	// Get counter value
 	count, err := countResult.Result()
 	if err != nil {
-		return false, fmt.Errorf("failed to get rate limit counter: %w", err)
+		return RateLimitStatus{}, fmt.Errorf("failed to get rate limit counter: %w", err)
 	}
 
-	// Check if limit exceeded
-	return count <= int64(limit), nil
+	// Start the window on the first request so the reset time is fixed
+	ttl := ttlResult.Val()
+	if ttl < 0 {
+		if err := sm.client.Expire(ctx, rateLimitKeyPrefix+key, period).Err(); err != nil {
+			return RateLimitStatus{}, fmt.Errorf("failed to set expiration for rate limit counter: %w", err)
+		}
+		ttl = period
+	}
+
+	return RateLimitStatus{
+		Allowed: count <= int64(limit),
+		Count:   count,
+		Limit:   limit,
+		ResetIn: ttl,
+	}, nil
 }
 
 // RecordFailedLogin records a failed login attempt for a user
-func (sm *SecurityManager) RecordFailedLogin(userID string) error {
+func (sm *SecurityManager) RecordFailedLogin(ctx context.Context, userID string) error {
 	key := failedLoginKeyPrefix + userID
 
 	// Increment failed login counter
-	count, err := sm.client.Incr(sm.ctx, key).Result()
+	count, err := sm.client.Incr(ctx, key).Result()
 	if err != nil {
 		return fmt.Errorf("failed to record failed login: %w", err)
 	}
 
 	// Set expiration if not already set
 	if count == 1 {
-		err = sm.client.Expire(sm.ctx, key, loginLockoutDuration).Err()
+		err = sm.client.Expire(ctx, key, loginLockoutDuration).Err()
 		if err != nil {
 			return fmt.Errorf("failed to set expiration for failed login counter: %w", err)
 		}
@@ -211,11 +398,11 @@ func (sm *SecurityManager) RecordFailedLogin(userID string) error {
 }
 
 // CheckLoginAllowed checks if a user is allowed to login (not locked out)
-func (sm *SecurityManager) CheckLoginAllowed(userID string) (bool, error) {
+func (sm *SecurityManager) CheckLoginAllowed(ctx context.Context, userID string) (bool, error) {
 	key := failedLoginKeyPrefix + userID
 
 	// Get failed login count
-	count, err := sm.client.Get(sm.ctx, key).Int64()
+	count, err := sm.client.Get(ctx, key).Int64()
 	if err == redis.Nil {
 		// No failed logins
 		return true, nil
@@ -229,10 +416,10 @@ func (sm *SecurityManager) CheckLoginAllowed(userID string) (bool, error) {
 }
 
 // ResetFailedLogins resets the failed login counter for a user
-func (sm *SecurityManager) ResetFailedLogins(userID string) error {
+func (sm *SecurityManager) ResetFailedLogins(ctx context.Context, userID string) error {
 	key := failedLoginKeyPrefix + userID
 
-	err := sm.client.Del(sm.ctx, key).Err()
+	err := sm.client.Del(ctx, key).Err()
 	if err != nil {
 		return fmt.Errorf("failed to reset failed logins: %w", err)
 	}
@@ -0,0 +1,54 @@
+// internal/security/session.go
+package security
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// Session prefix
+	sessionPrefix = "session:"
+
+	// SessionExpiration is how long a session stays valid after login
+	SessionExpiration = 24 * time.Hour
+)
+
+// CreateSession creates a new server-side session for a user
+func (sm *SecurityManager) CreateSession(ctx context.Context, userID string) (string, error) {
+	sessionID := uuid.New().String()
+
+	err := sm.client.Set(ctx, sessionPrefix+sessionID, userID, SessionExpiration).Err()
+	if err != nil {
+		return "", fmt.Errorf("failed to store session: %w", err)
+	}
+
+	return sessionID, nil
+}
+
+// InvalidateSession revokes a session and its CSRF token
+func (sm *SecurityManager) InvalidateSession(ctx context.Context, sessionID string) error {
+	err := sm.client.Del(ctx, sessionPrefix+sessionID, csrfTokenPrefix+sessionID).Err()
+	if err != nil {
+		return fmt.Errorf("failed to invalidate session: %w", err)
+	}
+
+	return nil
+}
+
+// IsSessionValid checks if a session exists and has not expired or been invalidated
+func (sm *SecurityManager) IsSessionValid(ctx context.Context, sessionID string) bool {
+	if sessionID == "" {
+		return false
+	}
+
+	count, err := sm.client.Exists(ctx, sessionPrefix+sessionID).Result()
+	if err != nil {
+		return false
+	}
+
+	return count > 0
+}
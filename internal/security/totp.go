@@ -0,0 +1,232 @@
+// internal/security/totp.go
+package security
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+)
+
+const (
+	// TOTP parameters (RFC 6238)
+	totpIssuer     = "Stathera"
+	totpDigits     = 6
+	totpStep       = 30 * time.Second
+	totpWindow     = 1
+	totpSecretSize = 20
+
+	// TOTP key prefixes
+	totpSecretPrefix  = "totp:secret:"
+	totpPendingPrefix = "totp:pending:"
+	totpUsedPrefix    = "totp:used:"
+
+	// How long an enrollment can be confirmed
+	totpEnrollmentExpiration = 10 * time.Minute
+
+	// MFA challenge prefix
+	mfaChallengePrefix     = "mfa:challenge:"
+	mfaChallengeExpiration = 5 * time.Minute
+)
+
+// TOTP errors
+var (
+	ErrInvalidMFAChallenge = errors.New("invalid or expired MFA challenge")
+	ErrNoPendingTOTP       = errors.New("no pending TOTP enrollment")
+)
+
+// EnrollTOTP generates a new TOTP secret for a user and returns it together
+// with an otpauth:// URL for authenticator apps. The secret stays pending, and
+// any enrolled secret stays in use, until ConfirmTOTP verifies a code for it.
+func (sm *SecurityManager) EnrollTOTP(ctx context.Context, userID string) (string, string, error) {
+	// Generate random secret
+	secretBytes := make([]byte, totpSecretSize)
+	if _, err := rand.Read(secretBytes); err != nil {
+		return "", "", fmt.Errorf("failed to generate TOTP secret: %w", err)
+	}
+
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(secretBytes)
+
+	// Store secret until it is confirmed
+	if err := sm.client.Set(ctx, totpPendingPrefix+userID, secret, totpEnrollmentExpiration).Err(); err != nil {
+		return "", "", fmt.Errorf("failed to store TOTP secret: %w", err)
+	}
+
+	// Build otpauth URL
+	params := url.Values{}
+	params.Set("secret", secret)
+	params.Set("issuer", totpIssuer)
+	params.Set("digits", strconv.Itoa(totpDigits))
+	params.Set("period", strconv.Itoa(int(totpStep.Seconds())))
+
+	otpauthURL := fmt.Sprintf("otpauth://totp/%s:%s?%s",
+		url.PathEscape(totpIssuer), url.PathEscape(userID), params.Encode())
+
+	return secret, otpauthURL, nil
+}
+
+// HasTOTP reports whether a user has TOTP enrolled
+func (sm *SecurityManager) HasTOTP(ctx context.Context, userID string) (bool, error) {
+	count, err := sm.client.Exists(ctx, totpSecretPrefix+userID).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to check TOTP enrollment: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+// VerifyTOTP validates a TOTP code for a user's enrolled secret, accepting
+// codes from the adjacent time steps. Each code can only be used once.
+func (sm *SecurityManager) VerifyTOTP(ctx context.Context, userID, code string) (bool, error) {
+	secret, err := sm.client.Get(ctx, totpSecretPrefix+userID).Result()
+	if err == redis.Nil {
+		return false, errors.New("TOTP not enrolled")
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get TOTP secret: %w", err)
+	}
+
+	return sm.checkTOTPCode(ctx, userID, secret, code)
+}
+
+// ConfirmTOTP enables a pending TOTP enrollment if code is valid for its
+// secret, replacing any secret enrolled before. It returns false for a wrong
+// code, leaving the enrollment pending.
+func (sm *SecurityManager) ConfirmTOTP(ctx context.Context, userID, code string) (bool, error) {
+	pendingKey := totpPendingPrefix + userID
+
+	valid := false
+	confirm := func(tx *redis.Tx) error {
+		secret, err := tx.Get(ctx, pendingKey).Result()
+		if err == redis.Nil {
+			return ErrNoPendingTOTP
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get pending TOTP secret: %w", err)
+		}
+
+		if valid, err = sm.checkTOTPCode(ctx, userID, secret, code); err != nil || !valid {
+			return err
+		}
+
+		// Fails if the user enrolled again since the secret was read
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, totpSecretPrefix+userID, secret, 0)
+			pipe.Del(ctx, pendingKey)
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to enable TOTP: %w", err)
+		}
+		return nil
+	}
+
+	if err := sm.client.Watch(ctx, confirm, pendingKey); err != nil {
+		return false, err
+	}
+
+	return valid, nil
+}
+
+// checkTOTPCode validates a code against a secret, accepting codes from the
+// adjacent time steps. Each code can only be used once.
+func (sm *SecurityManager) checkTOTPCode(ctx context.Context, userID, secret, code string) (bool, error) {
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	if err != nil {
+		return false, fmt.Errorf("invalid TOTP secret: %w", err)
+	}
+
+	if len(code) != totpDigits {
+		return false, nil
+	}
+
+	// Check current step and the adjacent windows
+	step := time.Now().Unix() / int64(totpStep.Seconds())
+	for offset := -totpWindow; offset <= totpWindow; offset++ {
+		counter := step + int64(offset)
+		if !hmac.Equal([]byte(generateTOTP(key, counter)), []byte(code)) {
+			continue
+		}
+
+		// Reject replays of a code within its validity window
+		usedKey := totpUsedPrefix + userID + ":" + strconv.FormatInt(counter, 10)
+		ttl := totpStep * time.Duration(2*totpWindow+1)
+		fresh, err := sm.client.SetNX(ctx, usedKey, 1, ttl).Result()
+		if err != nil {
+			return false, fmt.Errorf("failed to record TOTP use: %w", err)
+		}
+
+		return fresh, nil
+	}
+
+	return false, nil
+}
+
+// generateTOTP computes the HOTP value for a counter (RFC 4226)
+func generateTOTP(key []byte, counter int64) string {
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, uint64(counter))
+
+	h := hmac.New(sha1.New, key)
+	h.Write(msg)
+	sum := h.Sum(nil)
+
+	// Dynamic truncation
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < totpDigits; i++ {
+		mod *= 10
+	}
+
+	return fmt.Sprintf("%0*d", totpDigits, value%mod)
+}
+
+// CreateMFAChallenge stores a pending second-factor login and returns its token
+func (sm *SecurityManager) CreateMFAChallenge(ctx context.Context, userID, username string) (string, error) {
+	token := uuid.New().String()
+
+	challenge := map[string]interface{}{
+		"user_id":  userID,
+		"username": username,
+	}
+
+	pipe := sm.client.TxPipeline()
+	pipe.HSet(ctx, mfaChallengePrefix+token, challenge)
+	pipe.Expire(ctx, mfaChallengePrefix+token, mfaChallengeExpiration)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return "", fmt.Errorf("failed to store MFA challenge: %w", err)
+	}
+
+	return token, nil
+}
+
+// ConsumeMFAChallenge returns the user of a pending MFA challenge and deletes it
+func (sm *SecurityManager) ConsumeMFAChallenge(ctx context.Context, token string) (string, string, error) {
+	key := mfaChallengePrefix + token
+
+	pipe := sm.client.TxPipeline()
+	getCmd := pipe.HGetAll(ctx, key)
+	pipe.Del(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return "", "", fmt.Errorf("failed to get MFA challenge: %w", err)
+	}
+
+	challenge := getCmd.Val()
+	if len(challenge) == 0 {
+		return "", "", ErrInvalidMFAChallenge
+	}
+
+	return challenge["user_id"], challenge["username"], nil
+}
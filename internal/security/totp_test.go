@@ -0,0 +1,102 @@
+package security
+
+import (
+	"context"
+	"encoding/base32"
+	"errors"
+	"testing"
+	"time"
+)
+
+// currentTOTP returns the code for a secret at the current time step
+func currentTOTP(t *testing.T, secret string) string {
+	t.Helper()
+
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	if err != nil {
+		t.Fatalf("decode secret: %v", err)
+	}
+	return generateTOTP(key, time.Now().Unix()/int64(totpStep.Seconds()))
+}
+
+// wrongTOTP returns a code differing from code in its first digit
+func wrongTOTP(code string) string {
+	return string('0'+(code[0]-'0'+1)%10) + code[1:]
+}
+
+func TestEnrollTOTPRequiresConfirmation(t *testing.T) {
+	sm, fake := newTestSecurityManager(t)
+	ctx := context.Background()
+
+	secret, _, err := sm.EnrollTOTP(ctx, "user-1")
+	if err != nil {
+		t.Fatalf("EnrollTOTP: %v", err)
+	}
+	if enabled, err := sm.HasTOTP(ctx, "user-1"); err != nil || enabled {
+		t.Fatalf("HasTOTP before confirmation = %v, %v; want false", enabled, err)
+	}
+	if ttl := fake.TTL(totpPendingPrefix + "user-1"); ttl <= 0 || ttl > totpEnrollmentExpiration {
+		t.Fatalf("pending secret TTL = %v, want at most %v", ttl, totpEnrollmentExpiration)
+	}
+
+	code := currentTOTP(t, secret)
+	if valid, err := sm.ConfirmTOTP(ctx, "user-1", wrongTOTP(code)); err != nil || valid {
+		t.Fatalf("ConfirmTOTP with a wrong code = %v, %v; want false", valid, err)
+	}
+	if valid, err := sm.ConfirmTOTP(ctx, "user-1", code); err != nil || !valid {
+		t.Fatalf("ConfirmTOTP = %v, %v; want true", valid, err)
+	}
+	if enabled, err := sm.HasTOTP(ctx, "user-1"); err != nil || !enabled {
+		t.Fatalf("HasTOTP after confirmation = %v, %v; want true", enabled, err)
+	}
+
+	// The confirmation consumed both the code and the pending enrollment
+	if valid, err := sm.VerifyTOTP(ctx, "user-1", code); err != nil || valid {
+		t.Fatalf("VerifyTOTP with the confirmation code = %v, %v; want false", valid, err)
+	}
+	if _, err := sm.ConfirmTOTP(ctx, "user-1", code); !errors.Is(err, ErrNoPendingTOTP) {
+		t.Fatalf("second ConfirmTOTP error = %v, want %v", err, ErrNoPendingTOTP)
+	}
+}
+
+func TestReenrollKeepsSecretUntilConfirmed(t *testing.T) {
+	const enrolled = "JBSWY3DPEHPK3PXP"
+
+	tests := []struct {
+		name    string
+		confirm bool
+	}{
+		{name: "unconfirmed"},
+		{name: "confirmed", confirm: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sm, _ := newTestSecurityManager(t)
+			ctx := context.Background()
+			if err := sm.client.Set(ctx, totpSecretPrefix+"user-1", enrolled, 0).Err(); err != nil {
+				t.Fatalf("seed enrolled secret: %v", err)
+			}
+
+			pending, _, err := sm.EnrollTOTP(ctx, "user-1")
+			if err != nil {
+				t.Fatalf("EnrollTOTP: %v", err)
+			}
+			want := enrolled
+			if tt.confirm {
+				if valid, err := sm.ConfirmTOTP(ctx, "user-1", currentTOTP(t, pending)); err != nil || !valid {
+					t.Fatalf("ConfirmTOTP = %v, %v; want true", valid, err)
+				}
+				want = pending
+			}
+
+			got, err := sm.client.Get(ctx, totpSecretPrefix+"user-1").Result()
+			if err != nil {
+				t.Fatalf("get enrolled secret: %v", err)
+			}
+			if got != want {
+				t.Fatalf("enrolled secret = %q, want %q", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+// internal/security/users.go
+package security
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+)
+
+// User account prefix
+const userPrefix = "account:"
+
+// User account errors
+var (
+	ErrUserExists         = errors.New("username is already taken")
+	ErrInvalidCredentials = errors.New("invalid username or password")
+)
+
+// userRecord is the stored state of a user account
+type userRecord struct {
+	ID            string `json:"id"`
+	PasswordHash  string `json:"password_hash"`
+	WalletAddress string `json:"wallet_address"`
+	CreatedAt     int64  `json:"created_at"`
+}
+
+// CreateUser stores a new user account with a password hash from
+// HashPassword and returns the user's ID
+func (sm *SecurityManager) CreateUser(ctx context.Context, username, passwordHash, walletAddress string) (string, error) {
+	record := userRecord{
+		ID:            uuid.New().String(),
+		PasswordHash:  passwordHash,
+		WalletAddress: walletAddress,
+		CreatedAt:     time.Now().Unix(),
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal user: %w", err)
+	}
+
+	created, err := sm.client.SetNX(ctx, userPrefix+username, data, 0).Result()
+	if err != nil {
+		return "", fmt.Errorf("failed to store user: %w", err)
+	}
+	if !created {
+		return "", ErrUserExists
+	}
+
+	return record.ID, nil
+}
+
+// AuthenticateUser checks a username and password and returns the user's ID.
+// Unknown users and wrong passwords both return ErrInvalidCredentials.
+func (sm *SecurityManager) AuthenticateUser(ctx context.Context, username, password string) (string, error) {
+	data, err := sm.client.Get(ctx, userPrefix+username).Bytes()
+	if err == redis.Nil {
+		return "", ErrInvalidCredentials
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get user: %w", err)
+	}
+
+	var record userRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return "", fmt.Errorf("failed to unmarshal user: %w", err)
+	}
+
+	if !sm.VerifyPassword(record.PasswordHash, password) {
+		return "", ErrInvalidCredentials
+	}
+
+	return record.ID, nil
+}
@@ -0,0 +1,78 @@
+package security
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestAuthenticateUser(t *testing.T) {
+	sm, _ := newTestSecurityManager(t)
+	ctx := context.Background()
+	if err := sm.SetBcryptCost(MinBcryptCost); err != nil {
+		t.Fatalf("SetBcryptCost: %v", err)
+	}
+
+	passwordHash, err := sm.HashPassword("correct horse battery staple")
+	if err != nil {
+		t.Fatalf("HashPassword: %v", err)
+	}
+	userID, err := sm.CreateUser(ctx, "alice", passwordHash, "wallet-1")
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	if _, err := sm.CreateUser(ctx, "alice", passwordHash, "wallet-2"); !errors.Is(err, ErrUserExists) {
+		t.Fatalf("second CreateUser error = %v, want %v", err, ErrUserExists)
+	}
+
+	tests := []struct {
+		name     string
+		username string
+		password string
+		wantErr  error
+	}{
+		{name: "correct password", username: "alice", password: "correct horse battery staple"},
+		{name: "wrong password", username: "alice", password: "another horse battery staple", wantErr: ErrInvalidCredentials},
+		{name: "unknown user", username: "bob", password: "correct horse battery staple", wantErr: ErrInvalidCredentials},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := sm.AuthenticateUser(ctx, tt.username, tt.password)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("AuthenticateUser error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && got != userID {
+				t.Fatalf("AuthenticateUser = %q, want %q", got, userID)
+			}
+		})
+	}
+}
+
+func TestFailedLoginLockout(t *testing.T) {
+	sm, fake := newTestSecurityManager(t)
+	ctx := context.Background()
+
+	for i := 0; i < maxFailedLoginAttempts; i++ {
+		if allowed, err := sm.CheckLoginAllowed(ctx, "alice"); err != nil || !allowed {
+			t.Fatalf("CheckLoginAllowed after %d failures = %v, %v; want true", i, allowed, err)
+		}
+		if err := sm.RecordFailedLogin(ctx, "alice"); err != nil {
+			t.Fatalf("RecordFailedLogin: %v", err)
+		}
+	}
+
+	if allowed, err := sm.CheckLoginAllowed(ctx, "alice"); err != nil || allowed {
+		t.Fatalf("CheckLoginAllowed after %d failures = %v, %v; want false", maxFailedLoginAttempts, allowed, err)
+	}
+	if ttl := fake.TTL(failedLoginKeyPrefix + "alice"); ttl <= 0 || ttl > loginLockoutDuration {
+		t.Fatalf("lockout TTL = %v, want at most %v", ttl, loginLockoutDuration)
+	}
+
+	if err := sm.ResetFailedLogins(ctx, "alice"); err != nil {
+		t.Fatalf("ResetFailedLogins: %v", err)
+	}
+	if allowed, err := sm.CheckLoginAllowed(ctx, "alice"); err != nil || !allowed {
+		t.Fatalf("CheckLoginAllowed after reset = %v, %v; want true", allowed, err)
+	}
+}
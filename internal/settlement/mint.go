@@ -0,0 +1,63 @@
+package settlement
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// SetMintSchedule makes the settlement process mint new supply at the rate
+// reported by source once per interval. Whether a mint is due is decided by
+// the canonical ledger's minting schedule, which persists its last mint, so
+// neither a restart nor the ledger's own StartMinting can mint twice in one
+// interval.
+func (e *SettlementEngine) SetMintSchedule(source InflationSource, interval time.Duration) error {
+	if source != nil && interval < time.Second {
+		return fmt.Errorf("mint interval must be at least one second, got %v", interval)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.inflation = source
+	e.mintInterval = interval
+
+	return nil
+}
+
+// ReconcileSupply mints supply on the canonical ledger at the current inflation
+// rate if the ledger's schedule has not minted in the current interval yet.
+// The resulting ledger entry is recorded on the latest settlement batch.
+func (e *SettlementEngine) ReconcileSupply(ctx context.Context) (bool, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.inflation == nil {
+		return false, nil
+	}
+
+	rate, err := e.inflation.GetInflationRate()
+	if err != nil {
+		return false, fmt.Errorf("failed to get inflation rate: %w", err)
+	}
+
+	// Use the entry the mint appended; other appends may already follow it
+	entryID, minted, err := e.canonicalLedger.MintIfDue(ctx, rate, e.mintInterval)
+	if err != nil {
+		return false, fmt.Errorf("failed to mint supply: %w", err)
+	}
+	if !minted {
+		return false, nil
+	}
+
+	if batch, exists := e.batches[e.latestBatchID]; exists && batch.LedgerEntryID == "" {
+		batch.LedgerEntryID = entryID
+	}
+
+	if e.metrics != nil {
+		e.metrics.RecordSupplyChange("mint")
+	}
+	e.logger.Info("Minted scheduled supply", "rate", rate, "ledger_entry", entryID)
+
+	return true, nil
+}
@@ -10,8 +10,11 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/cmatc13/stathera/pkg/logging"
+	"github.com/cmatc13/stathera/pkg/metrics"
 	"github.com/cmatc13/stathera/timeoracle"
 	"github.com/cmatc13/stathera/transaction"
 )
@@ -43,8 +46,15 @@ type SettlementEngine struct {
 	canonicalLedger LedgerManager
 	timeOracle      timeoracle.TimeOracle
 	batchSize       int
+	workers         int // batches settled concurrently per cycle
 	settleInterval  time.Duration
 	latestBatchID   string
+	logger          *logging.Logger
+	metrics         *metrics.Metrics
+
+	// Scheduled supply minting
+	inflation    InflationSource
+	mintInterval time.Duration
 }
 
 // TransactionProcessor defines the interface for the transaction layer
@@ -67,13 +77,21 @@ type LedgerManager interface {
 	// GetLatestHash returns the hash of the latest ledger entry
 	GetLatestHash() string
 
-	// MintSupply increases the total supply based on the provided inflation rate
-	MintSupply(ctx context.Context, inflationRate float64, reason string) error
+	// MintIfDue mints at the inflation rate if the ledger's minting schedule
+	// has not minted in the current interval, returning the new entry's hash
+	// and whether a mint happened
+	MintIfDue(ctx context.Context, inflationRate float64, interval time.Duration) (string, bool, error)
 
 	// VerifyIntegrity checks the integrity of the entire ledger chain
 	VerifyIntegrity() (bool, error)
 }
 
+// InflationSource provides the inflation rate decided by the supply layer
+type InflationSource interface {
+	// GetInflationRate returns the current annual inflation rate in percent
+	GetInflationRate() (float64, error)
+}
+
 // NewSettlementEngine creates a new settlement engine
 func NewSettlementEngine(
 	txEngine TransactionProcessor,
@@ -88,9 +106,35 @@ func NewSettlementEngine(
 		canonicalLedger: canonicalLedger,
 		timeOracle:      timeOracle,
 		batchSize:       batchSize,
+		workers:         1,
 		settleInterval:  settleInterval,
 		latestBatchID:   "",
+		logger:          logging.New(logging.DefaultConfig()),
+	}
+}
+
+// SetLogger sets the logger used by the settlement process
+func (e *SettlementEngine) SetLogger(logger *logging.Logger) {
+	e.logger = logger
+}
+
+// SetMetrics sets the collector that records settlement metrics
+func (e *SettlementEngine) SetMetrics(m *metrics.Metrics) {
+	e.metrics = m
+}
+
+// SetWorkers sets how many batches a settlement cycle may create and settle
+// concurrently. The batches still form a single chain in transaction order.
+func (e *SettlementEngine) SetWorkers(workers int) error {
+	if workers < 1 {
+		return fmt.Errorf("settlement workers must be at least 1, got %d", workers)
 	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.workers = workers
+	return nil
 }
 
 // StartSettlementProcess starts the periodic settlement process
@@ -105,13 +149,21 @@ func (e *SettlementEngine) StartSettlementProcess(ctx context.Context) {
 		case <-ticker.C:
 			if err := e.SettleTransactions(ctx); err != nil {
 				// Log error but continue
-				fmt.Printf("Settlement error: %v\n", err)
+				if errors.Is(err, ErrEmptyBatch) {
+					e.logger.Debug("No confirmed transactions to settle")
+				} else {
+					e.logger.Error("Settlement failed", "error", err)
+				}
+			}
+			if _, err := e.ReconcileSupply(ctx); err != nil {
+				e.logger.Error("Supply reconciliation failed", "error", err)
 			}
 		}
 	}
 }
 
-// SettleTransactions creates a batch of transactions and settles them to the ledger
+// SettleTransactions settles up to one batch of confirmed transactions per
+// worker to the ledger
 func (e *SettlementEngine) SettleTransactions(ctx context.Context) error {
 	e.mu.Lock()
 	defer e.mu.Unlock()
@@ -119,55 +171,100 @@ func (e *SettlementEngine) SettleTransactions(ctx context.Context) error {
 	// Get confirmed transactions
 	confirmedTxs := e.txEngine.GetConfirmedTransactions()
 	if len(confirmedTxs) == 0 {
+		if e.metrics != nil {
+			e.metrics.RecordEmptySettlement()
+		}
 		return ErrEmptyBatch
 	}
 
-	// Limit batch size
-	batchSize := e.batchSize
-	if batchSize > len(confirmedTxs) {
-		batchSize = len(confirmedTxs)
+	batches := e.splitBatches(confirmedTxs)
+
+	// Settle the batches concurrently; they are linked onto the chain in
+	// order afterwards, so the order in which workers finish does not matter
+	errs := make([]error, len(batches))
+	var wg sync.WaitGroup
+	for i, batch := range batches {
+		wg.Add(1)
+		go func(i int, batch *SettlementBatch) {
+			defer wg.Done()
+
+			start := time.Now()
+			errs[i] = e.settleBatch(batch)
+			if e.metrics != nil {
+				e.metrics.RecordSettlement(errs[i] == nil, len(batch.Transactions), time.Since(start))
+			}
+		}(i, batch)
+	}
+	wg.Wait()
+
+	e.chainBatches(batches, errs)
+
+	return errors.Join(errs...)
+}
+
+// splitBatches splits confirmed transactions into at most one batch per worker
+func (e *SettlementEngine) splitBatches(confirmedTxs []*transaction.Transaction) []*SettlementBatch {
+	var batches []*SettlementBatch
+	for len(confirmedTxs) > 0 && len(batches) < e.workers {
+		// Limit batch size
+		batchSize := e.batchSize
+		if batchSize > len(confirmedTxs) {
+			batchSize = len(confirmedTxs)
+		}
+
+		// Extract transaction IDs
+		txIDs := make([]string, batchSize)
+		for i, tx := range confirmedTxs[:batchSize] {
+			txIDs[i] = tx.ID
+		}
+		confirmedTxs = confirmedTxs[batchSize:]
+
+		batches = append(batches, &SettlementBatch{
+			ID:           generateID(),
+			Transactions: txIDs,
+			Status:       "PENDING",
+		})
 	}
 
-	// Select transactions for this batch
-	selectedTxs := confirmedTxs[:batchSize]
+	return batches
+}
 
-	// Extract transaction IDs
-	txIDs := make([]string, len(selectedTxs))
-	for i, tx := range selectedTxs {
-		txIDs[i] = tx.ID
+// chainBatches stores settled batches in order and links them onto the chain.
+// Failed batches are stored for inspection but never become the chain head,
+// so the next batch links to the last one that settled.
+func (e *SettlementEngine) chainBatches(batches []*SettlementBatch, errs []error) {
+	for i, batch := range batches {
+		batch.PrevBatchID = e.latestBatchID
+		e.batches[batch.ID] = batch
+		if errs[i] == nil {
+			e.latestBatchID = batch.ID
+		}
 	}
+}
 
+// settleBatch computes a batch's merkle root and time proof and marks its
+// transactions as settled
+func (e *SettlementEngine) settleBatch(batch *SettlementBatch) error {
 	// Create merkle tree
-	merkleRoot, err := e.calculateMerkleRoot(txIDs)
+	merkleRoot, err := e.calculateMerkleRoot(batch.Transactions)
 	if err != nil {
+		batch.Status = "FAILED"
 		return err
 	}
 
 	// Get time with proof
 	timestamp, timeProof, err := e.timeOracle.GetTimeWithProof()
 	if err != nil {
+		batch.Status = "FAILED"
 		return err
 	}
 
-	// Create batch
-	batch := &SettlementBatch{
-		ID:           generateID(),
-		Transactions: txIDs,
-		MerkleRoot:   merkleRoot,
-		Timestamp:    timestamp,
-		TimeProof:    timeProof,
-		PrevBatchID:  e.latestBatchID,
-		Status:       "PENDING",
-	}
-
-	// Store batch
-	e.batches[batch.ID] = batch
-
-	// Update latest batch ID
-	e.latestBatchID = batch.ID
+	batch.MerkleRoot = merkleRoot
+	batch.Timestamp = timestamp
+	batch.TimeProof = timeProof
 
 	// Mark transactions as settled
-	if err := e.txEngine.MarkTransactionsAsSettled(txIDs); err != nil {
+	if err := e.txEngine.MarkTransactionsAsSettled(batch.Transactions); err != nil {
 		batch.Status = "FAILED"
 		return err
 	}
@@ -243,9 +340,12 @@ func (e *SettlementEngine) VerifyBatch(batch *SettlementBatch) error {
 	return nil
 }
 
+// idSequence keeps batch IDs created within the same nanosecond distinct
+var idSequence atomic.Uint64
+
 // generateID generates a unique batch ID
 func generateID() string {
 	h := sha256.New()
-	h.Write([]byte(fmt.Sprintf("%d", time.Now().UnixNano())))
+	h.Write([]byte(fmt.Sprintf("%d-%d", time.Now().UnixNano(), idSequence.Add(1))))
 	return hex.EncodeToString(h.Sum(nil))[:16]
 }
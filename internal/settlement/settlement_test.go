@@ -0,0 +1,319 @@
+package settlement
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cmatc13/stathera/internal/ledger"
+	"github.com/cmatc13/stathera/internal/timeoracle"
+	"github.com/cmatc13/stathera/internal/transaction"
+)
+
+// fakeOracle is a time oracle whose clock is set by the test
+type fakeOracle struct {
+	mu       sync.Mutex
+	now      int64
+	proofErr error
+}
+
+func (o *fakeOracle) Now() int64 {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.now
+}
+
+func (o *fakeOracle) set(now int64) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.now = now
+}
+
+func (o *fakeOracle) Validate(timestamp int64) error { return nil }
+
+func (o *fakeOracle) GenerateProof() (*timeoracle.TimeProof, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.proofErr != nil {
+		return nil, o.proofErr
+	}
+	return &timeoracle.TimeProof{Timestamp: o.now}, nil
+}
+
+func (o *fakeOracle) VerifyProof(proof *timeoracle.TimeProof) error { return nil }
+
+func (o *fakeOracle) GetTimeWithProof() (int64, *timeoracle.TimeProof, error) {
+	proof, err := o.GenerateProof()
+	if err != nil {
+		return 0, nil, err
+	}
+	return proof.Timestamp, proof, nil
+}
+
+// racingLedger is a real ledger that appends another entry right after every
+// scheduled mint, so the latest hash never belongs to the mint
+type racingLedger struct {
+	*ledger.Ledger
+}
+
+func (l racingLedger) MintIfDue(ctx context.Context, rate float64, interval time.Duration) (string, bool, error) {
+	hash, minted, err := l.Ledger.MintIfDue(ctx, rate, interval)
+	if minted {
+		if _, err := l.MintSupply(ctx, rate, "concurrent append"); err != nil {
+			return "", false, err
+		}
+	}
+	return hash, minted, err
+}
+
+// scheduledMints returns the rates of the ledger's scheduled mints
+func scheduledMints(l *ledger.Ledger) []float64 {
+	var rates []float64
+	entries := l.GetEntries()
+	for i, entry := range entries[1:] {
+		if entry.Reason == "Scheduled issuance" {
+			rates = append(rates, 100*entry.Delta/entries[i].TotalSupply)
+		}
+	}
+	return rates
+}
+
+func newTestLedger(t *testing.T, oracle *fakeOracle) *ledger.Ledger {
+	t.Helper()
+
+	l, err := ledger.NewLedger(1000, 1, 5, oracle)
+	if err != nil {
+		t.Fatalf("NewLedger: %v", err)
+	}
+	return l
+}
+
+// fakeProcessor serves a fixed set of confirmed transactions
+type fakeProcessor struct {
+	mu        sync.Mutex
+	confirmed []*transaction.Transaction
+	settled   []string
+	settleErr error
+}
+
+func (p *fakeProcessor) GetConfirmedTransactions() []*transaction.Transaction {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]*transaction.Transaction(nil), p.confirmed...)
+}
+
+func (p *fakeProcessor) MarkTransactionsAsSettled(txIDs []string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.settleErr != nil {
+		return p.settleErr
+	}
+
+	settled := make(map[string]bool, len(txIDs))
+	for _, id := range txIDs {
+		settled[id] = true
+	}
+	remaining := p.confirmed[:0]
+	for _, tx := range p.confirmed {
+		if !settled[tx.ID] {
+			remaining = append(remaining, tx)
+		}
+	}
+	p.confirmed = remaining
+	p.settled = append(p.settled, txIDs...)
+	return nil
+}
+
+func (p *fakeProcessor) GetTransaction(id string) (*transaction.Transaction, error) {
+	return nil, errors.New("not found")
+}
+
+// fixedRate is an inflation source reporting a constant rate
+type fixedRate float64
+
+func (r fixedRate) GetInflationRate() (float64, error) { return float64(r), nil }
+
+func newTestTransactions(n int) []*transaction.Transaction {
+	txs := make([]*transaction.Transaction, n)
+	for i := range txs {
+		txs[i] = &transaction.Transaction{ID: fmt.Sprintf("tx-%d", i), Status: transaction.Confirmed}
+	}
+	return txs
+}
+
+func newTestEngine(processor *fakeProcessor, canonical LedgerManager, oracle *fakeOracle) *SettlementEngine {
+	return NewSettlementEngine(processor, canonical, oracle, 10, time.Minute)
+}
+
+func TestReconcileSupplyMintsOncePerPeriod(t *testing.T) {
+	oracle := &fakeOracle{now: 1000}
+	canonical := newTestLedger(t, oracle)
+	e := newTestEngine(&fakeProcessor{}, canonical, oracle)
+	if err := e.SetMintSchedule(fixedRate(2.5), time.Hour); err != nil {
+		t.Fatalf("SetMintSchedule: %v", err)
+	}
+
+	steps := []struct {
+		now        int64
+		wantMinted bool
+	}{
+		{now: 1000, wantMinted: false}, // the interval the schedule started in
+		{now: 3600, wantMinted: true},
+		{now: 3600 + 1800, wantMinted: false},
+		{now: 7200 - 1, wantMinted: false},
+		{now: 7200, wantMinted: true},
+		{now: 7200 + 10, wantMinted: false},
+	}
+
+	for _, step := range steps {
+		oracle.set(step.now)
+		minted, err := e.ReconcileSupply(context.Background())
+		if err != nil {
+			t.Fatalf("ReconcileSupply at %d: %v", step.now, err)
+		}
+		if minted != step.wantMinted {
+			t.Fatalf("ReconcileSupply at %d minted = %v, want %v", step.now, minted, step.wantMinted)
+		}
+	}
+
+	if got := scheduledMints(canonical); len(got) != 2 || got[0] != 2.5 || got[1] != 2.5 {
+		t.Fatalf("mints = %v, want two mints at 2.5", got)
+	}
+}
+
+func TestReconcileSupplySharesLedgerSchedule(t *testing.T) {
+	oracle := &fakeOracle{now: 1000}
+	canonical := newTestLedger(t, oracle)
+	e := newTestEngine(&fakeProcessor{}, canonical, oracle)
+	if err := e.SetMintSchedule(fixedRate(2), time.Hour); err != nil {
+		t.Fatalf("SetMintSchedule: %v", err)
+	}
+	if _, err := e.ReconcileSupply(context.Background()); err != nil {
+		t.Fatalf("ReconcileSupply: %v", err)
+	}
+
+	// The ledger's own schedule mints first; reconciliation must not mint again
+	oracle.set(3600)
+	if _, minted, err := canonical.MintIfDue(context.Background(), 2, time.Hour); err != nil || !minted {
+		t.Fatalf("MintIfDue = %v, %v; want a mint", minted, err)
+	}
+	minted, err := e.ReconcileSupply(context.Background())
+	if err != nil {
+		t.Fatalf("ReconcileSupply: %v", err)
+	}
+	if minted {
+		t.Fatal("ReconcileSupply minted again in an interval the ledger already minted in")
+	}
+}
+
+func TestReconcileSupplyRecordsMintedEntry(t *testing.T) {
+	oracle := &fakeOracle{now: 1000}
+	canonical := racingLedger{newTestLedger(t, oracle)}
+	processor := &fakeProcessor{confirmed: newTestTransactions(3)}
+	e := newTestEngine(processor, canonical, oracle)
+	if err := e.SetMintSchedule(fixedRate(2), time.Hour); err != nil {
+		t.Fatalf("SetMintSchedule: %v", err)
+	}
+	if _, err := e.ReconcileSupply(context.Background()); err != nil {
+		t.Fatalf("ReconcileSupply: %v", err)
+	}
+
+	if err := e.SettleTransactions(context.Background()); err != nil {
+		t.Fatalf("SettleTransactions: %v", err)
+	}
+
+	oracle.set(3600)
+	if _, err := e.ReconcileSupply(context.Background()); err != nil {
+		t.Fatalf("ReconcileSupply: %v", err)
+	}
+
+	entries := canonical.GetEntries()
+	mint := entries[len(entries)-2]
+	if mint.Reason != "Scheduled issuance" {
+		t.Fatalf("entry before the latest has reason %q, want the scheduled mint", mint.Reason)
+	}
+
+	batch, err := e.GetLatestBatch()
+	if err != nil {
+		t.Fatalf("GetLatestBatch: %v", err)
+	}
+	if batch.LedgerEntryID != mint.Hash {
+		t.Fatalf("LedgerEntryID = %q, want the minted entry %q", batch.LedgerEntryID, mint.Hash)
+	}
+}
+
+func TestFailedBatchDoesNotAdvanceChain(t *testing.T) {
+	tests := []struct {
+		name      string
+		proofErr  error
+		settleErr error
+	}{
+		{name: "time proof fails", proofErr: errors.New("oracle unavailable")},
+		{name: "marking settled fails", settleErr: errors.New("store unavailable")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oracle := &fakeOracle{now: 1000}
+			processor := &fakeProcessor{confirmed: newTestTransactions(3)}
+			e := newTestEngine(processor, newTestLedger(t, oracle), oracle)
+
+			if err := e.SettleTransactions(context.Background()); err != nil {
+				t.Fatalf("first SettleTransactions: %v", err)
+			}
+			first, err := e.GetLatestBatch()
+			if err != nil {
+				t.Fatalf("GetLatestBatch: %v", err)
+			}
+
+			processor.confirmed = newTestTransactions(2)
+			oracle.proofErr, processor.settleErr = tt.proofErr, tt.settleErr
+			if err := e.SettleTransactions(context.Background()); err == nil {
+				t.Fatal("SettleTransactions succeeded, want an error")
+			}
+			if latest, _ := e.GetLatestBatch(); latest != first {
+				t.Fatalf("latest batch = %s after a failure, want %s", latest.ID, first.ID)
+			}
+
+			oracle.proofErr, processor.settleErr = nil, nil
+			if err := e.SettleTransactions(context.Background()); err != nil {
+				t.Fatalf("retry SettleTransactions: %v", err)
+			}
+			latest, err := e.GetLatestBatch()
+			if err != nil {
+				t.Fatalf("GetLatestBatch: %v", err)
+			}
+			if latest.Status != "SETTLED" || latest.PrevBatchID != first.ID {
+				t.Fatalf("latest batch = %s linked to %q, want SETTLED linked to %q", latest.Status, latest.PrevBatchID, first.ID)
+			}
+		})
+	}
+}
+
+func TestFailedFirstBatchLeavesNoChainHead(t *testing.T) {
+	oracle := &fakeOracle{now: 1000, proofErr: errors.New("oracle unavailable")}
+	processor := &fakeProcessor{confirmed: newTestTransactions(3)}
+	e := newTestEngine(processor, newTestLedger(t, oracle), oracle)
+
+	if err := e.SettleTransactions(context.Background()); err == nil {
+		t.Fatal("SettleTransactions succeeded, want an error")
+	}
+	if _, err := e.GetLatestBatch(); err == nil {
+		t.Fatal("GetLatestBatch returned a batch that failed to settle")
+	}
+
+	oracle.proofErr = nil
+	if err := e.SettleTransactions(context.Background()); err != nil {
+		t.Fatalf("retry SettleTransactions: %v", err)
+	}
+	latest, err := e.GetLatestBatch()
+	if err != nil {
+		t.Fatalf("GetLatestBatch: %v", err)
+	}
+	if latest.PrevBatchID != "" {
+		t.Fatalf("first settled batch links to %q, want no previous batch", latest.PrevBatchID)
+	}
+}
@@ -0,0 +1,221 @@
+package timeoracle
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// redisProofPrefix prefixes the Redis keys of cached proofs
+const redisProofPrefix = "timeoracle:proof:"
+
+// redisCacheTimeout bounds each Redis round trip made by the proof cache
+const redisCacheTimeout = time.Second
+
+// ProofCache stores the proof issued for each second so repeated requests
+// within a second return the same proof
+type ProofCache interface {
+	// Get returns the cached proof for a timestamp
+	Get(timestamp int64) (*TimeProof, bool)
+
+	// Put caches a proof under its timestamp
+	Put(proof TimeProof) error
+
+	// Prune removes proofs issued before a timestamp
+	Prune(before int64)
+
+	// Proofs returns all cached proofs
+	Proofs() []TimeProof
+}
+
+// SetProofCache replaces the oracle's proof cache. The default cache is an
+// in-memory cache of DefaultProofCacheSize proofs and is lost on restart.
+func (o *StandardTimeOracle) SetProofCache(cache ProofCache) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if cache == nil {
+		cache = NewMemoryProofCache(DefaultProofCacheSize)
+	}
+	o.proofCache = cache
+}
+
+// DefaultProofCacheSize is the number of proofs the default in-memory cache
+// holds, one hour of seconds
+const DefaultProofCacheSize = 3600
+
+// MemoryProofCache is an in-memory proof cache holding at most a fixed number
+// of proofs. When full, the least recently used proof is evicted; verification
+// recomputes signatures, so an evicted proof still verifies.
+type MemoryProofCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	proofs     map[int64]*list.Element
+	order      *list.List // most recently used first
+}
+
+// NewMemoryProofCache creates an in-memory proof cache holding at most
+// maxEntries proofs. A non-positive maxEntries uses DefaultProofCacheSize.
+func NewMemoryProofCache(maxEntries int) *MemoryProofCache {
+	if maxEntries <= 0 {
+		maxEntries = DefaultProofCacheSize
+	}
+
+	return &MemoryProofCache{
+		maxEntries: maxEntries,
+		proofs:     make(map[int64]*list.Element),
+		order:      list.New(),
+	}
+}
+
+// Get returns the cached proof for a timestamp
+func (c *MemoryProofCache) Get(timestamp int64) (*TimeProof, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, exists := c.proofs[timestamp]
+	if !exists {
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+
+	proof := elem.Value.(TimeProof)
+	return &proof, true
+}
+
+// Put caches a proof under its timestamp, evicting the least recently used
+// proof if the cache is full
+func (c *MemoryProofCache) Put(proof TimeProof) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, exists := c.proofs[proof.Timestamp]; exists {
+		elem.Value = proof
+		c.order.MoveToFront(elem)
+		return nil
+	}
+
+	c.proofs[proof.Timestamp] = c.order.PushFront(proof)
+	for c.order.Len() > c.maxEntries {
+		c.remove(c.order.Back())
+	}
+
+	return nil
+}
+
+// Prune removes proofs issued before a timestamp
+func (c *MemoryProofCache) Prune(before int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for ts, elem := range c.proofs {
+		if ts < before {
+			c.remove(elem)
+		}
+	}
+}
+
+// Proofs returns all cached proofs
+func (c *MemoryProofCache) Proofs() []TimeProof {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	proofs := make([]TimeProof, 0, c.order.Len())
+	for elem := c.order.Front(); elem != nil; elem = elem.Next() {
+		proofs = append(proofs, elem.Value.(TimeProof))
+	}
+	return proofs
+}
+
+// Len returns the number of cached proofs
+func (c *MemoryProofCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
+
+// remove deletes a cached proof
+func (c *MemoryProofCache) remove(elem *list.Element) {
+	delete(c.proofs, elem.Value.(TimeProof).Timestamp)
+	c.order.Remove(elem)
+}
+
+// RedisProofCache is a proof cache stored in Redis, so cached proofs survive
+// restarts and are shared by every oracle using the same Redis
+type RedisProofCache struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+// NewRedisProofCache creates a Redis-backed proof cache. Proofs expire after
+// ttl, which should match the oracle's proof validity.
+func NewRedisProofCache(client *redis.Client, ttl time.Duration) *RedisProofCache {
+	return &RedisProofCache{client: client, ttl: ttl}
+}
+
+// Get returns the cached proof for a timestamp. Redis errors are reported as
+// a cache miss so the oracle can still issue a proof.
+func (c *RedisProofCache) Get(timestamp int64) (*TimeProof, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisCacheTimeout)
+	defer cancel()
+
+	data, err := c.client.Get(ctx, redisProofKey(timestamp)).Bytes()
+	if err != nil {
+		return nil, false
+	}
+
+	var proof TimeProof
+	if err := json.Unmarshal(data, &proof); err != nil {
+		return nil, false
+	}
+	return &proof, true
+}
+
+// Put caches a proof under its timestamp. If another oracle has already
+// cached a proof for the same second, that proof is kept.
+func (c *RedisProofCache) Put(proof TimeProof) error {
+	data, err := json.Marshal(proof)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisCacheTimeout)
+	defer cancel()
+
+	return c.client.SetNX(ctx, redisProofKey(proof.Timestamp), data, c.ttl).Err()
+}
+
+// Prune is a no-op; cached proofs expire through their TTL
+func (c *RedisProofCache) Prune(before int64) {}
+
+// Proofs returns all cached proofs
+func (c *RedisProofCache) Proofs() []TimeProof {
+	ctx, cancel := context.WithTimeout(context.Background(), redisCacheTimeout)
+	defer cancel()
+
+	var proofs []TimeProof
+	iter := c.client.Scan(ctx, 0, redisProofPrefix+"*", 100).Iterator()
+	for iter.Next(ctx) {
+		timestamp, err := strconv.ParseInt(strings.TrimPrefix(iter.Val(), redisProofPrefix), 10, 64)
+		if err != nil {
+			continue
+		}
+		if proof, exists := c.Get(timestamp); exists {
+			proofs = append(proofs, *proof)
+		}
+	}
+
+	return proofs
+}
+
+// redisProofKey returns the Redis key of the proof cached for a timestamp
+func redisProofKey(timestamp int64) string {
+	return redisProofPrefix + strconv.FormatInt(timestamp, 10)
+}
@@ -0,0 +1,211 @@
+package timeoracle
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+// ntpEpochOffset is the number of seconds between the NTP epoch (1900) and the Unix epoch (1970)
+const ntpEpochOffset = 2208988800
+
+// ClockSource provides the current time to a time oracle
+type ClockSource interface {
+	// Now returns the current time
+	Now() time.Time
+}
+
+// SystemClock is a clock source backed by the local system clock
+type SystemClock struct{}
+
+// Now returns the current system time
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// NTPClock is a clock source that corrects the system clock using the offset
+// measured against a set of NTP servers
+type NTPClock struct {
+	mu           sync.RWMutex
+	servers      []string
+	timeout      time.Duration
+	pollInterval time.Duration
+	offset       time.Duration
+	lastSync     time.Time
+	lastErr      error
+	stopCh       chan struct{}
+	wg           sync.WaitGroup
+}
+
+// NewNTPClock creates a new NTP-backed clock source
+func NewNTPClock(servers []string, pollInterval, timeout time.Duration) (*NTPClock, error) {
+	if len(servers) == 0 {
+		return nil, errors.New("at least one NTP server is required")
+	}
+	if pollInterval <= 0 {
+		pollInterval = 5 * time.Minute
+	}
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+
+	return &NTPClock{
+		servers:      servers,
+		timeout:      timeout,
+		pollInterval: pollInterval,
+	}, nil
+}
+
+// Start performs an initial sync and begins polling the NTP servers in the background
+func (c *NTPClock) Start() {
+	c.mu.Lock()
+	if c.stopCh != nil {
+		c.mu.Unlock()
+		return
+	}
+	c.stopCh = make(chan struct{})
+	stopCh := c.stopCh
+	c.mu.Unlock()
+
+	// Sync immediately so the offset is available before the first tick
+	c.Sync()
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+
+		ticker := time.NewTicker(c.pollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				c.Sync()
+			case <-stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops background polling
+func (c *NTPClock) Stop() {
+	c.mu.Lock()
+	if c.stopCh == nil {
+		c.mu.Unlock()
+		return
+	}
+	close(c.stopCh)
+	c.stopCh = nil
+	c.mu.Unlock()
+
+	c.wg.Wait()
+}
+
+// Sync queries the configured NTP servers and updates the offset from the
+// first server that answers. On failure the previous offset is kept.
+func (c *NTPClock) Sync() error {
+	var lastErr error
+	for _, server := range c.servers {
+		offset, err := queryNTPOffset(server, c.timeout)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		c.mu.Lock()
+		c.offset = offset
+		c.lastSync = time.Now()
+		c.lastErr = nil
+		c.mu.Unlock()
+		return nil
+	}
+
+	c.mu.Lock()
+	c.lastErr = lastErr
+	c.mu.Unlock()
+	return lastErr
+}
+
+// Now returns the system time corrected by the last measured NTP offset.
+// If no server has ever answered, this is the plain system time.
+func (c *NTPClock) Now() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return time.Now().Add(c.offset)
+}
+
+// Offset returns the last measured offset between the system clock and NTP time
+func (c *NTPClock) Offset() time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.offset
+}
+
+// LastSync returns the time of the last successful sync and the last sync error, if any
+func (c *NTPClock) LastSync() (time.Time, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.lastSync, c.lastErr
+}
+
+// queryNTPOffset performs a single SNTP request and returns the clock offset
+func queryNTPOffset(server string, timeout time.Duration) (time.Duration, error) {
+	// Default to the standard NTP port
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, "123")
+	}
+
+	conn, err := net.DialTimeout("udp", server, timeout)
+	if err != nil {
+		return 0, fmt.Errorf("failed to connect to NTP server %s: %w", server, err)
+	}
+	defer conn.Close()
+
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return 0, fmt.Errorf("failed to set NTP deadline: %w", err)
+	}
+
+	// Build request: LI=0, VN=4, Mode=3 (client)
+	req := make([]byte, 48)
+	req[0] = 0x23
+
+	t1 := time.Now()
+	if _, err := conn.Write(req); err != nil {
+		return 0, fmt.Errorf("failed to send NTP request to %s: %w", server, err)
+	}
+
+	resp := make([]byte, 48)
+	n, err := conn.Read(resp)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read NTP response from %s: %w", server, err)
+	}
+	t4 := time.Now()
+
+	if n < 48 {
+		return 0, fmt.Errorf("short NTP response from %s: %d bytes", server, n)
+	}
+
+	// Reject kiss-of-death and unsynchronized responses
+	if resp[1] == 0 || resp[0]>>6 == 3 {
+		return 0, fmt.Errorf("NTP server %s is not synchronized", server)
+	}
+
+	t2 := ntpToTime(resp[32:40])
+	t3 := ntpToTime(resp[40:48])
+
+	// Offset = ((T2 - T1) + (T3 - T4)) / 2
+	return (t2.Sub(t1) + t3.Sub(t4)) / 2, nil
+}
+
+// ntpToTime converts a 64-bit NTP timestamp to a time.Time
+func ntpToTime(b []byte) time.Time {
+	secs := binary.BigEndian.Uint32(b[0:4])
+	frac := binary.BigEndian.Uint32(b[4:8])
+
+	nanos := (int64(frac) * int64(time.Second)) >> 32
+	return time.Unix(int64(secs)-ntpEpochOffset, nanos)
+}
@@ -0,0 +1,183 @@
+package timeoracle
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ErrNoQuorum is returned when not enough oracles agree on the time
+var ErrNoQuorum = errors.New("time oracle quorum not reached")
+
+// ConsensusTimeOracle combines several time oracles and only produces proofs
+// when a quorum of them agree on the current time
+type ConsensusTimeOracle struct {
+	oracles  []TimeOracle
+	quorum   int
+	maxDrift time.Duration
+}
+
+// NewConsensusTimeOracle creates a new consensus time oracle
+func NewConsensusTimeOracle(oracles []TimeOracle, quorum int, maxDrift time.Duration) (*ConsensusTimeOracle, error) {
+	if len(oracles) == 0 {
+		return nil, errors.New("at least one oracle is required")
+	}
+	if quorum <= 0 || quorum > len(oracles) {
+		return nil, fmt.Errorf("quorum must be between 1 and %d", len(oracles))
+	}
+
+	return &ConsensusTimeOracle{
+		oracles:  oracles,
+		quorum:   quorum,
+		maxDrift: maxDrift,
+	}, nil
+}
+
+// Now returns the median timestamp reported by the member oracles
+func (c *ConsensusTimeOracle) Now() int64 {
+	timestamps := make([]int64, len(c.oracles))
+	for i, oracle := range c.oracles {
+		timestamps[i] = oracle.Now()
+	}
+
+	return median(timestamps)
+}
+
+// Validate checks that a quorum of member oracles accept the timestamp
+func (c *ConsensusTimeOracle) Validate(timestamp int64) error {
+	var lastErr error
+	valid := 0
+	for _, oracle := range c.oracles {
+		if err := oracle.Validate(timestamp); err != nil {
+			lastErr = err
+			continue
+		}
+		valid++
+	}
+
+	if valid < c.quorum {
+		return fmt.Errorf("%w: %d of %d oracles accepted timestamp %d: %v",
+			ErrNoQuorum, valid, c.quorum, timestamp, lastErr)
+	}
+
+	return nil
+}
+
+// GenerateProof collects a proof from every member oracle and returns a
+// bundle proof for the median timestamp if a quorum agree within maxDrift
+func (c *ConsensusTimeOracle) GenerateProof() (*TimeProof, error) {
+	bundle := make([]*TimeProof, len(c.oracles))
+	timestamps := make([]int64, 0, len(c.oracles))
+
+	// Collect proofs from each oracle
+	for i, oracle := range c.oracles {
+		proof, err := oracle.GenerateProof()
+		if err != nil {
+			continue
+		}
+		bundle[i] = proof
+		timestamps = append(timestamps, proof.Timestamp)
+	}
+
+	if len(timestamps) < c.quorum {
+		return nil, fmt.Errorf("%w: only %d of %d oracles produced a proof",
+			ErrNoQuorum, len(timestamps), c.quorum)
+	}
+
+	ts := median(timestamps)
+
+	// Drop proofs that disagree with the median
+	agreeing := 0
+	for i, proof := range bundle {
+		if proof == nil {
+			continue
+		}
+		if !c.withinDrift(proof.Timestamp, ts) {
+			bundle[i] = nil
+			continue
+		}
+		agreeing++
+	}
+
+	if agreeing < c.quorum {
+		return nil, fmt.Errorf("%w: only %d of %d oracles agree on timestamp %d",
+			ErrNoQuorum, agreeing, c.quorum, ts)
+	}
+
+	return &TimeProof{
+		Timestamp: ts,
+		Bundle:    bundle,
+	}, nil
+}
+
+// VerifyProof checks that a quorum of oracles accept the bundle timestamp and
+// that a quorum of member proofs in the bundle verify against their oracles
+// and agree with it
+func (c *ConsensusTimeOracle) VerifyProof(proof *TimeProof) error {
+	if proof == nil {
+		return errors.New("proof cannot be nil")
+	}
+
+	// Reject stale or future proofs even if their signatures are valid
+	if err := c.Validate(proof.Timestamp); err != nil {
+		return err
+	}
+	if len(proof.Bundle) != len(c.oracles) {
+		return fmt.Errorf("%w: bundle has %d proofs, expected %d",
+			ErrInvalidProof, len(proof.Bundle), len(c.oracles))
+	}
+
+	verified := 0
+	for i, member := range proof.Bundle {
+		if member == nil {
+			continue
+		}
+		if !c.withinDrift(member.Timestamp, proof.Timestamp) {
+			continue
+		}
+		if err := c.oracles[i].VerifyProof(member); err != nil {
+			continue
+		}
+		verified++
+	}
+
+	if verified < c.quorum {
+		return fmt.Errorf("%w: only %d of %d member proofs verified",
+			ErrInvalidProof, verified, c.quorum)
+	}
+
+	return nil
+}
+
+// GetTimeWithProof returns the consensus time with a bundle proof
+func (c *ConsensusTimeOracle) GetTimeWithProof() (int64, *TimeProof, error) {
+	proof, err := c.GenerateProof()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return proof.Timestamp, proof, nil
+}
+
+// withinDrift reports whether two timestamps differ by at most maxDrift
+func (c *ConsensusTimeOracle) withinDrift(a, b int64) bool {
+	diff := a - b
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff <= int64(c.maxDrift.Seconds())
+}
+
+// median returns the median of a set of timestamps
+func median(values []int64) int64 {
+	sorted := make([]int64, len(values))
+	copy(sorted, values)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
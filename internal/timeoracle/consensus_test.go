@@ -0,0 +1,107 @@
+package timeoracle
+
+import (
+	"crypto/hmac"
+	"errors"
+	"testing"
+	"time"
+)
+
+// signatureOnlyOracle verifies member proofs by signature alone, leaving
+// timestamp checks to Validate
+type signatureOnlyOracle struct {
+	*StandardTimeOracle
+}
+
+func (o signatureOnlyOracle) VerifyProof(proof *TimeProof) error {
+	expected, err := o.signTimestamp(proof.Timestamp, proof.Nonce)
+	if err != nil {
+		return err
+	}
+	if !hmac.Equal(proof.Signature, expected) {
+		return ErrInvalidProof
+	}
+	return nil
+}
+
+func newTestConsensus(t *testing.T, clock *fakeClock, wrap func(*StandardTimeOracle) TimeOracle) *ConsensusTimeOracle {
+	t.Helper()
+
+	oracles := make([]TimeOracle, 3)
+	for i := range oracles {
+		secret := make([]byte, 32)
+		secret[0] = byte(i)
+		oracle, err := NewStandardTimeOracle(secret, 5*time.Second, time.Minute, clock)
+		if err != nil {
+			t.Fatalf("NewStandardTimeOracle: %v", err)
+		}
+		oracles[i] = wrap(oracle)
+	}
+
+	c, err := NewConsensusTimeOracle(oracles, 2, 2*time.Second)
+	if err != nil {
+		t.Fatalf("NewConsensusTimeOracle: %v", err)
+	}
+	return c
+}
+
+func TestConsensusVerifyProofValidatesTimestamp(t *testing.T) {
+	members := []struct {
+		name string
+		wrap func(*StandardTimeOracle) TimeOracle
+	}{
+		{name: "standard members", wrap: func(o *StandardTimeOracle) TimeOracle { return o }},
+		{name: "signature-only members", wrap: func(o *StandardTimeOracle) TimeOracle { return signatureOnlyOracle{o} }},
+	}
+	tests := []struct {
+		name    string
+		advance time.Duration
+		wantErr bool
+	}{
+		{name: "fresh proof", advance: 10 * time.Second},
+		{name: "expired proof", advance: 2 * time.Minute, wantErr: true},
+		{name: "future proof", advance: -time.Minute, wantErr: true},
+	}
+
+	for _, m := range members {
+		for _, tt := range tests {
+			t.Run(m.name+"/"+tt.name, func(t *testing.T) {
+				clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+				c := newTestConsensus(t, clock, m.wrap)
+
+				proof, err := c.GenerateProof()
+				if err != nil {
+					t.Fatalf("GenerateProof: %v", err)
+				}
+				clock.advance(tt.advance)
+
+				err = c.VerifyProof(proof)
+				if tt.wantErr {
+					if !errors.Is(err, ErrNoQuorum) {
+						t.Fatalf("VerifyProof error = %v, want %v", err, ErrNoQuorum)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("VerifyProof: %v", err)
+				}
+			})
+		}
+	}
+}
+
+func TestConsensusVerifyProofRejectsTamperedBundle(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+	c := newTestConsensus(t, clock, func(o *StandardTimeOracle) TimeOracle { return o })
+
+	proof, err := c.GenerateProof()
+	if err != nil {
+		t.Fatalf("GenerateProof: %v", err)
+	}
+	proof.Bundle[0].Signature = []byte("forged")
+	proof.Bundle[1].Signature = []byte("forged")
+
+	if err := c.VerifyProof(proof); !errors.Is(err, ErrInvalidProof) {
+		t.Fatalf("VerifyProof error = %v, want %v", err, ErrInvalidProof)
+	}
+}
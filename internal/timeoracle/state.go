@@ -0,0 +1,84 @@
+package timeoracle
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// stateVersion is the format version written by ExportState
+const stateVersion = 1
+
+// ErrStateMismatch is returned when importing state exported by an oracle
+// with a different secret
+var ErrStateMismatch = errors.New("time oracle state was exported with a different secret")
+
+// oracleState is the exported state of a standard time oracle
+type oracleState struct {
+	Version  int         `json:"version"`
+	SecretID string      `json:"secret_id"`
+	Proofs   []TimeProof `json:"proofs"`
+}
+
+// ExportState serializes the oracle's cached proofs so a standby oracle
+// sharing the same secret can take over without issuing different proofs for
+// seconds the primary has already covered. The secret itself is not exported.
+func (o *StandardTimeOracle) ExportState() ([]byte, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.cleanCache()
+
+	data, err := json.Marshal(oracleState{
+		Version:  stateVersion,
+		SecretID: o.secretID(),
+		Proofs:   o.proofCache.Proofs(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize time oracle state: %w", err)
+	}
+
+	return data, nil
+}
+
+// ImportState loads proofs exported by another oracle into this oracle's
+// cache. The state must come from an oracle with the same secret; proofs that
+// no longer verify, such as expired ones, are skipped.
+func (o *StandardTimeOracle) ImportState(data []byte) error {
+	var state oracleState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("failed to parse time oracle state: %w", err)
+	}
+	if state.Version != stateVersion {
+		return fmt.Errorf("unsupported time oracle state version %d", state.Version)
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if !hmac.Equal([]byte(state.SecretID), []byte(o.secretID())) {
+		return ErrStateMismatch
+	}
+
+	for _, proof := range state.Proofs {
+		if err := o.VerifyProof(&proof); err != nil {
+			continue
+		}
+		if err := o.proofCache.Put(proof); err != nil {
+			return fmt.Errorf("failed to cache imported proof: %w", err)
+		}
+	}
+
+	o.cleanCache()
+	return nil
+}
+
+// secretID identifies the oracle's secret without revealing it
+func (o *StandardTimeOracle) secretID() string {
+	h := hmac.New(sha256.New, o.secret)
+	h.Write([]byte("stathera-timeoracle-state"))
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
@@ -43,6 +43,9 @@ type TimeProof struct {
 	Timestamp int64  `json:"timestamp"`
 	Nonce     uint64 `json:"nonce"`
 	Signature []byte `json:"signature"`
+
+	// Bundle holds the member proofs of a consensus proof, indexed by oracle
+	Bundle []*TimeProof `json:"bundle,omitempty"`
 }
 
 // StandardTimeOracle implements a secure time oracle using HMAC-SHA256
@@ -51,31 +54,38 @@ type StandardTimeOracle struct {
 	secret        []byte
 	maxDrift      time.Duration
 	proofValidity time.Duration
-	proofCache    map[int64]TimeProof
+	proofCache    ProofCache
+	clock         ClockSource
 }
 
-// NewStandardTimeOracle creates a new standard time oracle
-func NewStandardTimeOracle(secret []byte, maxDrift, proofValidity time.Duration) (*StandardTimeOracle, error) {
+// NewStandardTimeOracle creates a new standard time oracle.
+// If clock is nil, the system clock is used.
+func NewStandardTimeOracle(secret []byte, maxDrift, proofValidity time.Duration, clock ClockSource) (*StandardTimeOracle, error) {
 	if len(secret) < 32 {
 		return nil, errors.New("secret must be at least 32 bytes")
 	}
 
+	if clock == nil {
+		clock = SystemClock{}
+	}
+
 	return &StandardTimeOracle{
 		secret:        secret,
 		maxDrift:      maxDrift,
 		proofValidity: proofValidity,
-		proofCache:    make(map[int64]TimeProof),
+		proofCache:    NewMemoryProofCache(DefaultProofCacheSize),
+		clock:         clock,
 	}, nil
 }
 
 // Now returns the current timestamp
 func (o *StandardTimeOracle) Now() int64 {
-	return time.Now().Unix()
+	return o.clock.Now().Unix()
 }
 
 // Validate checks if a timestamp is valid
 func (o *StandardTimeOracle) Validate(timestamp int64) error {
-	now := time.Now().Unix()
+	now := o.clock.Now().Unix()
 
 	// Check if timestamp is in the future (with allowed drift)
 	maxAllowed := now + int64(o.maxDrift.Seconds())
@@ -100,15 +110,15 @@ func (o *StandardTimeOracle) GenerateProof() (*TimeProof, error) {
 	defer o.mu.Unlock()
 
 	// Get current time
-	now := time.Now().Unix()
+	now := o.clock.Now().Unix()
 
 	// Check if we have a cached proof for this second
-	if proof, exists := o.proofCache[now]; exists {
-		return &proof, nil
+	if proof, exists := o.proofCache.Get(now); exists {
+		return proof, nil
 	}
 
 	// Generate a new proof
-	nonce := uint64(time.Now().UnixNano())
+	nonce := uint64(o.clock.Now().UnixNano())
 
 	// Create signature
 	signature, err := o.signTimestamp(now, nonce)
@@ -122,8 +132,9 @@ func (o *StandardTimeOracle) GenerateProof() (*TimeProof, error) {
 		Signature: signature,
 	}
 
-	// Cache the proof
-	o.proofCache[now] = proof
+	// Cache the proof. The cache only avoids regenerating proofs, so a
+	// failed write does not affect the proof's validity.
+	_ = o.proofCache.Put(proof)
 
 	// Clean old proofs from cache
 	o.cleanCache()
@@ -176,14 +187,10 @@ func (o *StandardTimeOracle) signTimestamp(timestamp int64, nonce uint64) ([]byt
 
 // cleanCache removes expired proofs from the cache
 func (o *StandardTimeOracle) cleanCache() {
-	now := time.Now().Unix()
+	now := o.clock.Now().Unix()
 	minAllowed := now - int64(o.proofValidity.Seconds())
 
-	for ts := range o.proofCache {
-		if ts < minAllowed {
-			delete(o.proofCache, ts)
-		}
-	}
+	o.proofCache.Prune(minAllowed)
 }
 
 // GetTimeWithProof returns the current time with a cryptographic proof
@@ -0,0 +1,181 @@
+package timeoracle
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock is a clock source set by the test
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func newTestOracle(t *testing.T, secret byte, clock ClockSource) *StandardTimeOracle {
+	t.Helper()
+
+	o, err := NewStandardTimeOracle(bytes.Repeat([]byte{secret}, 32), 5*time.Second, time.Minute, clock)
+	if err != nil {
+		t.Fatalf("NewStandardTimeOracle: %v", err)
+	}
+	return o
+}
+
+func TestStandardVerifyProof(t *testing.T) {
+	tests := []struct {
+		name    string
+		tamper  func(*TimeProof)
+		advance time.Duration
+		wantErr error
+	}{
+		{name: "fresh proof"},
+		{name: "within validity", advance: 30 * time.Second},
+		{name: "expired", advance: 2 * time.Minute, wantErr: ErrExpiredProof},
+		{name: "from the future", advance: -time.Minute, wantErr: ErrFutureTimestamp},
+		{name: "forged signature", tamper: func(p *TimeProof) { p.Signature[0] ^= 0xff }, wantErr: ErrInvalidProof},
+		{name: "moved timestamp", tamper: func(p *TimeProof) { p.Timestamp-- }, wantErr: ErrInvalidProof},
+		{name: "changed nonce", tamper: func(p *TimeProof) { p.Nonce++ }, wantErr: ErrInvalidProof},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+			o := newTestOracle(t, 1, clock)
+
+			proof, err := o.GenerateProof()
+			if err != nil {
+				t.Fatalf("GenerateProof: %v", err)
+			}
+			if tt.tamper != nil {
+				tt.tamper(proof)
+			}
+			clock.advance(tt.advance)
+
+			if err := o.VerifyProof(proof); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("VerifyProof error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGenerateProofIsStablePerSecond(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+	o := newTestOracle(t, 1, clock)
+
+	first, err := o.GenerateProof()
+	if err != nil {
+		t.Fatalf("GenerateProof: %v", err)
+	}
+	clock.advance(500 * time.Millisecond)
+	again, err := o.GenerateProof()
+	if err != nil {
+		t.Fatalf("GenerateProof: %v", err)
+	}
+	if again.Nonce != first.Nonce {
+		t.Fatal("two proofs issued for the same second")
+	}
+
+	clock.advance(time.Second)
+	next, err := o.GenerateProof()
+	if err != nil {
+		t.Fatalf("GenerateProof: %v", err)
+	}
+	if next.Timestamp != first.Timestamp+1 {
+		t.Fatalf("next proof timestamp = %d, want %d", next.Timestamp, first.Timestamp+1)
+	}
+}
+
+func TestMemoryProofCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewMemoryProofCache(2)
+	for _, ts := range []int64{1, 2} {
+		if err := c.Put(TimeProof{Timestamp: ts}); err != nil {
+			t.Fatalf("Put: %v", err)
+		}
+	}
+	c.Get(1)
+	if err := c.Put(TimeProof{Timestamp: 3}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	tests := []struct {
+		timestamp int64
+		want      bool
+	}{
+		{timestamp: 1, want: true},
+		{timestamp: 2, want: false},
+		{timestamp: 3, want: true},
+	}
+	for _, tt := range tests {
+		if _, got := c.Get(tt.timestamp); got != tt.want {
+			t.Fatalf("Get(%d) cached = %v, want %v", tt.timestamp, got, tt.want)
+		}
+	}
+	if c.Len() != 2 {
+		t.Fatalf("Len = %d, want 2", c.Len())
+	}
+
+	c.Prune(3)
+	if c.Len() != 1 {
+		t.Fatalf("Len after Prune = %d, want 1", c.Len())
+	}
+}
+
+func TestImportState(t *testing.T) {
+	tests := []struct {
+		name       string
+		secret     byte
+		advance    time.Duration
+		wantErr    error
+		wantCached bool
+	}{
+		{name: "standby with the same secret", secret: 1, wantCached: true},
+		{name: "expired proofs are skipped", secret: 1, advance: 2 * time.Minute},
+		{name: "different secret", secret: 2, wantErr: ErrStateMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+			primary := newTestOracle(t, 1, clock)
+			proof, err := primary.GenerateProof()
+			if err != nil {
+				t.Fatalf("GenerateProof: %v", err)
+			}
+			state, err := primary.ExportState()
+			if err != nil {
+				t.Fatalf("ExportState: %v", err)
+			}
+
+			clock.advance(tt.advance)
+			cache := NewMemoryProofCache(10)
+			standby := newTestOracle(t, tt.secret, clock)
+			standby.SetProofCache(cache)
+			if err := standby.ImportState(state); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ImportState error = %v, want %v", err, tt.wantErr)
+			}
+
+			cached, ok := cache.Get(proof.Timestamp)
+			if ok != tt.wantCached {
+				t.Fatalf("proof cached = %v, want %v", ok, tt.wantCached)
+			}
+			if ok && cached.Nonce != proof.Nonce {
+				t.Fatal("standby cached a different proof than the primary issued")
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package transaction
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// MinorUnitsPerUnit is the number of minor units (cents) in one unit of currency
+const MinorUnitsPerUnit = 100
+
+// minorUnitDecimals is the number of decimal places a minor unit represents
+const minorUnitDecimals = 2
+
+// maxExactMinorUnits is the largest minor unit count a float64 represents exactly
+const maxExactMinorUnits = 1 << 53
+
+// ErrPrecisionLoss is returned when an amount cannot be represented exactly in minor units
+var ErrPrecisionLoss = errors.New("amount has more precision than the smallest currency unit")
+
+// MinorUnits is an amount of currency counted in integer minor units. Balances
+// are kept in minor units so repeated arithmetic never accumulates rounding
+// errors; they are exposed as decimal numbers in JSON.
+type MinorUnits int64
+
+// ToMinorUnits converts a decimal amount to minor units, failing if the
+// amount is not a whole number of minor units or is out of range
+func ToMinorUnits(amount float64) (MinorUnits, error) {
+	if math.IsNaN(amount) || math.IsInf(amount, 0) {
+		return 0, ErrInvalidAmount
+	}
+
+	units := math.Round(amount * MinorUnitsPerUnit)
+	if math.Abs(units) > maxExactMinorUnits {
+		return 0, fmt.Errorf("%w: amount out of range", ErrInvalidAmount)
+	}
+	if MinorUnits(units).Float64() != amount {
+		return 0, ErrPrecisionLoss
+	}
+
+	return MinorUnits(units), nil
+}
+
+// RoundToMinorUnits rounds a computed amount, such as a percentage fee, to
+// the nearest minor unit
+func RoundToMinorUnits(amount float64) float64 {
+	return math.Round(amount*MinorUnitsPerUnit) / MinorUnitsPerUnit
+}
+
+// Float64 returns the amount as a decimal number of currency units
+func (m MinorUnits) Float64() float64 {
+	return float64(m) / MinorUnitsPerUnit
+}
+
+// MarshalJSON encodes the amount as a decimal number
+func (m MinorUnits) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.FormatFloat(m.Float64(), 'f', minorUnitDecimals, 64)), nil
+}
+
+// UnmarshalJSON decodes a decimal number, rejecting amounts that would lose precision
+func (m *MinorUnits) UnmarshalJSON(data []byte) error {
+	var amount float64
+	if err := json.Unmarshal(data, &amount); err != nil {
+		return err
+	}
+
+	units, err := ToMinorUnits(amount)
+	if err != nil {
+		return err
+	}
+
+	*m = units
+	return nil
+}
+
+// AmountMinorUnits returns the transaction amount in minor units
+func (tx *Transaction) AmountMinorUnits() (MinorUnits, error) {
+	return ToMinorUnits(tx.Amount)
+}
+
+// FeeMinorUnits returns the transaction fee in minor units
+func (tx *Transaction) FeeMinorUnits() (MinorUnits, error) {
+	return ToMinorUnits(tx.Fee)
+}
+
+// minorAmounts returns the amount and fee of a validated transaction in minor units
+func (tx *Transaction) minorAmounts() (amount, fee MinorUnits) {
+	amount, _ = tx.AmountMinorUnits()
+	fee, _ = tx.FeeMinorUnits()
+	return amount, fee
+}
@@ -0,0 +1,210 @@
+package transaction
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"strconv"
+	"sync/atomic"
+	"testing"
+)
+
+// testFeeAddress is the default fee collector of engines built by newTestEngine
+const testFeeAddress = "FEES"
+
+// testNonce makes every nonce issued by a test unique and increasing
+var testNonce atomic.Uint64
+
+// newTestEngine creates an engine using the system clock with the default fee
+// collector account already created
+func newTestEngine(t *testing.T) *TransactionEngine {
+	t.Helper()
+
+	e := NewTransactionEngine(nil, testFeeAddress)
+	newTestAccount(t, e, testFeeAddress)
+	return e
+}
+
+// newTestAccount creates an account and returns its signing key
+func newTestAccount(t *testing.T, e *TransactionEngine, address string) ed25519.PrivateKey {
+	t.Helper()
+
+	pubKey, privKey, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	if err := e.CreateAccount(address, pubKey); err != nil {
+		t.Fatalf("CreateAccount(%s): %v", address, err)
+	}
+	return privKey
+}
+
+// fund credits an account through a supply increase
+func fund(t *testing.T, e *TransactionEngine, address string, amount float64) {
+	t.Helper()
+
+	tx, err := NewTransaction("", address, amount, 0, SupplyIncrease, nextTestNonce(), "")
+	if err != nil {
+		t.Fatalf("NewTransaction: %v", err)
+	}
+	if err := e.ProcessTransaction(tx); err != nil {
+		t.Fatalf("fund %s: %v", address, err)
+	}
+}
+
+// signedTx creates a transaction signed by the sender's key
+func signedTx(t *testing.T, sender string, key ed25519.PrivateKey, receiver string, amount, fee float64, txType TransactionType) *Transaction {
+	t.Helper()
+
+	tx, err := NewTransaction(sender, receiver, amount, fee, txType, nextTestNonce(), "")
+	if err != nil {
+		t.Fatalf("NewTransaction: %v", err)
+	}
+	if err := tx.Sign(key); err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	return tx
+}
+
+func nextTestNonce() string {
+	return strconv.FormatUint(testNonce.Add(1), 10)
+}
+
+func balance(t *testing.T, e *TransactionEngine, address string) float64 {
+	t.Helper()
+
+	b, err := e.GetBalance(address)
+	if err != nil {
+		t.Fatalf("GetBalance(%s): %v", address, err)
+	}
+	return b
+}
+
+func TestFeesGoToConfiguredCollector(t *testing.T) {
+	tests := []struct {
+		name      string
+		txType    TransactionType
+		overrides map[TransactionType]string
+		collector string
+	}{
+		{name: "default collector", txType: Payment, collector: testFeeAddress},
+		{name: "per-type collector", txType: Payment, overrides: map[TransactionType]string{Payment: "PAYMENT_FEES"}, collector: "PAYMENT_FEES"},
+		{name: "other type uses default", txType: Payment, overrides: map[TransactionType]string{Withdrawal: "WITHDRAWAL_FEES"}, collector: testFeeAddress},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newTestEngine(t)
+			for txType, address := range tt.overrides {
+				newTestAccount(t, e, address)
+				e.SetFeeAddress(txType, address)
+			}
+
+			key := newTestAccount(t, e, "alice")
+			newTestAccount(t, e, "bob")
+			fund(t, e, "alice", 100)
+
+			if err := e.ProcessTransaction(signedTx(t, "alice", key, "bob", 10, 0.5, tt.txType)); err != nil {
+				t.Fatalf("ProcessTransaction: %v", err)
+			}
+
+			if got := balance(t, e, tt.collector); got != 0.5 {
+				t.Fatalf("collector %s balance = %v, want 0.5", tt.collector, got)
+			}
+			if got := balance(t, e, "alice"); got != 89.5 {
+				t.Fatalf("sender balance = %v, want 89.5", got)
+			}
+		})
+	}
+}
+
+func TestChangingFeeAddressRoutesNewFees(t *testing.T) {
+	e := newTestEngine(t)
+	newTestAccount(t, e, "NEW_FEES")
+	key := newTestAccount(t, e, "alice")
+	newTestAccount(t, e, "bob")
+	fund(t, e, "alice", 100)
+
+	if err := e.ProcessTransaction(signedTx(t, "alice", key, "bob", 10, 1, Payment)); err != nil {
+		t.Fatalf("first payment: %v", err)
+	}
+	e.SetFeeAddress(Payment, "NEW_FEES")
+	if err := e.ProcessTransaction(signedTx(t, "alice", key, "bob", 10, 2, Payment)); err != nil {
+		t.Fatalf("second payment: %v", err)
+	}
+
+	if got := balance(t, e, testFeeAddress); got != 1 {
+		t.Fatalf("old collector balance = %v, want 1", got)
+	}
+	if got := balance(t, e, "NEW_FEES"); got != 2 {
+		t.Fatalf("new collector balance = %v, want 2", got)
+	}
+}
+
+func TestMissingFeeCollectorFailsTransaction(t *testing.T) {
+	e := NewTransactionEngine(nil, "MISSING")
+	key := newTestAccount(t, e, "alice")
+	newTestAccount(t, e, "bob")
+	fund(t, e, "alice", 100)
+
+	tx := signedTx(t, "alice", key, "bob", 10, 1, Payment)
+	if err := e.ProcessTransaction(tx); !errors.Is(err, ErrFeeAccountNotFound) {
+		t.Fatalf("ProcessTransaction error = %v, want %v", err, ErrFeeAccountNotFound)
+	}
+	if tx.Status != Failed {
+		t.Fatalf("status = %s, want %s", tx.Status, Failed)
+	}
+	if got := balance(t, e, "alice"); got != 100 {
+		t.Fatalf("sender balance = %v, want 100", got)
+	}
+
+	// Transactions without a fee do not need a collector
+	if err := e.ProcessTransaction(signedTx(t, "alice", key, "bob", 10, 0, Payment)); err != nil {
+		t.Fatalf("fee-free payment: %v", err)
+	}
+}
+
+func TestProtectedAccountDebits(t *testing.T) {
+	tests := []struct {
+		name    string
+		sender  string
+		txType  TransactionType
+		wantErr error
+	}{
+		{name: "payment from reserve", sender: "RESERVE", txType: Payment, wantErr: ErrProtectedAccount},
+		{name: "withdrawal from reserve", sender: "RESERVE", txType: Withdrawal, wantErr: ErrProtectedAccount},
+		{name: "distribution from reserve", sender: "RESERVE", txType: Distribution},
+		{name: "distribution from user", sender: "alice", txType: Distribution, wantErr: ErrInvalidTransaction},
+		{name: "payment from user", sender: "alice", txType: Payment},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newTestEngine(t)
+			keys := map[string]ed25519.PrivateKey{
+				"RESERVE": newTestAccount(t, e, "RESERVE"),
+				"alice":   newTestAccount(t, e, "alice"),
+			}
+			newTestAccount(t, e, "bob")
+			e.SetProtectedAccounts([]string{"RESERVE", testFeeAddress})
+			fund(t, e, "RESERVE", 100)
+			fund(t, e, "alice", 100)
+
+			err := e.ProcessTransaction(signedTx(t, tt.sender, keys[tt.sender], "bob", 10, 0, tt.txType))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("ProcessTransaction error = %v, want %v", err, tt.wantErr)
+				}
+				if got := balance(t, e, tt.sender); got != 100 {
+					t.Fatalf("sender balance = %v, want 100", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ProcessTransaction: %v", err)
+			}
+			if got := balance(t, e, "bob"); got != 10 {
+				t.Fatalf("receiver balance = %v, want 10", got)
+			}
+		})
+	}
+}
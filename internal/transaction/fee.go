@@ -0,0 +1,44 @@
+package transaction
+
+// FeeSchedule defines how transaction fees are calculated
+type FeeSchedule interface {
+	// CalculateFee returns the fee charged for a transaction of the given type and amount
+	CalculateFee(txType TransactionType, amount float64) float64
+}
+
+// PercentageFeeSchedule charges a percentage of the amount with a minimum fee
+type PercentageFeeSchedule struct {
+	// Rate is the fraction of the amount charged as a fee (e.g. 0.001 for 0.1%)
+	Rate float64
+	// MinFee is the minimum fee charged for a non-zero amount
+	MinFee float64
+}
+
+// NewPercentageFeeSchedule creates a new percentage fee schedule
+func NewPercentageFeeSchedule(rate, minFee float64) *PercentageFeeSchedule {
+	return &PercentageFeeSchedule{
+		Rate:   rate,
+		MinFee: minFee,
+	}
+}
+
+// CalculateFee returns the fee for a transaction of the given type and amount
+func (s *PercentageFeeSchedule) CalculateFee(txType TransactionType, amount float64) float64 {
+	// Nothing to charge on empty amounts
+	if amount <= 0 {
+		return 0
+	}
+
+	// System transactions are not charged
+	if txType == Fee || txType == SupplyIncrease {
+		return 0
+	}
+
+	fee := amount * s.Rate
+	if fee < s.MinFee {
+		fee = s.MinFee
+	}
+
+	// Fees are charged in whole minor units
+	return RoundToMinorUnits(fee)
+}
@@ -0,0 +1,29 @@
+package transaction
+
+import "testing"
+
+func TestPercentageFeeSchedule(t *testing.T) {
+	schedule := NewPercentageFeeSchedule(0.001, 0.01)
+
+	tests := []struct {
+		name   string
+		txType TransactionType
+		amount float64
+		want   float64
+	}{
+		{name: "percentage of the amount", txType: Payment, amount: 1000, want: 1},
+		{name: "minimum fee", txType: Payment, amount: 2, want: 0.01},
+		{name: "rounded to minor units", txType: Withdrawal, amount: 123.456, want: 0.12},
+		{name: "zero amount", txType: Payment, amount: 0, want: 0},
+		{name: "supply increase", txType: SupplyIncrease, amount: 1000, want: 0},
+		{name: "fee transaction", txType: Fee, amount: 1000, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := schedule.CalculateFee(tt.txType, tt.amount); got != tt.want {
+				t.Fatalf("CalculateFee(%s, %v) = %v, want %v", tt.txType, tt.amount, got, tt.want)
+			}
+		})
+	}
+}
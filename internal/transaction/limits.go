@@ -0,0 +1,125 @@
+package transaction
+
+import (
+	"errors"
+	"fmt"
+)
+
+// secondsPerDay is the length of a daily spending window, which resets at UTC midnight
+const secondsPerDay = 24 * 60 * 60
+
+// ErrDailyLimitExceeded is returned when a transaction would exceed the sender's daily spending limit
+var ErrDailyLimitExceeded = errors.New("daily spending limit exceeded")
+
+// dailySpend tracks the outbound amount of an account within a UTC day
+type dailySpend struct {
+	day    int64
+	amount MinorUnits
+	txs    map[string]bool // IDs of the transactions counted in amount
+}
+
+// SetDailyLimit sets the default cap on the amount an account can send in
+// payments and withdrawals per UTC day. Zero disables the default limit.
+func (e *TransactionEngine) SetDailyLimit(limit float64) error {
+	if limit < 0 {
+		return fmt.Errorf("%w: daily limit must be non-negative", ErrInvalidAmount)
+	}
+	units, err := ToMinorUnits(limit)
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.dailyLimit = units
+	return nil
+}
+
+// SetAccountDailyLimit overrides the daily limit for an account. A limit of
+// zero blocks all outbound payments and withdrawals; a negative limit removes
+// the override so the default applies again.
+func (e *TransactionEngine) SetAccountDailyLimit(address string, limit float64) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if limit < 0 {
+		delete(e.accountLimits, address)
+		return nil
+	}
+	units, err := ToMinorUnits(limit)
+	if err != nil {
+		return err
+	}
+	e.accountLimits[address] = units
+	return nil
+}
+
+// DailySpent returns the amount an account has sent today
+func (e *TransactionEngine) DailySpent(address string) float64 {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	spend, exists := e.spending[address]
+	if !exists || spend.day != e.now()/secondsPerDay {
+		return 0
+	}
+	return spend.amount.Float64()
+}
+
+// dailyLimitFor returns the daily limit for an account and whether it has
+// one. An account override always applies, even when it is zero; a zero
+// default means no limit.
+func (e *TransactionEngine) dailyLimitFor(address string) (MinorUnits, bool) {
+	if limit, ok := e.accountLimits[address]; ok {
+		return limit, true
+	}
+	return e.dailyLimit, e.dailyLimit > 0
+}
+
+// checkDailyLimit reports whether a transaction keeps its sender within the daily limit
+func (e *TransactionEngine) checkDailyLimit(tx *Transaction) error {
+	limit, limited := e.dailyLimitFor(tx.Sender)
+	if !limited {
+		return nil
+	}
+
+	var spent MinorUnits
+	if spend, exists := e.spending[tx.Sender]; exists && spend.day == e.now()/secondsPerDay {
+		spent = spend.amount
+	}
+
+	amount, _ := tx.minorAmounts()
+	if spent+amount > limit {
+		return ErrDailyLimitExceeded
+	}
+	return nil
+}
+
+// recordSpend adds a transaction's amount to its sender's outbound total for today
+func (e *TransactionEngine) recordSpend(tx *Transaction) {
+	day := e.now() / secondsPerDay
+
+	spend, exists := e.spending[tx.Sender]
+	if !exists || spend.day != day {
+		spend = &dailySpend{day: day, txs: make(map[string]bool)}
+		e.spending[tx.Sender] = spend
+	}
+	amount, _ := tx.minorAmounts()
+	spend.amount += amount
+	spend.txs[tx.ID] = true
+}
+
+// releaseSpend removes a failed transaction's amount from its sender's
+// outbound total, if it was counted in the current window
+func (e *TransactionEngine) releaseSpend(tx *Transaction) {
+	spend, exists := e.spending[tx.Sender]
+	if !exists || !spend.txs[tx.ID] {
+		return
+	}
+	delete(spend.txs, tx.ID)
+	amount, _ := tx.minorAmounts()
+	spend.amount -= amount
+	if spend.amount < 0 {
+		spend.amount = 0
+	}
+}
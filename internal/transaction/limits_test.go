@@ -0,0 +1,176 @@
+package transaction
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDailyLimit(t *testing.T) {
+	tests := []struct {
+		name    string
+		limit   float64
+		amounts []float64
+		wantErr []error
+	}{
+		{
+			name:    "exactly at the limit after sums that are inexact in floats",
+			limit:   0.3,
+			amounts: []float64{0.1, 0.2},
+			wantErr: []error{nil, nil},
+		},
+		{
+			name:    "one minor unit over the limit",
+			limit:   0.3,
+			amounts: []float64{0.1, 0.2, 0.01},
+			wantErr: []error{nil, nil, ErrDailyLimitExceeded},
+		},
+		{
+			name:    "rejected spend is not counted",
+			limit:   10,
+			amounts: []float64{6, 5, 4},
+			wantErr: []error{nil, ErrDailyLimitExceeded, nil},
+		},
+		{
+			name:    "no limit",
+			amounts: []float64{50, 49},
+			wantErr: []error{nil, nil},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newTestEngine(t)
+			if err := e.SetDailyLimit(tt.limit); err != nil {
+				t.Fatalf("SetDailyLimit: %v", err)
+			}
+			key := newTestAccount(t, e, "alice")
+			newTestAccount(t, e, "bob")
+			fund(t, e, "alice", 100)
+
+			for i, amount := range tt.amounts {
+				err := e.ProcessTransaction(signedTx(t, "alice", key, "bob", amount, 0, Payment))
+				if !errors.Is(err, tt.wantErr[i]) {
+					t.Fatalf("payment %d of %v: error = %v, want %v", i, amount, err, tt.wantErr[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSetDailyLimitValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		limit   float64
+		wantErr error
+	}{
+		{name: "whole units", limit: 100},
+		{name: "minor units", limit: 0.05},
+		{name: "negative", limit: -1, wantErr: ErrInvalidAmount},
+		{name: "below a minor unit", limit: 0.001, wantErr: ErrPrecisionLoss},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newTestEngine(t)
+			if err := e.SetDailyLimit(tt.limit); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SetDailyLimit(%v) error = %v, want %v", tt.limit, err, tt.wantErr)
+			}
+			if err := e.SetAccountDailyLimit("alice", tt.limit); tt.limit >= 0 && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SetAccountDailyLimit(%v) error = %v, want %v", tt.limit, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAccountDailyLimitOverride(t *testing.T) {
+	e := newTestEngine(t)
+	if err := e.SetDailyLimit(100); err != nil {
+		t.Fatalf("SetDailyLimit: %v", err)
+	}
+	if err := e.SetAccountDailyLimit("alice", 5); err != nil {
+		t.Fatalf("SetAccountDailyLimit: %v", err)
+	}
+	key := newTestAccount(t, e, "alice")
+	newTestAccount(t, e, "bob")
+	fund(t, e, "alice", 100)
+
+	if err := e.ProcessTransaction(signedTx(t, "alice", key, "bob", 6, 0, Payment)); !errors.Is(err, ErrDailyLimitExceeded) {
+		t.Fatalf("payment over the override: error = %v, want %v", err, ErrDailyLimitExceeded)
+	}
+
+	// Removing the override restores the default
+	if err := e.SetAccountDailyLimit("alice", -1); err != nil {
+		t.Fatalf("SetAccountDailyLimit: %v", err)
+	}
+	if err := e.ProcessTransaction(signedTx(t, "alice", key, "bob", 6, 0, Payment)); err != nil {
+		t.Fatalf("payment under the default: %v", err)
+	}
+	if got := e.DailySpent("alice"); got != 6 {
+		t.Fatalf("DailySpent = %v, want 6", got)
+	}
+}
+
+func TestZeroAccountDailyLimit(t *testing.T) {
+	e := newTestEngine(t)
+	if err := e.SetAccountDailyLimit("alice", 0); err != nil {
+		t.Fatalf("SetAccountDailyLimit: %v", err)
+	}
+	key := newTestAccount(t, e, "alice")
+	bobKey := newTestAccount(t, e, "bob")
+	newTestAccount(t, e, "carol")
+	fund(t, e, "alice", 100)
+	fund(t, e, "bob", 100)
+
+	// A zero override blocks the account even though the default is unlimited
+	if err := e.ProcessTransaction(signedTx(t, "alice", key, "bob", 1, 0, Payment)); !errors.Is(err, ErrDailyLimitExceeded) {
+		t.Fatalf("payment under a zero override: error = %v, want %v", err, ErrDailyLimitExceeded)
+	}
+	if err := e.ProcessTransaction(signedTx(t, "bob", bobKey, "carol", 1, 0, Payment)); err != nil {
+		t.Fatalf("payment without an override: %v", err)
+	}
+}
+
+func TestRefundAmountMatchesExactly(t *testing.T) {
+	tests := []struct {
+		name    string
+		amount  float64
+		fee     float64
+		wantErr error
+	}{
+		{name: "full amount", amount: 0.3},
+		{name: "amount and fee split inexact in floats", amount: 0.2, fee: 0.1},
+		{name: "one minor unit short", amount: 0.29, wantErr: ErrInvalidRefund},
+		{name: "one minor unit over", amount: 0.3, fee: 0.01, wantErr: ErrInvalidRefund},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newTestEngine(t)
+			aliceKey := newTestAccount(t, e, "alice")
+			bobKey := newTestAccount(t, e, "bob")
+			fund(t, e, "alice", 100)
+			fund(t, e, "bob", 1)
+
+			original := signedTx(t, "alice", aliceKey, "bob", 0.3, 0, Payment)
+			if err := e.ProcessTransaction(original); err != nil {
+				t.Fatalf("original payment: %v", err)
+			}
+
+			refund, err := NewRefundTransaction(original, 0, nextTestNonce())
+			if err != nil {
+				t.Fatalf("NewRefundTransaction: %v", err)
+			}
+			refund.Amount, refund.Fee = tt.amount, tt.fee
+			if refund.Hash, err = refund.CalculateHash(); err != nil {
+				t.Fatalf("CalculateHash: %v", err)
+			}
+			if err := refund.Sign(bobKey); err != nil {
+				t.Fatalf("Sign: %v", err)
+			}
+
+			if err := e.ProcessTransaction(refund); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("refund error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package transaction
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// Memo encryption errors
+var (
+	ErrInvalidMemoKey = errors.New("invalid memo key")
+	ErrInvalidMemo    = errors.New("encrypted memo is malformed or was not encrypted for this key")
+)
+
+const (
+	// memoKeySize is the size of an X25519 public key
+	memoKeySize = 32
+
+	// memoNonceSize is the size of the AES-GCM nonce
+	memoNonceSize = 12
+
+	// memoOverhead is the number of bytes an encrypted memo adds to its
+	// plaintext: the ephemeral public key, the nonce and the GCM tag
+	memoOverhead = memoKeySize + memoNonceSize + 16
+)
+
+// fieldPrime is the prime 2^255 - 19 both curve25519 forms are defined over
+var fieldPrime = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(19))
+
+// EncryptMemo encrypts a transaction memo so only the holder of the
+// recipient's ed25519 private key can read it. The memo is sealed with
+// AES-256-GCM under a key agreed between a fresh X25519 key and the X25519
+// form of the recipient's key. The result is base64-encoded, ready to be
+// used as the description of a transaction with MemoEncrypted set.
+func EncryptMemo(recipientPubKey ed25519.PublicKey, plaintext string) (string, error) {
+	recipient, err := x25519PublicKey(recipientPubKey)
+	if err != nil {
+		return "", err
+	}
+
+	ephemeral, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate memo key: %w", err)
+	}
+
+	aead, err := memoCipher(ephemeral, recipient, ephemeral.PublicKey())
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, memoNonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate memo nonce: %w", err)
+	}
+
+	blob := append(ephemeral.PublicKey().Bytes(), nonce...)
+	blob = aead.Seal(blob, nonce, []byte(plaintext), nil)
+
+	return base64.StdEncoding.EncodeToString(blob), nil
+}
+
+// DecryptMemo decrypts a memo produced by EncryptMemo with the recipient's
+// ed25519 private key
+func DecryptMemo(privKey ed25519.PrivateKey, memo string) (string, error) {
+	recipient, err := x25519PrivateKey(privKey)
+	if err != nil {
+		return "", err
+	}
+
+	blob, err := base64.StdEncoding.DecodeString(memo)
+	if err != nil || len(blob) < memoOverhead {
+		return "", ErrInvalidMemo
+	}
+
+	ephemeral, err := ecdh.X25519().NewPublicKey(blob[:memoKeySize])
+	if err != nil {
+		return "", ErrInvalidMemo
+	}
+
+	aead, err := memoCipher(recipient, ephemeral, ephemeral)
+	if err != nil {
+		return "", ErrInvalidMemo
+	}
+
+	nonce := blob[memoKeySize : memoKeySize+memoNonceSize]
+	plaintext, err := aead.Open(nil, nonce, blob[memoKeySize+memoNonceSize:], nil)
+	if err != nil {
+		return "", ErrInvalidMemo
+	}
+
+	return string(plaintext), nil
+}
+
+// memoCipher derives the AES-GCM cipher for a memo from an X25519 key
+// agreement, binding the key to the ephemeral public key
+func memoCipher(priv *ecdh.PrivateKey, peer, ephemeral *ecdh.PublicKey) (cipher.AEAD, error) {
+	shared, err := priv.ECDH(peer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to agree memo key: %w", err)
+	}
+
+	key := sha256.Sum256(append(shared, ephemeral.Bytes()...))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// x25519PublicKey converts an ed25519 public key to its X25519 form using the
+// birational map u = (1 + y) / (1 - y) from the Edwards to the Montgomery curve
+func x25519PublicKey(pubKey ed25519.PublicKey) (*ecdh.PublicKey, error) {
+	if len(pubKey) != ed25519.PublicKeySize {
+		return nil, ErrInvalidMemoKey
+	}
+
+	// The key is the little-endian y coordinate with the sign of x in the top bit
+	y := new(big.Int).SetBytes(reverseBytes(pubKey))
+	y.SetBit(y, 255, 0)
+	if y.Cmp(fieldPrime) >= 0 {
+		return nil, ErrInvalidMemoKey
+	}
+
+	one := big.NewInt(1)
+	denominator := new(big.Int).Sub(one, y)
+	denominator.Mod(denominator, fieldPrime)
+	if denominator.Sign() == 0 {
+		return nil, ErrInvalidMemoKey
+	}
+
+	u := new(big.Int).Add(one, y)
+	u.Mul(u, denominator.ModInverse(denominator, fieldPrime))
+	u.Mod(u, fieldPrime)
+
+	encoded := make([]byte, memoKeySize)
+	u.FillBytes(encoded)
+
+	key, err := ecdh.X25519().NewPublicKey(reverseBytes(encoded))
+	if err != nil {
+		return nil, ErrInvalidMemoKey
+	}
+	return key, nil
+}
+
+// x25519PrivateKey converts an ed25519 private key to its X25519 form, the
+// scalar ed25519 derives from the key's seed
+func x25519PrivateKey(privKey ed25519.PrivateKey) (*ecdh.PrivateKey, error) {
+	if len(privKey) != ed25519.PrivateKeySize {
+		return nil, ErrInvalidMemoKey
+	}
+
+	h := sha512.Sum512(privKey.Seed())
+	key, err := ecdh.X25519().NewPrivateKey(h[:memoKeySize])
+	if err != nil {
+		return nil, ErrInvalidMemoKey
+	}
+	return key, nil
+}
+
+// reverseBytes returns a reversed copy of b, converting between the
+// little-endian curve encodings and big.Int's big-endian bytes
+func reverseBytes(b []byte) []byte {
+	reversed := make([]byte, len(b))
+	for i := range b {
+		reversed[len(b)-1-i] = b[i]
+	}
+	return reversed
+}
@@ -0,0 +1,142 @@
+package transaction
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func newMemoKey(t *testing.T) (ed25519.PublicKey, ed25519.PrivateKey) {
+	t.Helper()
+
+	pubKey, privKey, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	return pubKey, privKey
+}
+
+func TestMemoRoundTrip(t *testing.T) {
+	pubKey, privKey := newMemoKey(t)
+
+	tests := []struct {
+		name string
+		memo string
+	}{
+		{name: "empty", memo: ""},
+		{name: "ascii", memo: "invoice 1042"},
+		{name: "unicode", memo: "loyer de février ✓"},
+		{name: "long", memo: strings.Repeat("memo ", 200)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encrypted, err := EncryptMemo(pubKey, tt.memo)
+			if err != nil {
+				t.Fatalf("EncryptMemo: %v", err)
+			}
+			if tt.memo != "" && strings.Contains(encrypted, tt.memo) {
+				t.Fatalf("encrypted memo contains the plaintext")
+			}
+
+			decrypted, err := DecryptMemo(privKey, encrypted)
+			if err != nil {
+				t.Fatalf("DecryptMemo: %v", err)
+			}
+			if decrypted != tt.memo {
+				t.Fatalf("DecryptMemo = %q, want %q", decrypted, tt.memo)
+			}
+		})
+	}
+}
+
+func TestEncryptMemoIsRandomized(t *testing.T) {
+	pubKey, _ := newMemoKey(t)
+
+	first, err := EncryptMemo(pubKey, "rent")
+	if err != nil {
+		t.Fatalf("EncryptMemo: %v", err)
+	}
+	second, err := EncryptMemo(pubKey, "rent")
+	if err != nil {
+		t.Fatalf("EncryptMemo: %v", err)
+	}
+	if first == second {
+		t.Fatal("encrypting the same memo twice gave the same ciphertext")
+	}
+}
+
+func TestDecryptMemoRejects(t *testing.T) {
+	pubKey, privKey := newMemoKey(t)
+	_, thirdParty := newMemoKey(t)
+
+	encrypted, err := EncryptMemo(pubKey, "salary march")
+	if err != nil {
+		t.Fatalf("EncryptMemo: %v", err)
+	}
+	blob, _ := base64.StdEncoding.DecodeString(encrypted)
+	tampered := append([]byte(nil), blob...)
+	tampered[len(tampered)-1] ^= 0xff
+
+	tests := []struct {
+		name    string
+		privKey ed25519.PrivateKey
+		memo    string
+		want    error
+	}{
+		{name: "third party key", privKey: thirdParty, memo: encrypted, want: ErrInvalidMemo},
+		{name: "tampered ciphertext", privKey: privKey, memo: base64.StdEncoding.EncodeToString(tampered), want: ErrInvalidMemo},
+		{name: "truncated", privKey: privKey, memo: base64.StdEncoding.EncodeToString(blob[:memoOverhead-1]), want: ErrInvalidMemo},
+		{name: "not base64", privKey: privKey, memo: "not base64!", want: ErrInvalidMemo},
+		{name: "short private key", privKey: privKey[:32], memo: encrypted, want: ErrInvalidMemoKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecryptMemo(tt.privKey, tt.memo); !errors.Is(err, tt.want) {
+				t.Fatalf("DecryptMemo error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncryptMemoRejectsInvalidKeys(t *testing.T) {
+	tests := []struct {
+		name   string
+		pubKey ed25519.PublicKey
+	}{
+		{name: "short key", pubKey: make(ed25519.PublicKey, 31)},
+		// y = 1 is the identity point, which has no Montgomery form
+		{name: "identity point", pubKey: append([]byte{1}, make([]byte, 31)...)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := EncryptMemo(tt.pubKey, "memo"); !errors.Is(err, ErrInvalidMemoKey) {
+				t.Fatalf("EncryptMemo error = %v, want %v", err, ErrInvalidMemoKey)
+			}
+		})
+	}
+}
+
+func TestX25519KeysMatch(t *testing.T) {
+	for i := 0; i < 8; i++ {
+		pubKey, privKey := newMemoKey(t)
+
+		public, err := x25519PublicKey(pubKey)
+		if err != nil {
+			t.Fatalf("x25519PublicKey: %v", err)
+		}
+		private, err := x25519PrivateKey(privKey)
+		if err != nil {
+			t.Fatalf("x25519PrivateKey: %v", err)
+		}
+
+		if !bytes.Equal(private.PublicKey().Bytes(), public.Bytes()) {
+			t.Fatalf("converted public key %x does not match converted private key %x", public.Bytes(), private.PublicKey().Bytes())
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package transaction
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// Sequential nonce errors
+var (
+	ErrInvalidNonce    = errors.New("nonce must be a positive decimal integer")
+	ErrNonceOutOfOrder = errors.New("nonce is not above the account's last nonce")
+	ErrNonceExhausted  = errors.New("nonce sequence exhausted")
+)
+
+// NextNonce returns the sequential nonce following lastNonce. An empty or
+// non-numeric lastNonce starts the sequence at 1.
+func NextNonce(lastNonce string) (string, error) {
+	last, err := strconv.ParseUint(lastNonce, 10, 64)
+	if err != nil {
+		last = 0
+	}
+	if last == math.MaxUint64 {
+		return "", ErrNonceExhausted
+	}
+	return strconv.FormatUint(last+1, 10), nil
+}
+
+// parseSequentialNonce parses a sequential nonce
+func parseSequentialNonce(nonce string) (uint64, error) {
+	n, err := strconv.ParseUint(nonce, 10, 64)
+	if err != nil || n == 0 {
+		return 0, ErrInvalidNonce
+	}
+	return n, nil
+}
+
+// checkSequentialNonce verifies that a nonce continues the account's sequence.
+// Gaps are allowed so a client can skip nonces it failed to use.
+func (a *Account) checkSequentialNonce(nonce string) error {
+	n, err := parseSequentialNonce(nonce)
+	if err != nil {
+		return err
+	}
+	if n <= a.LastNonce {
+		return fmt.Errorf("%w: got %d, last was %d", ErrNonceOutOfOrder, n, a.LastNonce)
+	}
+	return nil
+}
+
+// SetSequentialNonces configures whether senders must use increasing decimal
+// nonces, such as those returned by NextNonce. When enabled, a transaction
+// whose nonce is not above the sender's last nonce is rejected even after the
+// nonce retention window has passed.
+func (e *TransactionEngine) SetSequentialNonces(enabled bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.sequentialNonces = enabled
+}
+
+// NextNonce reserves and returns the next sequential nonce for an account.
+// Each call hands out a new nonce, so concurrent callers never share one even
+// before their transactions are processed.
+func (e *TransactionEngine) NextNonce(address string) (string, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	account, exists := e.accounts[address]
+	if !exists {
+		return "", fmt.Errorf("account %s not found", address)
+	}
+
+	last := account.LastNonce
+	if account.NonceIssued > last {
+		last = account.NonceIssued
+	}
+	if last == math.MaxUint64 {
+		return "", ErrNonceExhausted
+	}
+
+	account.NonceIssued = last + 1
+	return strconv.FormatUint(account.NonceIssued, 10), nil
+}
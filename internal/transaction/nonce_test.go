@@ -0,0 +1,159 @@
+package transaction
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"math"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestNextNonce(t *testing.T) {
+	tests := []struct {
+		last    string
+		want    string
+		wantErr error
+	}{
+		{last: "", want: "1"},
+		{last: "not-a-number", want: "1"},
+		{last: "1", want: "2"},
+		{last: "41", want: "42"},
+		{last: strconv.FormatUint(math.MaxUint64-1, 10), want: strconv.FormatUint(math.MaxUint64, 10)},
+		{last: strconv.FormatUint(math.MaxUint64, 10), wantErr: ErrNonceExhausted},
+	}
+
+	for _, tt := range tests {
+		got, err := NextNonce(tt.last)
+		if !errors.Is(err, tt.wantErr) {
+			t.Fatalf("NextNonce(%q) error = %v, want %v", tt.last, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Fatalf("NextNonce(%q) = %q, want %q", tt.last, got, tt.want)
+		}
+	}
+}
+
+func TestEngineNextNonceReservesUniqueNonces(t *testing.T) {
+	e := newTestEngine(t)
+	newTestAccount(t, e, "alice")
+
+	const callers = 50
+	nonces := make([]string, callers)
+	var wg sync.WaitGroup
+	for i := range nonces {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			nonce, err := e.NextNonce("alice")
+			if err != nil {
+				t.Errorf("NextNonce: %v", err)
+			}
+			nonces[i] = nonce
+		}(i)
+	}
+	wg.Wait()
+
+	seen := make(map[string]bool, callers)
+	for _, nonce := range nonces {
+		if seen[nonce] {
+			t.Fatalf("nonce %s handed out twice", nonce)
+		}
+		seen[nonce] = true
+	}
+	for i := 1; i <= callers; i++ {
+		if !seen[strconv.Itoa(i)] {
+			t.Fatalf("nonce %d was skipped", i)
+		}
+	}
+}
+
+func TestEngineNextNonceExhausted(t *testing.T) {
+	e := newTestEngine(t)
+	newTestAccount(t, e, "alice")
+	e.accounts["alice"].LastNonce = math.MaxUint64
+
+	if _, err := e.NextNonce("alice"); !errors.Is(err, ErrNonceExhausted) {
+		t.Fatalf("NextNonce error = %v, want %v", err, ErrNonceExhausted)
+	}
+}
+
+func TestSequentialNonces(t *testing.T) {
+	tests := []struct {
+		name    string
+		nonce   string
+		wantErr error
+	}{
+		{name: "next in sequence", nonce: "3"},
+		{name: "gap", nonce: "10"},
+		{name: "replay", nonce: "2", wantErr: ErrDuplicateNonce},
+		{name: "below last", nonce: "1", wantErr: ErrNonceOutOfOrder},
+		{name: "zero", nonce: "0", wantErr: ErrInvalidNonce},
+		{name: "not a number", nonce: "abc", wantErr: ErrInvalidNonce},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newTestEngine(t)
+			e.SetSequentialNonces(true)
+			key := newTestAccount(t, e, "alice")
+			newTestAccount(t, e, "bob")
+			fund(t, e, "alice", 100)
+
+			// Forget the first nonce so only the sequence rejects its reuse
+			for _, nonce := range []string{"1", "2"} {
+				if err := e.ProcessTransaction(nonceTx(t, key, nonce)); err != nil {
+					t.Fatalf("nonce %s: %v", nonce, err)
+				}
+			}
+			delete(e.accounts["alice"].Nonces, "1")
+
+			err := e.ProcessTransaction(nonceTx(t, key, tt.nonce))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ProcessTransaction error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLastNonceTrackedOnlyInSequentialMode(t *testing.T) {
+	tests := []struct {
+		name       string
+		sequential bool
+		want       uint64
+	}{
+		{name: "sequential", sequential: true, want: 7},
+		{name: "random", sequential: false, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newTestEngine(t)
+			e.SetSequentialNonces(tt.sequential)
+			key := newTestAccount(t, e, "alice")
+			newTestAccount(t, e, "bob")
+			fund(t, e, "alice", 100)
+
+			if err := e.ProcessTransaction(nonceTx(t, key, "7")); err != nil {
+				t.Fatalf("ProcessTransaction: %v", err)
+			}
+			if got := e.accounts["alice"].LastNonce; got != tt.want {
+				t.Fatalf("LastNonce = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// nonceTx creates a payment from alice to bob with the given nonce
+func nonceTx(t *testing.T, key ed25519.PrivateKey, nonce string) *Transaction {
+	t.Helper()
+
+	tx, err := NewTransaction("alice", "bob", 1, 0, Payment, nonce, "")
+	if err != nil {
+		t.Fatalf("NewTransaction: %v", err)
+	}
+	if err := tx.Sign(key); err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	return tx
+}
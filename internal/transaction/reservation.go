@@ -0,0 +1,145 @@
+package transaction
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrReservationNotFound is returned when a reservation does not exist or has already been resolved
+var ErrReservationNotFound = errors.New("reservation not found")
+
+// Reservation holds part of an account balance until it is committed or released
+type Reservation struct {
+	ID        string     `json:"id"`
+	Address   string     `json:"address"`
+	Amount    MinorUnits `json:"amount"`
+	CreatedAt int64      `json:"created_at"`
+}
+
+// Reserve holds funds in an account so they cannot be spent by other
+// transactions until the reservation is committed or released
+func (e *TransactionEngine) Reserve(address string, amount float64) (string, error) {
+	if amount <= 0 {
+		return "", ErrInvalidAmount
+	}
+	units, err := ToMinorUnits(amount)
+	if err != nil {
+		return "", err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	account, exists := e.accounts[address]
+	if !exists {
+		return "", fmt.Errorf("account %s not found", address)
+	}
+
+	if account.Available() < units {
+		return "", ErrInsufficientFunds
+	}
+
+	reservation := &Reservation{
+		ID:        generateID(),
+		Address:   address,
+		Amount:    units,
+		CreatedAt: e.now(),
+	}
+
+	account.Held += units
+	e.reservations[reservation.ID] = reservation
+
+	return reservation.ID, nil
+}
+
+// Commit settles a reservation by processing tx, a signed payment from the
+// reserved account, as a normal transfer to its receiver, fees included. The
+// payment's amount and fee must fit in the reservation; whatever is left over
+// becomes available again. If the payment fails, the reservation is kept.
+func (e *TransactionEngine) Commit(reservationID string, tx *Transaction) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	reservation, exists := e.reservations[reservationID]
+	if !exists {
+		return ErrReservationNotFound
+	}
+	if tx.Type != Payment || tx.Sender != reservation.Address {
+		return fmt.Errorf("%w: a reservation is committed by a payment from its account", ErrInvalidTransaction)
+	}
+	if amount, fee := tx.minorAmounts(); amount+fee > reservation.Amount {
+		return fmt.Errorf("%w: payment exceeds the reserved amount", ErrInvalidAmount)
+	}
+
+	reservation, account, err := e.takeReservation(reservationID)
+	if err != nil {
+		return err
+	}
+
+	// Release the hold so the payment can spend the reserved funds
+	account.Held -= reservation.Amount
+	if err := e.processTransaction(tx); err != nil {
+		account.Held += reservation.Amount
+		e.reservations[reservationID] = reservation
+		return err
+	}
+
+	return nil
+}
+
+// Release cancels a reservation, making the held funds available again
+func (e *TransactionEngine) Release(reservationID string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	reservation, account, err := e.takeReservation(reservationID)
+	if err != nil {
+		return err
+	}
+
+	account.Held -= reservation.Amount
+
+	return nil
+}
+
+// GetReservation returns an outstanding reservation by ID
+func (e *TransactionEngine) GetReservation(reservationID string) (*Reservation, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	reservation, exists := e.reservations[reservationID]
+	if !exists {
+		return nil, ErrReservationNotFound
+	}
+
+	return reservation, nil
+}
+
+// GetAvailableBalance returns the available and held parts of an account balance
+func (e *TransactionEngine) GetAvailableBalance(address string) (available, held float64, err error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	account, exists := e.accounts[address]
+	if !exists {
+		return 0, 0, fmt.Errorf("account %s not found", address)
+	}
+
+	return account.Available().Float64(), account.Held.Float64(), nil
+}
+
+// takeReservation removes an outstanding reservation and returns it with its account
+func (e *TransactionEngine) takeReservation(reservationID string) (*Reservation, *Account, error) {
+	reservation, exists := e.reservations[reservationID]
+	if !exists {
+		return nil, nil, ErrReservationNotFound
+	}
+
+	account, exists := e.accounts[reservation.Address]
+	if !exists {
+		return nil, nil, fmt.Errorf("account %s not found", reservation.Address)
+	}
+
+	delete(e.reservations, reservationID)
+	return reservation, account, nil
+}
@@ -0,0 +1,122 @@
+package transaction
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"testing"
+)
+
+func TestCommitTransfersReservedFunds(t *testing.T) {
+	tests := []struct {
+		name          string
+		reserve       float64
+		amount        float64
+		fee           float64
+		wantAvailable float64
+	}{
+		{name: "whole reservation", reserve: 10, amount: 9, fee: 1, wantAvailable: 90},
+		{name: "part of the reservation", reserve: 10, amount: 5, fee: 0.5, wantAvailable: 94.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newTestEngine(t)
+			key := newTestAccount(t, e, "alice")
+			newTestAccount(t, e, "bob")
+			fund(t, e, "alice", 100)
+
+			id, err := e.Reserve("alice", tt.reserve)
+			if err != nil {
+				t.Fatalf("Reserve: %v", err)
+			}
+
+			tx := signedTx(t, "alice", key, "bob", tt.amount, tt.fee, Payment)
+			if err := e.Commit(id, tx); err != nil {
+				t.Fatalf("Commit: %v", err)
+			}
+
+			if tx.Status != Confirmed {
+				t.Fatalf("payment status = %s, want %s", tx.Status, Confirmed)
+			}
+			if got := balance(t, e, "bob"); got != tt.amount {
+				t.Fatalf("receiver balance = %v, want %v", got, tt.amount)
+			}
+			if got := balance(t, e, testFeeAddress); got != tt.fee {
+				t.Fatalf("fee collector balance = %v, want %v", got, tt.fee)
+			}
+			available, held, err := e.GetAvailableBalance("alice")
+			if err != nil {
+				t.Fatalf("GetAvailableBalance: %v", err)
+			}
+			if available != tt.wantAvailable || held != 0 {
+				t.Fatalf("sender available, held = %v, %v; want %v, 0", available, held, tt.wantAvailable)
+			}
+			if _, err := e.GetReservation(id); !errors.Is(err, ErrReservationNotFound) {
+				t.Fatalf("GetReservation after commit = %v, want %v", err, ErrReservationNotFound)
+			}
+		})
+	}
+}
+
+func TestCommitRejectsMismatchedPayment(t *testing.T) {
+	tests := []struct {
+		name    string
+		sender  string
+		amount  float64
+		fee     float64
+		txType  TransactionType
+		wantErr error
+	}{
+		{name: "exceeds reservation", sender: "alice", amount: 10, fee: 0.5, txType: Payment, wantErr: ErrInvalidAmount},
+		{name: "other sender", sender: "carol", amount: 5, txType: Payment, wantErr: ErrInvalidTransaction},
+		{name: "not a payment", sender: "alice", amount: 5, txType: Withdrawal, wantErr: ErrInvalidTransaction},
+		{name: "payment fails", sender: "alice", amount: 5, txType: Payment, wantErr: ErrInvalidSignature},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newTestEngine(t)
+			keys := map[string]ed25519.PrivateKey{
+				"alice": newTestAccount(t, e, "alice"),
+				"carol": newTestAccount(t, e, "carol"),
+			}
+			newTestAccount(t, e, "bob")
+			fund(t, e, "alice", 100)
+			fund(t, e, "carol", 100)
+
+			id, err := e.Reserve("alice", 10)
+			if err != nil {
+				t.Fatalf("Reserve: %v", err)
+			}
+
+			tx := signedTx(t, tt.sender, keys[tt.sender], "bob", tt.amount, tt.fee, tt.txType)
+			if errors.Is(tt.wantErr, ErrInvalidSignature) {
+				tx.Signature = nil
+			}
+			if err := e.Commit(id, tx); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Commit error = %v, want %v", err, tt.wantErr)
+			}
+
+			// The reservation still holds the funds
+			if _, err := e.GetReservation(id); err != nil {
+				t.Fatalf("GetReservation after failed commit: %v", err)
+			}
+			if _, held, _ := e.GetAvailableBalance("alice"); held != 10 {
+				t.Fatalf("held = %v, want 10", held)
+			}
+			if got := balance(t, e, "bob"); got != 0 {
+				t.Fatalf("receiver balance = %v, want 0", got)
+			}
+		})
+	}
+}
+
+func TestCommitUnknownReservation(t *testing.T) {
+	e := newTestEngine(t)
+	key := newTestAccount(t, e, "alice")
+	newTestAccount(t, e, "bob")
+
+	if err := e.Commit("missing", signedTx(t, "alice", key, "bob", 1, 0, Payment)); !errors.Is(err, ErrReservationNotFound) {
+		t.Fatalf("Commit error = %v, want %v", err, ErrReservationNotFound)
+	}
+}
@@ -0,0 +1,235 @@
+package transaction
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// snapshotVersion is the format version written by Snapshot
+const snapshotVersion = 1
+
+// ErrInvalidSnapshot is returned when a snapshot cannot be restored
+var ErrInvalidSnapshot = errors.New("invalid snapshot")
+
+// engineSnapshot is the serialized state of a transaction engine
+type engineSnapshot struct {
+	Version      int                     `json:"version"`
+	TakenAt      int64                   `json:"taken_at"`
+	Accounts     map[string]*Account     `json:"accounts"`
+	Transactions map[string]*Transaction `json:"transactions"`
+	Reservations map[string]*Reservation `json:"reservations,omitempty"`
+}
+
+// Snapshot serializes the engine's accounts, including balances and used
+// nonces, its transactions and its open reservations
+func (e *TransactionEngine) Snapshot() ([]byte, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	data, err := json.Marshal(engineSnapshot{
+		Version:      snapshotVersion,
+		TakenAt:      e.now(),
+		Accounts:     e.accounts,
+		Transactions: e.transactions,
+		Reservations: e.reservations,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize snapshot: %w", err)
+	}
+
+	return data, nil
+}
+
+// Restore replaces the engine's accounts, transactions and reservations with
+// those in a snapshot. Transaction hashes are recomputed and the snapshot is
+// rejected if any applied transaction has been altered. Configuration such as
+// fee addresses, limits and protected accounts is left unchanged.
+func (e *TransactionEngine) Restore(data []byte) error {
+	var snap engineSnapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSnapshot, err)
+	}
+	if snap.Version != snapshotVersion {
+		return fmt.Errorf("%w: unsupported version %d", ErrInvalidSnapshot, snap.Version)
+	}
+
+	accounts := snap.Accounts
+	if accounts == nil {
+		accounts = make(map[string]*Account)
+	}
+	for address, account := range accounts {
+		if account == nil || account.Address != address {
+			return fmt.Errorf("%w: account %s does not match its key", ErrInvalidSnapshot, address)
+		}
+		if account.Nonces == nil {
+			account.Nonces = make(map[string]int64)
+		}
+		// Apply the checks of SetMultiSigPolicy; bad keys would panic on verification
+		if account.MultiSig != nil {
+			if err := account.MultiSig.Validate(); err != nil {
+				return fmt.Errorf("%w: account %s: %w", ErrInvalidSnapshot, address, err)
+			}
+		}
+	}
+
+	transactions := snap.Transactions
+	if transactions == nil {
+		transactions = make(map[string]*Transaction)
+	}
+	for id, tx := range transactions {
+		if tx == nil || tx.ID != id {
+			return fmt.Errorf("%w: transaction %s does not match its key", ErrInvalidSnapshot, id)
+		}
+
+		// Failed transactions are kept as submitted, so only applied ones must hash correctly
+		if tx.Status == Failed {
+			continue
+		}
+		hash, err := tx.CalculateHash()
+		if err != nil {
+			return err
+		}
+		if hash != tx.Hash {
+			return fmt.Errorf("%w: transaction %s hash is invalid", ErrInvalidSnapshot, id)
+		}
+	}
+
+	reservations := snap.Reservations
+	if reservations == nil {
+		reservations = make(map[string]*Reservation)
+	}
+	for id, reservation := range reservations {
+		if reservation == nil || reservation.ID != id {
+			return fmt.Errorf("%w: reservation %s does not match its key", ErrInvalidSnapshot, id)
+		}
+		if _, exists := accounts[reservation.Address]; !exists {
+			return fmt.Errorf("%w: reservation %s references unknown account %s", ErrInvalidSnapshot, id, reservation.Address)
+		}
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.accounts = accounts
+	e.transactions = make(map[string]*Transaction, len(transactions))
+	e.reservations = reservations
+	e.refunds = make(map[string]string)
+	e.byAddress = make(map[string][]string)
+	e.spending = make(map[string]*dailySpend)
+
+	// Rebuild the indexes in processing order
+	ordered := make([]*Transaction, 0, len(transactions))
+	for _, tx := range transactions {
+		ordered = append(ordered, tx)
+	}
+	sort.Slice(ordered, func(i, j int) bool {
+		if ordered[i].Timestamp != ordered[j].Timestamp {
+			return ordered[i].Timestamp < ordered[j].Timestamp
+		}
+		return ordered[i].ID < ordered[j].ID
+	})
+
+	today := e.now() / secondsPerDay
+	for _, tx := range ordered {
+		e.storeTransaction(tx)
+		if tx.Status == Failed {
+			continue
+		}
+
+		if tx.Type == Refund {
+			e.refunds[tx.RefundOf] = tx.ID
+		}
+		if (tx.Type == Payment || tx.Type == Withdrawal) && tx.Timestamp/secondsPerDay == today {
+			e.recordSpend(tx)
+		}
+	}
+
+	return nil
+}
+
+// SaveSnapshot writes a snapshot of the engine to path, replacing any previous
+// snapshot atomically
+func (e *TransactionEngine) SaveSnapshot(path string) error {
+	data, err := e.Snapshot()
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace snapshot: %w", err)
+	}
+
+	return nil
+}
+
+// LoadSnapshot restores the engine from the snapshot at path. It reports
+// whether a snapshot was found; a missing file leaves the engine unchanged.
+func (e *TransactionEngine) LoadSnapshot(path string) (bool, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+
+	if err := e.Restore(data); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// StartSnapshots saves a snapshot to path once per interval in the background
+// until ctx is done. Failed saves are passed to onError, if set, and retried
+// on the next interval.
+func (e *TransactionEngine) StartSnapshots(ctx context.Context, path string, interval time.Duration, onError func(error)) error {
+	if path == "" {
+		return errors.New("snapshot path cannot be empty")
+	}
+	if interval <= 0 {
+		return errors.New("snapshot interval must be positive")
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := e.SaveSnapshot(path); err != nil && onError != nil {
+					onError(err)
+				}
+			}
+		}
+	}()
+
+	return nil
+}
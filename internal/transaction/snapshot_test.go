@@ -0,0 +1,89 @@
+package transaction
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestRestoreValidatesMultiSigPolicy(t *testing.T) {
+	key := func() ed25519.PublicKey {
+		pub, _, err := ed25519.GenerateKey(nil)
+		if err != nil {
+			t.Fatalf("GenerateKey: %v", err)
+		}
+		return pub
+	}
+	first, second := key(), key()
+
+	tests := []struct {
+		name    string
+		policy  *MultiSigPolicy
+		wantErr error
+	}{
+		{name: "valid policy", policy: &MultiSigPolicy{PublicKeys: []ed25519.PublicKey{first, second}, Threshold: 2}},
+		{name: "short key", policy: &MultiSigPolicy{PublicKeys: []ed25519.PublicKey{first, second[:16]}, Threshold: 1}, wantErr: ErrInvalidSnapshot},
+		{name: "threshold above key count", policy: &MultiSigPolicy{PublicKeys: []ed25519.PublicKey{first}, Threshold: 2}, wantErr: ErrInvalidSnapshot},
+		{name: "zero threshold", policy: &MultiSigPolicy{PublicKeys: []ed25519.PublicKey{first}}, wantErr: ErrInvalidSnapshot},
+		{name: "duplicate keys", policy: &MultiSigPolicy{PublicKeys: []ed25519.PublicKey{first, first}, Threshold: 2}, wantErr: ErrInvalidSnapshot},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := newTestEngine(t)
+			newTestAccount(t, source, "vault")
+			source.accounts["vault"].MultiSig = tt.policy
+			data, err := source.Snapshot()
+			if err != nil {
+				t.Fatalf("Snapshot: %v", err)
+			}
+
+			e := newTestEngine(t)
+			err = e.Restore(data)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Restore error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if !errors.Is(err, ErrInvalidMultiSigPolicy) {
+					t.Fatalf("Restore error = %v, want it to wrap %v", err, ErrInvalidMultiSigPolicy)
+				}
+				if _, err := e.GetAccount("vault"); err == nil {
+					t.Fatal("rejected snapshot replaced the engine's accounts")
+				}
+				return
+			}
+
+			account, err := e.GetAccount("vault")
+			if err != nil {
+				t.Fatalf("GetAccount: %v", err)
+			}
+			if account.MultiSig == nil || account.MultiSig.Threshold != 2 {
+				t.Fatalf("restored policy = %+v, want threshold 2", account.MultiSig)
+			}
+		})
+	}
+}
+
+func TestRestoreRejectsMalformedSnapshot(t *testing.T) {
+	tests := []struct {
+		name string
+		snap engineSnapshot
+	}{
+		{name: "unsupported version", snap: engineSnapshot{Version: snapshotVersion + 1}},
+		{name: "account under another key", snap: engineSnapshot{Version: snapshotVersion, Accounts: map[string]*Account{"alice": {Address: "bob"}}}},
+		{name: "nil transaction", snap: engineSnapshot{Version: snapshotVersion, Transactions: map[string]*Transaction{"tx": nil}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.snap)
+			if err != nil {
+				t.Fatalf("Marshal: %v", err)
+			}
+			if err := newTestEngine(t).Restore(data); !errors.Is(err, ErrInvalidSnapshot) {
+				t.Fatalf("Restore error = %v, want %v", err, ErrInvalidSnapshot)
+			}
+		})
+	}
+}
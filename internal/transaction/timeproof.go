@@ -0,0 +1,49 @@
+package transaction
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Time proof errors
+var (
+	ErrTimeProofRequired = errors.New("transaction requires a time proof")
+	ErrInvalidTimeProof  = errors.New("invalid time proof")
+)
+
+// SetTimeProofRequired replaces the set of transaction types that must carry a
+// time proof. Proofs on other types are still verified when present.
+func (e *TransactionEngine) SetTimeProofRequired(types []TransactionType) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.proofRequired = make(map[TransactionType]bool, len(types))
+	for _, txType := range types {
+		e.proofRequired[txType] = true
+	}
+}
+
+// verifyTimeProof checks a transaction's time proof against the time oracle.
+// The proof must be valid and vouch for a time close to the transaction's timestamp.
+func (e *TransactionEngine) verifyTimeProof(tx *Transaction) error {
+	if tx.TimeProof == nil {
+		if e.proofRequired[tx.Type] {
+			return ErrTimeProofRequired
+		}
+		return nil
+	}
+
+	if e.timeOracle == nil {
+		return fmt.Errorf("%w: no time oracle configured", ErrInvalidTimeProof)
+	}
+	if err := e.timeOracle.VerifyProof(tx.TimeProof); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidTimeProof, err)
+	}
+
+	drift := tx.TimeProof.Timestamp - tx.Timestamp
+	if drift < -maxFutureSkew || drift > maxFutureSkew {
+		return fmt.Errorf("%w: proof time does not match transaction timestamp", ErrInvalidTimeProof)
+	}
+
+	return nil
+}
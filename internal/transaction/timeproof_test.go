@@ -0,0 +1,83 @@
+package transaction
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cmatc13/stathera/internal/timeoracle"
+)
+
+// proofOracle is a time oracle on the system clock that accepts only proofs
+// signed "valid"
+type proofOracle struct{}
+
+func (proofOracle) Now() int64                     { return time.Now().Unix() }
+func (proofOracle) Validate(timestamp int64) error { return nil }
+
+func (proofOracle) GenerateProof() (*timeoracle.TimeProof, error) {
+	return &timeoracle.TimeProof{Timestamp: time.Now().Unix(), Signature: []byte("valid")}, nil
+}
+
+func (proofOracle) VerifyProof(proof *timeoracle.TimeProof) error {
+	if string(proof.Signature) != "valid" {
+		return errors.New("bad signature")
+	}
+	return nil
+}
+
+func (o proofOracle) GetTimeWithProof() (int64, *timeoracle.TimeProof, error) {
+	proof, err := o.GenerateProof()
+	return proof.Timestamp, proof, err
+}
+
+func TestProcessTransactionVerifiesTimeProof(t *testing.T) {
+	tests := []struct {
+		name     string
+		oracle   timeoracle.TimeOracle
+		required bool
+		proof    func(tx *Transaction) *timeoracle.TimeProof
+		wantErr  error
+	}{
+		{name: "no proof", oracle: proofOracle{}},
+		{name: "missing required proof", oracle: proofOracle{}, required: true, wantErr: ErrTimeProofRequired},
+		{name: "valid proof", oracle: proofOracle{}, required: true, proof: func(tx *Transaction) *timeoracle.TimeProof {
+			return &timeoracle.TimeProof{Timestamp: tx.Timestamp, Signature: []byte("valid")}
+		}},
+		{name: "forged proof", oracle: proofOracle{}, proof: func(tx *Transaction) *timeoracle.TimeProof {
+			return &timeoracle.TimeProof{Timestamp: tx.Timestamp, Signature: []byte("forged")}
+		}, wantErr: ErrInvalidTimeProof},
+		{name: "proof for another time", oracle: proofOracle{}, proof: func(tx *Transaction) *timeoracle.TimeProof {
+			return &timeoracle.TimeProof{Timestamp: tx.Timestamp - 2*maxFutureSkew, Signature: []byte("valid")}
+		}, wantErr: ErrInvalidTimeProof},
+		{name: "proof without an oracle", proof: func(tx *Transaction) *timeoracle.TimeProof {
+			return &timeoracle.TimeProof{Timestamp: tx.Timestamp, Signature: []byte("valid")}
+		}, wantErr: ErrInvalidTimeProof},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewTransactionEngine(tt.oracle, testFeeAddress)
+			newTestAccount(t, e, testFeeAddress)
+			key := newTestAccount(t, e, "alice")
+			newTestAccount(t, e, "bob")
+			fund(t, e, "alice", 100)
+			if tt.required {
+				e.SetTimeProofRequired([]TransactionType{Payment})
+			}
+
+			tx := signedTx(t, "alice", key, "bob", 10, 0.1, Payment)
+			if tt.proof != nil {
+				tx.TimeProof = tt.proof(tx)
+			}
+
+			err := e.ProcessTransaction(tx)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ProcessTransaction = %v, want %v", err, tt.wantErr)
+			}
+			if want := 100.0; err != nil && balance(t, e, "alice") != want {
+				t.Fatalf("sender balance = %v after a rejected transaction, want %v", balance(t, e, "alice"), want)
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ package transaction
 import (
 	"crypto/ed25519"
 	"crypto/sha256"
+	"encoding/base64"
 	"encoding/hex"
 	"errors"
 	"fmt"
@@ -21,8 +22,25 @@ var (
 	ErrInsufficientFunds  = errors.New("insufficient funds")
 	ErrInvalidTransaction = errors.New("invalid transaction")
 	ErrDuplicateNonce     = errors.New("duplicate nonce")
+	ErrStaleTransaction   = errors.New("transaction timestamp is outside the nonce retention window")
+	ErrTransactionExpired = errors.New("transaction has expired")
+	ErrNotYetValid        = errors.New("transaction timestamp is in the future")
+	ErrProtectedAccount   = errors.New("protected account can only be debited by a distribution")
+	ErrFeeAccountNotFound = errors.New("fee collector account not found")
+
+	ErrInsufficientSignatures = errors.New("not enough valid signatures")
+	ErrInvalidMultiSigPolicy  = errors.New("invalid multisig policy")
+
+	ErrAlreadyRefunded = errors.New("transaction has already been refunded")
+	ErrInvalidRefund   = errors.New("invalid refund")
 )
 
+// maxFutureSkew is how far ahead of the time oracle, in seconds, a transaction timestamp may be
+const maxFutureSkew = 5 * 60
+
+// DefaultNonceRetention is how long used nonces are remembered for replay protection
+const DefaultNonceRetention = 24 * time.Hour
+
 // TransactionType defines the type of transaction
 type TransactionType string
 
@@ -37,6 +55,10 @@ const (
 	Fee TransactionType = "FEE"
 	// SupplyIncrease represents new coins from inflation
 	SupplyIncrease TransactionType = "SUPPLY_INCREASE"
+	// Refund returns a confirmed payment from its receiver to its sender
+	Refund TransactionType = "REFUND"
+	// Distribution pays out funds from a protected system account
+	Distribution TransactionType = "DISTRIBUTION"
 )
 
 // TransactionStatus defines the status of a transaction
@@ -55,19 +77,25 @@ const (
 
 // Transaction represents a transfer of funds between addresses
 type Transaction struct {
-	ID          string                `json:"id"`
-	Sender      string                `json:"sender"`
-	Receiver    string                `json:"receiver"`
-	Amount      float64               `json:"amount"`
-	Fee         float64               `json:"fee"`
-	Type        TransactionType       `json:"type"`
-	Status      TransactionStatus     `json:"status"`
-	Nonce       string                `json:"nonce"`
-	Signature   []byte                `json:"signature"`
-	Timestamp   int64                 `json:"timestamp"`
-	TimeProof   *timeoracle.TimeProof `json:"time_proof,omitempty"`
-	Description string                `json:"description,omitempty"`
-	Hash        string                `json:"hash"`
+	ID            string                `json:"id"`
+	Sender        string                `json:"sender"`
+	Receiver      string                `json:"receiver"`
+	Amount        float64               `json:"amount"`
+	Fee           float64               `json:"fee"`
+	Type          TransactionType       `json:"type"`
+	Status        TransactionStatus     `json:"status"`
+	Nonce         string                `json:"nonce"`
+	Signature     []byte                `json:"signature"`
+	Signatures    [][]byte              `json:"signatures,omitempty"`
+	Timestamp     int64                 `json:"timestamp"`
+	ValidUntil    int64                 `json:"valid_until,omitempty"`
+	RefundOf      string                `json:"refund_of,omitempty"`
+	TimeProof     *timeoracle.TimeProof `json:"time_proof,omitempty"`
+	Description   string                `json:"description,omitempty"`
+	MemoEncrypted bool                  `json:"memo_encrypted,omitempty"`
+	ExternalRef   string                `json:"external_ref,omitempty"`
+	Withdrawal    WithdrawalStatus      `json:"withdrawal_status,omitempty"`
+	Hash          string                `json:"hash"`
 }
 
 // NewTransaction creates a new transaction without signature
@@ -99,11 +127,67 @@ func NewTransaction(sender, receiver string, amount, fee float64, txType Transac
 	return tx, nil
 }
 
+// NewRefundTransaction creates an unsigned refund of a confirmed payment. The
+// original receiver pays back the original amount; fee is deducted from what
+// the original sender receives.
+func NewRefundTransaction(original *Transaction, fee float64, nonce string) (*Transaction, error) {
+	if original.Type != Payment {
+		return nil, fmt.Errorf("%w: only payments can be refunded", ErrInvalidRefund)
+	}
+
+	tx := &Transaction{
+		ID:        generateID(),
+		Sender:    original.Receiver,
+		Receiver:  original.Sender,
+		Amount:    RoundToMinorUnits(original.Amount - fee),
+		Fee:       fee,
+		Type:      Refund,
+		Status:    Pending,
+		Nonce:     nonce,
+		Timestamp: time.Now().Unix(),
+		RefundOf:  original.ID,
+	}
+	if tx.Amount <= 0 || fee < 0 {
+		return nil, ErrInvalidAmount
+	}
+
+	hash, err := tx.CalculateHash()
+	if err != nil {
+		return nil, err
+	}
+	tx.Hash = hash
+
+	return tx, nil
+}
+
+// SetValidUntil sets the Unix time after which the transaction can no longer
+// be applied and recalculates the hash. It must be called before signing.
+func (tx *Transaction) SetValidUntil(validUntil int64) error {
+	tx.ValidUntil = validUntil
+
+	hash, err := tx.CalculateHash()
+	if err != nil {
+		return err
+	}
+	tx.Hash = hash
+
+	return nil
+}
+
 // SignableData returns the data that should be signed
 func (tx *Transaction) SignableData() ([]byte, error) {
 	// Create a composite string of transaction data
 	signData := fmt.Sprintf("%s|%s|%s|%.8f|%.8f|%s|%s|%d",
 		tx.ID, tx.Sender, tx.Receiver, tx.Amount, tx.Fee, tx.Type, tx.Nonce, tx.Timestamp)
+
+	// Only include the expiry when set so existing signatures remain valid
+	if tx.ValidUntil != 0 {
+		signData += fmt.Sprintf("|%d", tx.ValidUntil)
+	}
+	if tx.RefundOf != "" {
+		signData += "|refund:" + tx.RefundOf
+	}
+
 	return []byte(signData), nil
 }
 
@@ -113,6 +197,17 @@ func (tx *Transaction) CalculateHash() (string, error) {
 	hashData := fmt.Sprintf("%s|%s|%s|%.8f|%.8f|%s|%s|%d|%s",
 		tx.ID, tx.Sender, tx.Receiver, tx.Amount, tx.Fee, tx.Type, tx.Nonce, tx.Timestamp, tx.Description)
 
+	// Only include the expiry when set so existing hashes remain valid
+	if tx.ValidUntil != 0 {
+		hashData += fmt.Sprintf("|%d", tx.ValidUntil)
+	}
+	if tx.RefundOf != "" {
+		hashData += "|refund:" + tx.RefundOf
+	}
+	if tx.MemoEncrypted {
+		hashData += "|memo:encrypted"
+	}
+
 	// Calculate SHA256 hash
 	h := sha256.Sum256([]byte(hashData))
 	return hex.EncodeToString(h[:]), nil
@@ -129,6 +224,46 @@ func (tx *Transaction) Sign(privateKey ed25519.PrivateKey) error {
 	return nil
 }
 
+// AddSignature adds a signature from one of the keys of a multisig account
+func (tx *Transaction) AddSignature(privateKey ed25519.PrivateKey) error {
+	signData, err := tx.SignableData()
+	if err != nil {
+		return err
+	}
+
+	tx.Signatures = append(tx.Signatures, ed25519.Sign(privateKey, signData))
+	return nil
+}
+
+// VerifyMultiSig checks that the transaction carries valid signatures from at
+// least the policy's threshold of distinct keys. Each key counts once, however
+// many of the signatures it produced.
+func (tx *Transaction) VerifyMultiSig(policy *MultiSigPolicy) (bool, error) {
+	if len(tx.Signatures) == 0 {
+		return false, ErrInvalidSignature
+	}
+
+	signData, err := tx.SignableData()
+	if err != nil {
+		return false, err
+	}
+
+	signed := make([]bool, len(policy.PublicKeys))
+	signers := 0
+	for _, sig := range tx.Signatures {
+		for i, key := range policy.PublicKeys {
+			if signed[i] || !ed25519.Verify(key, signData, sig) {
+				continue
+			}
+			signed[i] = true
+			signers++
+			break
+		}
+	}
+
+	return signers >= policy.Threshold, nil
+}
+
 // Verify checks if the transaction signature is valid
 func (tx *Transaction) Verify(publicKey ed25519.PublicKey) (bool, error) {
 	if len(tx.Signature) == 0 {
@@ -150,10 +285,37 @@ func (tx *Transaction) Validate() error {
 		return ErrInvalidAmount
 	}
 
+	// Amounts must be whole minor units so balances stay exact
+	if _, err := tx.AmountMinorUnits(); err != nil {
+		return err
+	}
+	if _, err := tx.FeeMinorUnits(); err != nil {
+		return err
+	}
+
 	if tx.Sender == tx.Receiver && tx.Type == Payment {
 		return errors.New("sender and receiver cannot be the same for payment transactions")
 	}
 
+	if tx.MemoEncrypted {
+		// The memo is opaque to the engine, but it must at least be well-formed
+		blob, err := base64.StdEncoding.DecodeString(tx.Description)
+		if err != nil {
+			return errors.New("encrypted memo is not valid base64")
+		}
+		if len(blob) < memoOverhead {
+			return ErrInvalidMemo
+		}
+	}
+
+	if tx.Type == Refund && tx.RefundOf == "" {
+		return fmt.Errorf("%w: refund must reference the original transaction", ErrInvalidRefund)
+	}
+
+	if tx.ValidUntil != 0 && tx.ValidUntil < tx.Timestamp {
+		return errors.New("transaction valid_until cannot be before its timestamp")
+	}
+
 	// Validate hash
 	calculatedHash, err := tx.CalculateHash()
 	if err != nil {
@@ -167,13 +329,43 @@ func (tx *Transaction) Validate() error {
 	return nil
 }
 
+// MultiSigPolicy requires Threshold of PublicKeys to sign an account's transactions
+type MultiSigPolicy struct {
+	PublicKeys []ed25519.PublicKey `json:"public_keys"`
+	Threshold  int                 `json:"threshold"`
+}
+
+// Validate checks that the policy's threshold is reachable and its keys are distinct
+func (p *MultiSigPolicy) Validate() error {
+	if p.Threshold < 1 || p.Threshold > len(p.PublicKeys) {
+		return fmt.Errorf("%w: threshold %d with %d keys", ErrInvalidMultiSigPolicy, p.Threshold, len(p.PublicKeys))
+	}
+
+	seen := make(map[string]bool, len(p.PublicKeys))
+	for _, key := range p.PublicKeys {
+		if len(key) != ed25519.PublicKeySize {
+			return fmt.Errorf("%w: invalid public key size", ErrInvalidMultiSigPolicy)
+		}
+		if seen[string(key)] {
+			return fmt.Errorf("%w: duplicate public key", ErrInvalidMultiSigPolicy)
+		}
+		seen[string(key)] = true
+	}
+
+	return nil
+}
+
 // Account represents a user account in the system
 type Account struct {
-	Address    string            `json:"address"`
-	Balance    float64           `json:"balance"`
-	PublicKey  ed25519.PublicKey `json:"public_key"`
-	Nonces     map[string]bool   `json:"nonces"`
-	LastActive int64             `json:"last_active"`
+	Address     string            `json:"address"`
+	Balance     MinorUnits        `json:"balance"`
+	Held        MinorUnits        `json:"held,omitempty"`
+	PublicKey   ed25519.PublicKey `json:"public_key"`
+	MultiSig    *MultiSigPolicy   `json:"multisig,omitempty"`
+	Nonces      map[string]int64  `json:"nonces"`
+	LastNonce   uint64            `json:"last_nonce,omitempty"`
+	NonceIssued uint64            `json:"nonce_issued,omitempty"` // highest nonce handed out by NextNonce
+	LastActive  int64             `json:"last_active"`
 }
 
 // NewAccount creates a new account
@@ -182,28 +374,144 @@ func NewAccount(address string, publicKey ed25519.PublicKey) *Account {
 		Address:    address,
 		Balance:    0,
 		PublicKey:  publicKey,
-		Nonces:     make(map[string]bool),
+		Nonces:     make(map[string]int64),
 		LastActive: time.Now().Unix(),
 	}
 }
 
+// Available returns the part of the balance that is not held by reservations
+func (a *Account) Available() MinorUnits {
+	return a.Balance - a.Held
+}
+
+// HasNonce reports whether a nonce has been used within the retention window
+func (a *Account) HasNonce(nonce string) bool {
+	_, used := a.Nonces[nonce]
+	return used
+}
+
+// recordNonce records a used nonce and forgets nonces used before cutoff,
+// keeping the nonce set bounded by the retention window. In sequential mode
+// the nonce also advances the account's last nonce.
+func (a *Account) recordNonce(nonce string, usedAt, cutoff int64, sequential bool) {
+	for n, ts := range a.Nonces {
+		if ts < cutoff {
+			delete(a.Nonces, n)
+		}
+	}
+	a.Nonces[nonce] = usedAt
+
+	if !sequential {
+		return
+	}
+	if n, err := parseSequentialNonce(nonce); err == nil && n > a.LastNonce {
+		a.LastNonce = n
+	}
+}
+
 // TransactionEngine manages accounts and processes transactions
 type TransactionEngine struct {
-	mu           sync.RWMutex
-	accounts     map[string]*Account
-	transactions map[string]*Transaction
-	timeOracle   timeoracle.TimeOracle
-	feeAddress   string
+	mu               sync.RWMutex
+	accounts         map[string]*Account
+	transactions     map[string]*Transaction
+	timeOracle       timeoracle.TimeOracle
+	feeAddress       string
+	feeAddresses     map[TransactionType]string // per-type overrides of feeAddress
+	nonceRetention   time.Duration
+	sequentialNonces bool
+	refunds          map[string]string   // original transaction ID -> refund ID
+	byAddress        map[string][]string // address -> IDs of transactions it sent or received
+	reservations     map[string]*Reservation
+	protected        map[string]bool // system accounts that only distributions may debit
+	dailyLimit       MinorUnits
+	accountLimits    map[string]MinorUnits
+	spending         map[string]*dailySpend
+	proofRequired    map[TransactionType]bool // types that must carry a time proof
 }
 
 // NewTransactionEngine creates a new transaction engine
 func NewTransactionEngine(timeOracle timeoracle.TimeOracle, feeAddress string) *TransactionEngine {
 	return &TransactionEngine{
-		accounts:     make(map[string]*Account),
-		transactions: make(map[string]*Transaction),
-		timeOracle:   timeOracle,
-		feeAddress:   feeAddress,
+		accounts:       make(map[string]*Account),
+		transactions:   make(map[string]*Transaction),
+		timeOracle:     timeOracle,
+		feeAddress:     feeAddress,
+		feeAddresses:   make(map[TransactionType]string),
+		nonceRetention: DefaultNonceRetention,
+		refunds:        make(map[string]string),
+		reservations:   make(map[string]*Reservation),
+		byAddress:      make(map[string][]string),
+		protected:      make(map[string]bool),
+		accountLimits:  make(map[string]MinorUnits),
+		spending:       make(map[string]*dailySpend),
+		proofRequired:  make(map[TransactionType]bool),
+	}
+}
+
+// SetNonceRetention configures how long used nonces are remembered. Transactions
+// older than the retention window are rejected, so an expired nonce can only be
+// reused by a newly signed transaction. A non-positive value restores the default.
+func (e *TransactionEngine) SetNonceRetention(retention time.Duration) {
+	if retention <= 0 {
+		retention = DefaultNonceRetention
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.nonceRetention = retention
+}
+
+// SetFeeAddress routes fees from transactions of the given type to a separate
+// collector account. An empty address restores the default fee address.
+func (e *TransactionEngine) SetFeeAddress(txType TransactionType, address string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if address == "" {
+		delete(e.feeAddresses, txType)
+		return
+	}
+	e.feeAddresses[txType] = address
+}
+
+// feeAddressFor returns the collector account for fees from a transaction type
+func (e *TransactionEngine) feeAddressFor(txType TransactionType) string {
+	if address, ok := e.feeAddresses[txType]; ok {
+		return address
+	}
+	return e.feeAddress
+}
+
+// SetProtectedAccounts replaces the set of system accounts, such as the reserve
+// and fee collectors, that can only be debited by distributions
+func (e *TransactionEngine) SetProtectedAccounts(addresses []string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.protected = make(map[string]bool, len(addresses))
+	for _, address := range addresses {
+		e.protected[address] = true
+	}
+}
+
+// IsProtectedAccount reports whether an account can only be debited by distributions
+func (e *TransactionEngine) IsProtectedAccount(address string) bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.protected[address]
+}
+
+// now returns the current Unix time according to the time oracle
+func (e *TransactionEngine) now() int64 {
+	if e.timeOracle != nil {
+		return e.timeOracle.Now()
 	}
+	return time.Now().Unix()
+}
+
+// nonceCutoff returns the timestamp before which used nonces are forgotten
+func (e *TransactionEngine) nonceCutoff() int64 {
+	return e.now() - int64(e.nonceRetention/time.Second)
 }
 
 // CreateAccount creates a new account
@@ -219,6 +527,27 @@ func (e *TransactionEngine) CreateAccount(address string, publicKey ed25519.Publ
 	return nil
 }
 
+// SetMultiSigPolicy requires transactions from an account to be signed by
+// threshold of the given keys. The account's single public key is no longer
+// accepted once a policy is set.
+func (e *TransactionEngine) SetMultiSigPolicy(address string, publicKeys []ed25519.PublicKey, threshold int) error {
+	policy := &MultiSigPolicy{PublicKeys: publicKeys, Threshold: threshold}
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	account, exists := e.accounts[address]
+	if !exists {
+		return fmt.Errorf("account %s not found", address)
+	}
+
+	account.MultiSig = policy
+	return nil
+}
+
 // GetAccount returns an account by address
 func (e *TransactionEngine) GetAccount(address string) (*Account, error) {
 	e.mu.RLock()
@@ -239,7 +568,7 @@ func (e *TransactionEngine) GetBalance(address string) (float64, error) {
 		return 0, err
 	}
 
-	return account.Balance, nil
+	return account.Balance.Float64(), nil
 }
 
 // ProcessTransaction processes a transaction
@@ -247,6 +576,11 @@ func (e *TransactionEngine) ProcessTransaction(tx *Transaction) error {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
+	return e.processTransaction(tx)
+}
+
+// processTransaction processes a transaction with the engine lock held
+func (e *TransactionEngine) processTransaction(tx *Transaction) error {
 	// Check if transaction already exists
 	if _, exists := e.transactions[tx.ID]; exists {
 		return fmt.Errorf("transaction %s already exists", tx.ID)
@@ -255,7 +589,29 @@ func (e *TransactionEngine) ProcessTransaction(tx *Transaction) error {
 	// Validate transaction
 	if err := tx.Validate(); err != nil {
 		tx.Status = Failed
-		e.transactions[tx.ID] = tx
+		e.storeTransaction(tx)
+		return err
+	}
+
+	amount, fee := tx.minorAmounts()
+
+	// Check the transaction's validity window
+	now := e.now()
+	if tx.ValidUntil != 0 && now > tx.ValidUntil {
+		tx.Status = Failed
+		e.storeTransaction(tx)
+		return ErrTransactionExpired
+	}
+	if tx.Timestamp > now+maxFutureSkew {
+		tx.Status = Failed
+		e.storeTransaction(tx)
+		return ErrNotYetValid
+	}
+
+	// Check the time proof against the oracle
+	if err := e.verifyTimeProof(tx); err != nil {
+		tx.Status = Failed
+		e.storeTransaction(tx)
 		return err
 	}
 
@@ -265,114 +621,207 @@ func (e *TransactionEngine) ProcessTransaction(tx *Transaction) error {
 		sender, exists := e.accounts[tx.Sender]
 		if !exists {
 			tx.Status = Failed
-			e.transactions[tx.ID] = tx
+			e.storeTransaction(tx)
 			return fmt.Errorf("sender account %s not found", tx.Sender)
 		}
 
+		// Protected system accounts only pay out through distributions,
+		// and only they may send distributions
+		if e.protected[tx.Sender] && (tx.Type == Payment || tx.Type == Withdrawal || tx.Type == Refund) {
+			tx.Status = Failed
+			e.storeTransaction(tx)
+			return ErrProtectedAccount
+		}
+		if tx.Type == Distribution && !e.protected[tx.Sender] {
+			tx.Status = Failed
+			e.storeTransaction(tx)
+			return fmt.Errorf("%w: distributions must come from a protected account", ErrInvalidTransaction)
+		}
+
+		// Reject transactions too old for their nonce to still be remembered
+		if tx.Timestamp < e.nonceCutoff() {
+			tx.Status = Failed
+			e.storeTransaction(tx)
+			return ErrStaleTransaction
+		}
+
 		// Check for duplicate nonce
-		if sender.Nonces[tx.Nonce] {
+		if sender.HasNonce(tx.Nonce) {
 			tx.Status = Failed
-			e.transactions[tx.ID] = tx
+			e.storeTransaction(tx)
 			return ErrDuplicateNonce
 		}
+		if e.sequentialNonces {
+			if err := sender.checkSequentialNonce(tx.Nonce); err != nil {
+				tx.Status = Failed
+				e.storeTransaction(tx)
+				return err
+			}
+		}
 
-		// Verify signature
-		valid, err := tx.Verify(sender.PublicKey)
-		if err != nil || !valid {
-			tx.Status = Failed
-			e.transactions[tx.ID] = tx
-			return ErrInvalidSignature
+		// Verify signatures against the account's multisig policy or single key
+		if sender.MultiSig != nil {
+			valid, err := tx.VerifyMultiSig(sender.MultiSig)
+			if err != nil {
+				tx.Status = Failed
+				e.storeTransaction(tx)
+				return ErrInvalidSignature
+			}
+			if !valid {
+				tx.Status = Failed
+				e.storeTransaction(tx)
+				return ErrInsufficientSignatures
+			}
+		} else {
+			valid, err := tx.Verify(sender.PublicKey)
+			if err != nil || !valid {
+				tx.Status = Failed
+				e.storeTransaction(tx)
+				return ErrInvalidSignature
+			}
 		}
 
-		// Check sufficient funds for payments and withdrawals
-		if tx.Type == Payment || tx.Type == Withdrawal {
-			if sender.Balance < tx.Amount+tx.Fee {
+		// Check sufficient funds for payments, withdrawals, refunds and distributions
+		if tx.Type == Payment || tx.Type == Withdrawal || tx.Type == Refund || tx.Type == Distribution {
+			if sender.Available() < amount+fee {
 				tx.Status = Failed
-				e.transactions[tx.ID] = tx
+				e.storeTransaction(tx)
 				return ErrInsufficientFunds
 			}
 		}
+
+		// Check the daily spending limit for payments and withdrawals
+		if tx.Type == Payment || tx.Type == Withdrawal {
+			if err := e.checkDailyLimit(tx); err != nil {
+				tx.Status = Failed
+				e.storeTransaction(tx)
+				return err
+			}
+		}
+	}
+
+	// Fees need a collector to credit, otherwise they would be destroyed.
+	// Withdrawal fees are credited when the withdrawal is sent.
+	var feeAccount *Account
+	if fee > 0 && tx.Type != SupplyIncrease {
+		account, exists := e.accounts[e.feeAddressFor(tx.Type)]
+		if !exists {
+			tx.Status = Failed
+			e.storeTransaction(tx)
+			return fmt.Errorf("%w: %s", ErrFeeAccountNotFound, e.feeAddressFor(tx.Type))
+		}
+		feeAccount = account
 	}
 
 	// Process transaction based on type
 	switch tx.Type {
-	case Payment:
+	case Payment, Distribution:
 		// Get receiver account
 		receiver, exists := e.accounts[tx.Receiver]
 		if !exists {
 			tx.Status = Failed
-			e.transactions[tx.ID] = tx
+			e.storeTransaction(tx)
 			return fmt.Errorf("receiver account %s not found", tx.Receiver)
 		}
 
 		// Update balances
 		sender := e.accounts[tx.Sender]
-		sender.Balance -= tx.Amount + tx.Fee
-		receiver.Balance += tx.Amount
+		sender.Balance -= amount + fee
+		receiver.Balance += amount
 
 		// Update fee account
-		if tx.Fee > 0 {
-			feeAccount, exists := e.accounts[e.feeAddress]
-			if exists {
-				feeAccount.Balance += tx.Fee
-			}
+		if feeAccount != nil {
+			feeAccount.Balance += fee
 		}
 
 		// Record nonce
-		sender.Nonces[tx.Nonce] = true
+		sender.recordNonce(tx.Nonce, tx.Timestamp, e.nonceCutoff(), e.sequentialNonces)
 		sender.LastActive = tx.Timestamp
 		receiver.LastActive = tx.Timestamp
 
+		if tx.Type == Payment {
+			e.recordSpend(tx)
+		}
+
 	case Deposit:
 		// Get receiver account
 		receiver, exists := e.accounts[tx.Receiver]
 		if !exists {
 			tx.Status = Failed
-			e.transactions[tx.ID] = tx
+			e.storeTransaction(tx)
 			return fmt.Errorf("receiver account %s not found", tx.Receiver)
 		}
 
 		// Update balance
-		receiver.Balance += tx.Amount
+		receiver.Balance += amount
 
 		// Update fee account
-		if tx.Fee > 0 {
-			feeAccount, exists := e.accounts[e.feeAddress]
-			if exists {
-				feeAccount.Balance += tx.Fee
-			}
+		if feeAccount != nil {
+			feeAccount.Balance += fee
 		}
 
 		receiver.LastActive = tx.Timestamp
 
 	case Withdrawal:
-		// Update sender balance
+		// Hold the funds until the external send is confirmed; the fee is
+		// collected when the withdrawal is marked sent
 		sender := e.accounts[tx.Sender]
-		sender.Balance -= tx.Amount + tx.Fee
+		sender.Balance -= amount + fee
+
+		// Record nonce
+		sender.recordNonce(tx.Nonce, tx.Timestamp, e.nonceCutoff(), e.sequentialNonces)
+		sender.LastActive = tx.Timestamp
+		e.recordSpend(tx)
+
+		// Withdrawals stay pending until an operator completes them
+		tx.Withdrawal = WithdrawalRequested
+		e.storeTransaction(tx)
+		return nil
+
+	case Refund:
+		// Check the refund against the original payment
+		if err := e.checkRefund(tx); err != nil {
+			tx.Status = Failed
+			e.storeTransaction(tx)
+			return err
+		}
+
+		sender := e.accounts[tx.Sender]
+		receiver, exists := e.accounts[tx.Receiver]
+		if !exists {
+			tx.Status = Failed
+			e.storeTransaction(tx)
+			return fmt.Errorf("receiver account %s not found", tx.Receiver)
+		}
+
+		// Update balances
+		sender.Balance -= amount + fee
+		receiver.Balance += amount
 
 		// Update fee account
-		if tx.Fee > 0 {
-			feeAccount, exists := e.accounts[e.feeAddress]
-			if exists {
-				feeAccount.Balance += tx.Fee
-			}
+		if feeAccount != nil {
+			feeAccount.Balance += fee
 		}
 
+		// Mark the original as refunded
+		e.refunds[tx.RefundOf] = tx.ID
+
 		// Record nonce
-		sender.Nonces[tx.Nonce] = true
+		sender.recordNonce(tx.Nonce, tx.Timestamp, e.nonceCutoff(), e.sequentialNonces)
 		sender.LastActive = tx.Timestamp
+		receiver.LastActive = tx.Timestamp
 
 	case SupplyIncrease:
 		// Get receiver account (reserve)
 		receiver, exists := e.accounts[tx.Receiver]
 		if !exists {
 			tx.Status = Failed
-			e.transactions[tx.ID] = tx
+			e.storeTransaction(tx)
 			return fmt.Errorf("reserve account %s not found", tx.Receiver)
 		}
 
 		// Update balance
-		receiver.Balance += tx.Amount
+		receiver.Balance += amount
 		receiver.LastActive = tx.Timestamp
 	}
 
@@ -380,11 +829,79 @@ func (e *TransactionEngine) ProcessTransaction(tx *Transaction) error {
 	tx.Status = Confirmed
 
 	// Store transaction
-	e.transactions[tx.ID] = tx
+	e.storeTransaction(tx)
 
 	return nil
 }
 
+// checkRefund verifies that a refund reverses a confirmed, unrefunded payment
+// between the same parties for its full amount
+func (e *TransactionEngine) checkRefund(tx *Transaction) error {
+	if _, refunded := e.refunds[tx.RefundOf]; refunded {
+		return ErrAlreadyRefunded
+	}
+
+	original, exists := e.transactions[tx.RefundOf]
+	if !exists {
+		return fmt.Errorf("%w: original transaction %s not found", ErrInvalidRefund, tx.RefundOf)
+	}
+
+	if original.Type != Payment {
+		return fmt.Errorf("%w: only payments can be refunded", ErrInvalidRefund)
+	}
+
+	if original.Status != Confirmed && original.Status != Settled {
+		return fmt.Errorf("%w: original transaction %s is not confirmed", ErrInvalidRefund, tx.RefundOf)
+	}
+
+	if tx.Sender != original.Receiver || tx.Receiver != original.Sender {
+		return fmt.Errorf("%w: refund must reverse the original sender and receiver", ErrInvalidRefund)
+	}
+
+	amount, fee := tx.minorAmounts()
+	originalAmount, _ := original.minorAmounts()
+	if amount+fee != originalAmount {
+		return fmt.Errorf("%w: refund amount and fee must equal the original amount", ErrInvalidRefund)
+	}
+
+	return nil
+}
+
+// RefundOf returns the ID of the refund for a transaction, if it has been refunded
+func (e *TransactionEngine) RefundOf(originalID string) (string, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	refundID, refunded := e.refunds[originalID]
+	return refundID, refunded
+}
+
+// storeTransaction records a transaction and indexes it by sender and receiver
+func (e *TransactionEngine) storeTransaction(tx *Transaction) {
+	e.transactions[tx.ID] = tx
+
+	if tx.Sender != "" {
+		e.byAddress[tx.Sender] = append(e.byAddress[tx.Sender], tx.ID)
+	}
+	if tx.Receiver != "" && tx.Receiver != tx.Sender {
+		e.byAddress[tx.Receiver] = append(e.byAddress[tx.Receiver], tx.ID)
+	}
+}
+
+// GetTransactionsByAddress returns all transactions sent or received by an address
+func (e *TransactionEngine) GetTransactionsByAddress(address string) []*Transaction {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	ids := e.byAddress[address]
+	txs := make([]*Transaction, 0, len(ids))
+	for _, id := range ids {
+		txs = append(txs, e.transactions[id])
+	}
+
+	return txs
+}
+
 // GetTransaction returns a transaction by ID
 func (e *TransactionEngine) GetTransaction(id string) (*Transaction, error) {
 	e.mu.RLock()
@@ -0,0 +1,108 @@
+package transaction
+
+import (
+	"errors"
+	"fmt"
+)
+
+// WithdrawalStatus tracks a withdrawal to an external destination
+type WithdrawalStatus string
+
+const (
+	// WithdrawalRequested means the funds are held awaiting approval
+	WithdrawalRequested WithdrawalStatus = "REQUESTED"
+	// WithdrawalApproved means an operator approved the external send
+	WithdrawalApproved WithdrawalStatus = "APPROVED"
+	// WithdrawalSent means the external send completed
+	WithdrawalSent WithdrawalStatus = "SENT"
+	// WithdrawalFailed means the external send failed and the funds were returned
+	WithdrawalFailed WithdrawalStatus = "FAILED"
+)
+
+// ErrInvalidWithdrawalTransition is returned when a withdrawal cannot move to the requested status
+var ErrInvalidWithdrawalTransition = errors.New("invalid withdrawal status transition")
+
+// ApproveWithdrawal approves a requested withdrawal for sending
+func (e *TransactionEngine) ApproveWithdrawal(id string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	tx, err := e.pendingWithdrawal(id, WithdrawalRequested)
+	if err != nil {
+		return err
+	}
+
+	tx.Withdrawal = WithdrawalApproved
+	return nil
+}
+
+// MarkWithdrawalSent completes an approved withdrawal, recording the external
+// reference of the send and collecting the fee
+func (e *TransactionEngine) MarkWithdrawalSent(id, externalRef string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	tx, err := e.pendingWithdrawal(id, WithdrawalApproved)
+	if err != nil {
+		return err
+	}
+
+	// Collect the fee held with the withdrawal
+	if _, fee := tx.minorAmounts(); fee > 0 {
+		feeAccount, exists := e.accounts[e.feeAddressFor(tx.Type)]
+		if !exists {
+			return fmt.Errorf("%w: %s", ErrFeeAccountNotFound, e.feeAddressFor(tx.Type))
+		}
+		feeAccount.Balance += fee
+	}
+
+	tx.ExternalRef = externalRef
+	tx.Withdrawal = WithdrawalSent
+	tx.Status = Confirmed
+	return nil
+}
+
+// FailWithdrawal fails a requested or approved withdrawal and returns the held
+// funds, including the fee, to the sender. The amount no longer counts toward
+// the sender's daily limit.
+func (e *TransactionEngine) FailWithdrawal(id string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	tx, err := e.pendingWithdrawal(id, WithdrawalRequested, WithdrawalApproved)
+	if err != nil {
+		return err
+	}
+
+	sender, exists := e.accounts[tx.Sender]
+	if !exists {
+		return fmt.Errorf("sender account %s not found", tx.Sender)
+	}
+	amount, fee := tx.minorAmounts()
+	sender.Balance += amount + fee
+	e.releaseSpend(tx)
+
+	tx.Withdrawal = WithdrawalFailed
+	tx.Status = Failed
+	return nil
+}
+
+// pendingWithdrawal returns a withdrawal transaction if it is in one of the given statuses
+func (e *TransactionEngine) pendingWithdrawal(id string, from ...WithdrawalStatus) (*Transaction, error) {
+	tx, exists := e.transactions[id]
+	if !exists {
+		return nil, fmt.Errorf("transaction %s not found", id)
+	}
+
+	if tx.Type != Withdrawal {
+		return nil, fmt.Errorf("transaction %s is not a withdrawal", id)
+	}
+
+	for _, status := range from {
+		if tx.Withdrawal == status {
+			return tx, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: withdrawal %s is %s", ErrInvalidWithdrawalTransition, id, tx.Withdrawal)
+}
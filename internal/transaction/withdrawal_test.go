@@ -0,0 +1,85 @@
+package transaction
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFailWithdrawalReleasesDailyLimit(t *testing.T) {
+	tests := []struct {
+		name          string
+		approve       bool
+		signedEarlier bool
+	}{
+		{name: "requested"},
+		{name: "approved", approve: true},
+		// Spending is counted on the day it is processed, not the day it was signed
+		{name: "signed the day before", signedEarlier: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newTestEngine(t)
+			if err := e.SetDailyLimit(100); err != nil {
+				t.Fatalf("SetDailyLimit: %v", err)
+			}
+			key := newTestAccount(t, e, "alice")
+			newTestAccount(t, e, "EXTERNAL")
+			fund(t, e, "alice", 200)
+
+			withdrawal := signedTx(t, "alice", key, "EXTERNAL", 80, 1, Withdrawal)
+			if err := e.ProcessTransaction(withdrawal); err != nil {
+				t.Fatalf("ProcessTransaction: %v", err)
+			}
+			if tt.signedEarlier {
+				withdrawal.Timestamp -= secondsPerDay
+			}
+			if tt.approve {
+				if err := e.ApproveWithdrawal(withdrawal.ID); err != nil {
+					t.Fatalf("ApproveWithdrawal: %v", err)
+				}
+			}
+			if err := e.FailWithdrawal(withdrawal.ID); err != nil {
+				t.Fatalf("FailWithdrawal: %v", err)
+			}
+
+			if got := e.DailySpent("alice"); got != 0 {
+				t.Fatalf("DailySpent after a failed withdrawal = %v, want 0", got)
+			}
+			if got := balance(t, e, "alice"); got != 200 {
+				t.Fatalf("balance after a failed withdrawal = %v, want 200", got)
+			}
+
+			// The released amount can be sent again within the same day
+			if err := e.ProcessTransaction(signedTx(t, "alice", key, "EXTERNAL", 80, 1, Withdrawal)); err != nil {
+				t.Fatalf("second withdrawal: %v", err)
+			}
+			if err := e.ProcessTransaction(signedTx(t, "alice", key, "EXTERNAL", 30, 0, Withdrawal)); !errors.Is(err, ErrDailyLimitExceeded) {
+				t.Fatalf("withdrawal over the limit error = %v, want %v", err, ErrDailyLimitExceeded)
+			}
+		})
+	}
+}
+
+func TestFailWithdrawalTransitions(t *testing.T) {
+	e := newTestEngine(t)
+	key := newTestAccount(t, e, "alice")
+	newTestAccount(t, e, "EXTERNAL")
+	fund(t, e, "alice", 100)
+
+	withdrawal := signedTx(t, "alice", key, "EXTERNAL", 10, 0, Withdrawal)
+	if err := e.ProcessTransaction(withdrawal); err != nil {
+		t.Fatalf("ProcessTransaction: %v", err)
+	}
+	if err := e.FailWithdrawal(withdrawal.ID); err != nil {
+		t.Fatalf("FailWithdrawal: %v", err)
+	}
+
+	// A failed withdrawal cannot be failed again, so funds are returned once
+	if err := e.FailWithdrawal(withdrawal.ID); !errors.Is(err, ErrInvalidWithdrawalTransition) {
+		t.Fatalf("second FailWithdrawal error = %v, want %v", err, ErrInvalidWithdrawalTransition)
+	}
+	if got := balance(t, e, "alice"); got != 100 {
+		t.Fatalf("balance = %v, want 100", got)
+	}
+}
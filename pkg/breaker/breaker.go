@@ -0,0 +1,147 @@
+// Package breaker provides a circuit breaker that fails fast while a
+// dependency such as Redis is unavailable.
+package breaker
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrUnavailable is returned while the circuit is open
+var ErrUnavailable = errors.New("circuit breaker is open: dependency unavailable")
+
+// State is the state of a circuit breaker
+type State int
+
+const (
+	// Closed lets calls through and counts consecutive failures
+	Closed State = iota
+	// Open rejects calls until the cooldown has elapsed
+	Open
+	// HalfOpen lets a single probe call through to test recovery
+	HalfOpen
+)
+
+// String returns the name of the state
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+const (
+	// DefaultThreshold is the number of consecutive failures that opens the circuit
+	DefaultThreshold = 5
+
+	// DefaultCooldown is how long the circuit stays open before probing
+	DefaultCooldown = 10 * time.Second
+)
+
+// Breaker is a consecutive-failure circuit breaker. It opens after threshold
+// consecutive failures, rejects calls for the cooldown, then lets a single
+// probe through; a successful probe closes it and a failed one reopens it.
+type Breaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	state     State
+	failures  int
+	openedAt  time.Time
+	probing   bool
+	now       func() time.Time
+}
+
+// New creates a circuit breaker. Non-positive values use the defaults.
+func New(threshold int, cooldown time.Duration) *Breaker {
+	if threshold <= 0 {
+		threshold = DefaultThreshold
+	}
+	if cooldown <= 0 {
+		cooldown = DefaultCooldown
+	}
+
+	return &Breaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+	}
+}
+
+// Allow reports whether a call may proceed, returning ErrUnavailable while
+// the circuit is open or a probe is already in flight
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case Open:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			return ErrUnavailable
+		}
+		b.state = HalfOpen
+		b.probing = true
+		return nil
+	case HalfOpen:
+		if b.probing {
+			return ErrUnavailable
+		}
+		b.probing = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+// Success records a successful call, closing the circuit after a probe
+func (b *Breaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = Closed
+	b.failures = 0
+	b.probing = false
+}
+
+// Failure records a failed call, opening the circuit once the threshold is
+// reached or when a probe fails
+func (b *Breaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	if b.state == HalfOpen || b.failures >= b.threshold {
+		b.state = Open
+		b.openedAt = b.now()
+		b.probing = false
+	}
+}
+
+// Abandon records a call that ended without telling whether the dependency
+// is healthy, such as one its caller cancelled. A probe slot it held is freed
+// so the next call can probe instead.
+func (b *Breaker) Abandon() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
+
+// State returns the current state of the circuit
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// An open circuit whose cooldown has elapsed will admit the next call as a probe
+	if b.state == Open && b.now().Sub(b.openedAt) >= b.cooldown {
+		return HalfOpen
+	}
+	return b.state
+}
@@ -0,0 +1,111 @@
+package breaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// newTestBreaker returns a breaker whose clock is advanced by the returned function
+func newTestBreaker(threshold int, cooldown time.Duration) (*Breaker, func(time.Duration)) {
+	b := New(threshold, cooldown)
+	now := time.Unix(1000, 0)
+	b.now = func() time.Time { return now }
+	return b, func(d time.Duration) { now = now.Add(d) }
+}
+
+func TestBreakerTransitions(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps func(b *Breaker, advance func(time.Duration))
+		want  State
+	}{
+		{
+			name:  "stays closed below threshold",
+			steps: func(b *Breaker, advance func(time.Duration)) { b.Failure(); b.Failure() },
+			want:  Closed,
+		},
+		{
+			name:  "opens at threshold",
+			steps: func(b *Breaker, advance func(time.Duration)) { b.Failure(); b.Failure(); b.Failure() },
+			want:  Open,
+		},
+		{
+			name: "success resets the failure count",
+			steps: func(b *Breaker, advance func(time.Duration)) {
+				b.Failure()
+				b.Failure()
+				b.Success()
+				b.Failure()
+			},
+			want: Closed,
+		},
+		{
+			name: "half-open after cooldown",
+			steps: func(b *Breaker, advance func(time.Duration)) {
+				b.Failure()
+				b.Failure()
+				b.Failure()
+				advance(time.Minute)
+			},
+			want: HalfOpen,
+		},
+		{
+			name: "successful probe closes",
+			steps: func(b *Breaker, advance func(time.Duration)) {
+				b.Failure()
+				b.Failure()
+				b.Failure()
+				advance(time.Minute)
+				b.Allow()
+				b.Success()
+			},
+			want: Closed,
+		},
+		{
+			name: "failed probe reopens",
+			steps: func(b *Breaker, advance func(time.Duration)) {
+				b.Failure()
+				b.Failure()
+				b.Failure()
+				advance(time.Minute)
+				b.Allow()
+				b.Failure()
+			},
+			want: Open,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, advance := newTestBreaker(3, time.Minute)
+			tt.steps(b, advance)
+			if got := b.State(); got != tt.want {
+				t.Fatalf("State() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBreakerAllowsOneProbe(t *testing.T) {
+	b, advance := newTestBreaker(1, time.Minute)
+	b.Failure()
+
+	if err := b.Allow(); !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("Allow during cooldown = %v, want %v", err, ErrUnavailable)
+	}
+
+	advance(time.Minute)
+	if err := b.Allow(); err != nil {
+		t.Fatalf("Allow after cooldown = %v, want the probe through", err)
+	}
+	if err := b.Allow(); !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("Allow during probe = %v, want %v", err, ErrUnavailable)
+	}
+
+	// An abandoned probe lets the next call probe instead
+	b.Abandon()
+	if err := b.Allow(); err != nil {
+		t.Fatalf("Allow after abandoned probe = %v, want the next probe through", err)
+	}
+}
@@ -0,0 +1,75 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// redisHook guards Redis commands with a circuit breaker
+type redisHook struct {
+	breaker *Breaker
+}
+
+// NewRedisHook returns a Redis client hook that fails commands fast with
+// ErrUnavailable while the breaker is open. Add it with client.AddHook.
+func NewRedisHook(b *Breaker) redis.Hook {
+	return redisHook{breaker: b}
+}
+
+// BeforeProcess rejects the command while the circuit is open
+func (h redisHook) BeforeProcess(ctx context.Context, cmd redis.Cmder) (context.Context, error) {
+	return ctx, h.breaker.Allow()
+}
+
+// AfterProcess records the outcome of the command
+func (h redisHook) AfterProcess(ctx context.Context, cmd redis.Cmder) error {
+	h.record(ctx, cmd.Err())
+	return nil
+}
+
+// BeforeProcessPipeline rejects the pipeline while the circuit is open
+func (h redisHook) BeforeProcessPipeline(ctx context.Context, cmds []redis.Cmder) (context.Context, error) {
+	return ctx, h.breaker.Allow()
+}
+
+// AfterProcessPipeline records the outcome of the pipeline
+func (h redisHook) AfterProcessPipeline(ctx context.Context, cmds []redis.Cmder) error {
+	var err error
+	for _, cmd := range cmds {
+		if cmdErr := cmd.Err(); cmdErr != nil && cmdErr != redis.Nil {
+			err = cmdErr
+			break
+		}
+	}
+	h.record(ctx, err)
+	return nil
+}
+
+// record reports a command outcome to the breaker. Missing keys are not
+// failures, and commands the breaker rejected or the caller gave up on have
+// nothing to report.
+func (h redisHook) record(ctx context.Context, err error) {
+	switch {
+	case errors.Is(err, ErrUnavailable):
+		return
+	case ctx.Err() != nil && (errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)):
+		// The caller's context ended, which says nothing about Redis
+		h.breaker.Abandon()
+	case err == nil || err == redis.Nil:
+		h.breaker.Success()
+	case isServerReply(err):
+		// The server answered, so it is reachable
+		h.breaker.Success()
+	default:
+		h.breaker.Failure()
+	}
+}
+
+// isServerReply reports whether an error is a reply from the Redis server
+// (such as WRONGTYPE) rather than a connection or timeout failure
+func isServerReply(err error) bool {
+	var redisErr redis.Error
+	return errors.As(err, &redisErr)
+}
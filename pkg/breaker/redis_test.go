@@ -0,0 +1,118 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// cancelledContext returns a context that has already been cancelled
+func cancelledContext() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	return ctx
+}
+
+// expiredContext returns a context whose deadline has already passed
+func expiredContext() context.Context {
+	ctx, cancel := context.WithDeadline(context.Background(), time.Unix(0, 0))
+	cancel() // the context already ended with DeadlineExceeded
+	return ctx
+}
+
+func TestRedisHookRecordsOutcomes(t *testing.T) {
+	netErr := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		err  error
+		want State
+	}{
+		{name: "success", ctx: context.Background(), want: Closed},
+		{name: "missing key", ctx: context.Background(), err: redis.Nil, want: Closed},
+		{name: "server reply", ctx: context.Background(), err: redisReply("WRONGTYPE Operation against a key"), want: Closed},
+		{name: "connection failure", ctx: context.Background(), err: netErr, want: Open},
+		{name: "rejected by breaker", ctx: context.Background(), err: ErrUnavailable, want: Closed},
+		{name: "caller cancelled", ctx: cancelledContext(), err: context.Canceled, want: Closed},
+		{name: "caller deadline", ctx: expiredContext(), err: context.DeadlineExceeded, want: Closed},
+		{name: "deadline error with live context", ctx: context.Background(), err: context.DeadlineExceeded, want: Open},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, _ := newTestBreaker(1, time.Minute)
+			hook := NewRedisHook(b)
+
+			cmd := redis.NewStringCmd(tt.ctx, "get", "key")
+			cmd.SetErr(tt.err)
+			hook.AfterProcess(tt.ctx, cmd)
+
+			if got := b.State(); got != tt.want {
+				t.Fatalf("State() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedisHookCancelledProbeFreesSlot(t *testing.T) {
+	b, advance := newTestBreaker(1, time.Minute)
+	hook := NewRedisHook(b)
+	b.Failure()
+	advance(time.Minute)
+
+	ctx := cancelledContext()
+	if _, err := hook.BeforeProcess(ctx, redis.NewStringCmd(ctx, "get", "key")); err != nil {
+		t.Fatalf("BeforeProcess = %v, want the probe through", err)
+	}
+	cmd := redis.NewStringCmd(ctx, "get", "key")
+	cmd.SetErr(context.Canceled)
+	hook.AfterProcess(ctx, cmd)
+
+	if _, err := hook.BeforeProcess(context.Background(), cmd); err != nil {
+		t.Fatalf("BeforeProcess after a cancelled probe = %v, want a new probe through", err)
+	}
+}
+
+func TestRedisHookPipeline(t *testing.T) {
+	netErr := &net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset")}
+
+	tests := []struct {
+		name string
+		errs []error
+		want State
+	}{
+		{name: "all succeed", errs: []error{nil, redis.Nil}, want: Closed},
+		{name: "one fails", errs: []error{nil, netErr}, want: Open},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, _ := newTestBreaker(1, time.Minute)
+			hook := NewRedisHook(b)
+
+			cmds := make([]redis.Cmder, len(tt.errs))
+			for i, err := range tt.errs {
+				cmd := redis.NewStringCmd(context.Background(), "get", "key")
+				cmd.SetErr(err)
+				cmds[i] = cmd
+			}
+			hook.AfterProcessPipeline(context.Background(), cmds)
+
+			if got := b.State(); got != tt.want {
+				t.Fatalf("State() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// redisReply is an error reply from the Redis server
+type redisReply string
+
+func (e redisReply) Error() string { return string(e) }
+
+func (redisReply) RedisError() {}
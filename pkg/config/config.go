@@ -8,6 +8,7 @@ import (
 	"io"
 	"net"
 	"os"
+	"path"
 	"path/filepath"
 	"strconv"
 	"strings"
@@ -16,6 +17,7 @@ import (
 	"github.com/joho/godotenv"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
 )
 
 // Config represents the application configuration
@@ -25,6 +27,7 @@ type Config struct {
 	API       APIConfig       `mapstructure:"api" json:"api"`
 	Auth      AuthConfig      `mapstructure:"auth" json:"auth"`
 	Supply    SupplyConfig    `mapstructure:"supply" json:"supply"`
+	Fee       FeeConfig       `mapstructure:"fee" json:"fee"`
 	Processor ProcessorConfig `mapstructure:"processor" json:"processor"`
 	Log       LogConfig       `mapstructure:"log" json:"log"`
 	Metrics   MetricsConfig   `mapstructure:"metrics" json:"metrics"`
@@ -34,12 +37,16 @@ type Config struct {
 
 // RedisConfig represents Redis configuration
 type RedisConfig struct {
-	Address     string        `mapstructure:"address" json:"address"`
-	Password    string        `mapstructure:"password" json:"password"`
-	DB          int           `mapstructure:"db" json:"db"`
-	MaxRetries  int           `mapstructure:"max_retries" json:"max_retries"`
-	PoolSize    int           `mapstructure:"pool_size" json:"pool_size"`
-	DialTimeout time.Duration `mapstructure:"dial_timeout" json:"dial_timeout"`
+	Address          string        `mapstructure:"address" json:"address"`
+	Password         string        `mapstructure:"password" json:"password" secret:"true"`
+	DB               int           `mapstructure:"db" json:"db"`
+	MaxRetries       int           `mapstructure:"max_retries" json:"max_retries"`
+	PoolSize         int           `mapstructure:"pool_size" json:"pool_size"`
+	DialTimeout      time.Duration `mapstructure:"dial_timeout" json:"dial_timeout"`
+	BreakerThreshold int           `mapstructure:"breaker_threshold" json:"breaker_threshold"`
+	BreakerCooldown  time.Duration `mapstructure:"breaker_cooldown" json:"breaker_cooldown"`
+	TLS              bool          `mapstructure:"tls" json:"tls"`
+	TLSServerName    string        `mapstructure:"tls_server_name" json:"tls_server_name,omitempty"`
 }
 
 // KafkaConfig represents Kafka configuration
@@ -55,40 +62,92 @@ type KafkaConfig struct {
 	AutoCommitInterval   time.Duration `mapstructure:"auto_commit_interval" json:"auto_commit_interval"`
 	ProducerMaxRetries   int           `mapstructure:"producer_max_retries" json:"producer_max_retries"`
 	ProducerRetryBackoff time.Duration `mapstructure:"producer_retry_backoff" json:"producer_retry_backoff"`
+	ProducerFlushTimeout time.Duration `mapstructure:"producer_flush_timeout" json:"producer_flush_timeout"`
+	SASLMechanism        string        `mapstructure:"sasl_mechanism" json:"sasl_mechanism,omitempty"`
+	SASLUsername         string        `mapstructure:"sasl_username" json:"sasl_username,omitempty"`
+	SASLPassword         string        `mapstructure:"sasl_password" json:"sasl_password,omitempty" secret:"true"`
+	TLS                  bool          `mapstructure:"tls" json:"tls"`
+	TLSCAFile            string        `mapstructure:"tls_ca_file" json:"tls_ca_file,omitempty"`
 }
 
 // APIConfig represents API server configuration
 type APIConfig struct {
-	Host               string        `mapstructure:"host" json:"host"`
-	Port               string        `mapstructure:"port" json:"port"`
-	Version            string        `mapstructure:"version" json:"version"`
-	ReadTimeout        time.Duration `mapstructure:"read_timeout" json:"read_timeout"`
-	WriteTimeout       time.Duration `mapstructure:"write_timeout" json:"write_timeout"`
-	ShutdownTimeout    time.Duration `mapstructure:"shutdown_timeout" json:"shutdown_timeout"`
-	CORSAllowedOrigins []string      `mapstructure:"cors_allowed_origins" json:"cors_allowed_origins"`
+	Host                  string                    `mapstructure:"host" json:"host"`
+	Port                  string                    `mapstructure:"port" json:"port"`
+	Version               string                    `mapstructure:"version" json:"version"`
+	ReadTimeout           time.Duration             `mapstructure:"read_timeout" json:"read_timeout"`
+	WriteTimeout          time.Duration             `mapstructure:"write_timeout" json:"write_timeout"`
+	ShutdownTimeout       time.Duration             `mapstructure:"shutdown_timeout" json:"shutdown_timeout"`
+	CORSAllowedOrigins    []string                  `mapstructure:"cors_allowed_origins" json:"cors_allowed_origins"`
+	CORSAllowCredentials  bool                      `mapstructure:"cors_allow_credentials" json:"cors_allow_credentials"`
+	CORSMaxAge            time.Duration             `mapstructure:"cors_max_age" json:"cors_max_age"`
+	StrictInputValidation bool                      `mapstructure:"strict_input_validation" json:"strict_input_validation"`
+	RedactedFields        []string                  `mapstructure:"redacted_fields" json:"redacted_fields"`
+	RateLimitRequests     int                       `mapstructure:"rate_limit_requests" json:"rate_limit_requests"`
+	RateLimitWindow       time.Duration             `mapstructure:"rate_limit_window" json:"rate_limit_window"`
+	AllowDegradedStart    bool                      `mapstructure:"allow_degraded_start" json:"allow_degraded_start"`
+	MaxBodySize           int64                     `mapstructure:"max_body_size" json:"max_body_size"`
+	RouteBodySizes        map[string]int64          `mapstructure:"route_body_sizes" json:"route_body_sizes,omitempty"`
+	RouteRateLimits       map[string]RouteRateLimit `mapstructure:"route_rate_limits" json:"route_rate_limits,omitempty"`
+}
+
+// RouteRateLimit overrides the API rate limit for the routes matching a pattern
+type RouteRateLimit struct {
+	Requests int           `mapstructure:"requests" json:"requests"`
+	Window   time.Duration `mapstructure:"window" json:"window"`
 }
 
 // AuthConfig represents authentication configuration
 type AuthConfig struct {
-	JWTSecret            string        `mapstructure:"jwt_secret" json:"jwt_secret"`
-	JWTExpirationTime    time.Duration `mapstructure:"jwt_expiration_time" json:"jwt_expiration_time"`
-	RefreshTokenDuration time.Duration `mapstructure:"refresh_token_duration" json:"refresh_token_duration"`
+	JWTSecret            string         `mapstructure:"jwt_secret" json:"jwt_secret" secret:"true"`
+	JWTExpirationTime    time.Duration  `mapstructure:"jwt_expiration_time" json:"jwt_expiration_time"`
+	RefreshTokenDuration time.Duration  `mapstructure:"refresh_token_duration" json:"refresh_token_duration"`
+	TimeOracleSecret     string         `mapstructure:"time_oracle_secret" json:"time_oracle_secret,omitempty" secret:"true"`
+	APIKeyTTL            time.Duration  `mapstructure:"api_key_ttl" json:"api_key_ttl"`
+	APIKeyRotationGrace  time.Duration  `mapstructure:"api_key_rotation_grace" json:"api_key_rotation_grace"`
+	BcryptCost           int            `mapstructure:"bcrypt_cost" json:"bcrypt_cost"`
+	PasswordPolicy       PasswordPolicy `mapstructure:"password_policy" json:"password_policy"`
+}
+
+// PasswordPolicy represents the strength rules new passwords must satisfy
+type PasswordPolicy struct {
+	MinLength             int    `mapstructure:"min_length" json:"min_length"`
+	RequireUpper          bool   `mapstructure:"require_upper" json:"require_upper"`
+	RequireLower          bool   `mapstructure:"require_lower" json:"require_lower"`
+	RequireDigit          bool   `mapstructure:"require_digit" json:"require_digit"`
+	RequireSymbol         bool   `mapstructure:"require_symbol" json:"require_symbol"`
+	BreachedPasswordsFile string `mapstructure:"breached_passwords_file" json:"breached_passwords_file"`
 }
 
 // SupplyConfig represents currency supply management configuration
 type SupplyConfig struct {
-	MinInflation   float64       `mapstructure:"min_inflation" json:"min_inflation"`
-	MaxInflation   float64       `mapstructure:"max_inflation" json:"max_inflation"`
-	MaxStepSize    float64       `mapstructure:"max_step_size" json:"max_step_size"`
-	ReserveAddress string        `mapstructure:"reserve_address" json:"reserve_address"`
-	AdjustInterval time.Duration `mapstructure:"adjust_interval" json:"adjust_interval"`
+	MinInflation       float64       `mapstructure:"min_inflation" json:"min_inflation"`
+	MaxInflation       float64       `mapstructure:"max_inflation" json:"max_inflation"`
+	MaxStepSize        float64       `mapstructure:"max_step_size" json:"max_step_size"`
+	ReserveAddress     string        `mapstructure:"reserve_address" json:"reserve_address"`
+	AdjustInterval     time.Duration `mapstructure:"adjust_interval" json:"adjust_interval"`
+	ProtectedAddresses []string      `mapstructure:"protected_addresses" json:"protected_addresses,omitempty"`
+
+	MintInterval  time.Duration `mapstructure:"mint_interval" json:"mint_interval"`
+	MintStatePath string        `mapstructure:"mint_state_path" json:"mint_state_path"`
+}
+
+// FeeConfig represents transaction fee configuration
+type FeeConfig struct {
+	Rate             float64           `mapstructure:"rate" json:"rate"`
+	MinFee           float64           `mapstructure:"min_fee" json:"min_fee"`
+	CollectorAddress string            `mapstructure:"collector_address" json:"collector_address"`
+	Collectors       map[string]string `mapstructure:"collectors" json:"collectors,omitempty"`
 }
 
 // ProcessorConfig represents transaction processor configuration
 type ProcessorConfig struct {
-	BatchSize      int           `mapstructure:"batch_size" json:"batch_size"`
-	PollInterval   time.Duration `mapstructure:"poll_interval" json:"poll_interval"`
-	MaxConcurrency int           `mapstructure:"max_concurrency" json:"max_concurrency"`
+	BatchSize        int           `mapstructure:"batch_size" json:"batch_size"`
+	PollInterval     time.Duration `mapstructure:"poll_interval" json:"poll_interval"`
+	MaxConcurrency   int           `mapstructure:"max_concurrency" json:"max_concurrency"`
+	RequireTimeProof []string      `mapstructure:"require_time_proof" json:"require_time_proof,omitempty"`
+
+	SettlementWorkers int `mapstructure:"settlement_workers" json:"settlement_workers"`
 }
 
 // LogConfig represents logging configuration
@@ -99,6 +158,11 @@ type LogConfig struct {
 	ServiceName  string `mapstructure:"service_name" json:"service_name"`
 	Environment  string `mapstructure:"environment" json:"environment"`
 	IncludeTrace bool   `mapstructure:"include_trace" json:"include_trace"`
+	SampleRate   int    `mapstructure:"sample_rate" json:"sample_rate"`
+
+	AccessLogExclude     []string      `mapstructure:"access_log_exclude" json:"access_log_exclude,omitempty"`
+	FastRequestThreshold time.Duration `mapstructure:"fast_request_threshold" json:"fast_request_threshold"`
+	SlowRequestThreshold time.Duration `mapstructure:"slow_request_threshold" json:"slow_request_threshold"`
 }
 
 // MetricsConfig represents metrics collection configuration
@@ -108,6 +172,9 @@ type MetricsConfig struct {
 	ServiceName string `mapstructure:"service_name" json:"service_name"`
 	Endpoint    string `mapstructure:"endpoint" json:"endpoint"`
 	Port        string `mapstructure:"port" json:"port"`
+
+	RequestDurationBuckets   []float64 `mapstructure:"request_duration_buckets" json:"request_duration_buckets,omitempty"`
+	TransactionAmountBuckets []float64 `mapstructure:"transaction_amount_buckets" json:"transaction_amount_buckets,omitempty"`
 }
 
 // HealthConfig represents health check configuration
@@ -116,6 +183,17 @@ type HealthConfig struct {
 	Endpoint string `mapstructure:"endpoint" json:"endpoint"`
 	Port     string `mapstructure:"port" json:"port"`
 	Interval string `mapstructure:"interval" json:"interval"`
+	Timeout  string `mapstructure:"timeout" json:"timeout"`
+}
+
+// CheckTimeout returns how long a single health check may run, or zero if
+// the timeout is not a valid duration
+func (c HealthConfig) CheckTimeout() time.Duration {
+	timeout, err := time.ParseDuration(c.Timeout)
+	if err != nil {
+		return 0
+	}
+	return timeout
 }
 
 // LoadOptions contains options for loading configuration
@@ -224,6 +302,10 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("redis.max_retries", 3)
 	v.SetDefault("redis.pool_size", 10)
 	v.SetDefault("redis.dial_timeout", 5*time.Second)
+	v.SetDefault("redis.breaker_threshold", 5)
+	v.SetDefault("redis.breaker_cooldown", 10*time.Second)
+	v.SetDefault("redis.tls", false)
+	v.SetDefault("redis.tls_server_name", "")
 
 	// Kafka defaults
 	v.SetDefault("kafka.brokers", "localhost:9092")
@@ -237,6 +319,12 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("kafka.auto_commit_interval", 5*time.Second)
 	v.SetDefault("kafka.producer_max_retries", 3)
 	v.SetDefault("kafka.producer_retry_backoff", 100*time.Millisecond)
+	v.SetDefault("kafka.producer_flush_timeout", 15*time.Second)
+	v.SetDefault("kafka.sasl_mechanism", "")
+	v.SetDefault("kafka.sasl_username", "")
+	v.SetDefault("kafka.sasl_password", "")
+	v.SetDefault("kafka.tls", false)
+	v.SetDefault("kafka.tls_ca_file", "")
 
 	// API defaults
 	v.SetDefault("api.host", "0.0.0.0")
@@ -246,11 +334,29 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("api.write_timeout", 10*time.Second)
 	v.SetDefault("api.shutdown_timeout", 30*time.Second)
 	v.SetDefault("api.cors_allowed_origins", []string{"*"})
+	v.SetDefault("api.cors_allow_credentials", true)
+	v.SetDefault("api.cors_max_age", 5*time.Minute)
+	v.SetDefault("api.strict_input_validation", false)
+	v.SetDefault("api.redacted_fields", []string{"private_key", "password", "password_hash"})
+	v.SetDefault("api.rate_limit_requests", 100)
+	v.SetDefault("api.rate_limit_window", 1*time.Minute)
+	v.SetDefault("api.allow_degraded_start", false)
+	v.SetDefault("api.max_body_size", 64*1024)
 
 	// Auth defaults
 	v.SetDefault("auth.jwt_secret", "your_jwt_secret_here")
 	v.SetDefault("auth.jwt_expiration_time", 24*time.Hour)
 	v.SetDefault("auth.refresh_token_duration", 7*24*time.Hour)
+	v.SetDefault("auth.time_oracle_secret", "")
+	v.SetDefault("auth.api_key_ttl", time.Duration(0))
+	v.SetDefault("auth.api_key_rotation_grace", 24*time.Hour)
+	v.SetDefault("auth.bcrypt_cost", 14)
+	v.SetDefault("auth.password_policy.min_length", 8)
+	v.SetDefault("auth.password_policy.require_upper", false)
+	v.SetDefault("auth.password_policy.require_lower", false)
+	v.SetDefault("auth.password_policy.require_digit", false)
+	v.SetDefault("auth.password_policy.require_symbol", false)
+	v.SetDefault("auth.password_policy.breached_passwords_file", "")
 
 	// Supply defaults
 	v.SetDefault("supply.min_inflation", 1.5)
@@ -258,11 +364,21 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("supply.max_step_size", 0.1)
 	v.SetDefault("supply.reserve_address", "system_reserve_address")
 	v.SetDefault("supply.adjust_interval", 24*time.Hour)
+	v.SetDefault("supply.protected_addresses", []string{})
+	v.SetDefault("supply.mint_interval", time.Duration(0))
+	v.SetDefault("supply.mint_state_path", "")
+
+	// Fee defaults
+	v.SetDefault("fee.rate", 0.001)
+	v.SetDefault("fee.min_fee", 0.01)
+	v.SetDefault("fee.collector_address", "fee_collector")
 
 	// Processor defaults
 	v.SetDefault("processor.batch_size", 100)
 	v.SetDefault("processor.poll_interval", 100*time.Millisecond)
 	v.SetDefault("processor.max_concurrency", 10)
+	v.SetDefault("processor.require_time_proof", []string{})
+	v.SetDefault("processor.settlement_workers", 1)
 
 	// Log defaults
 	v.SetDefault("log.level", "info")
@@ -271,6 +387,10 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("log.service_name", "stathera")
 	v.SetDefault("log.environment", "development")
 	v.SetDefault("log.include_trace", true)
+	v.SetDefault("log.sample_rate", 1)
+	v.SetDefault("log.access_log_exclude", []string{"/health", "/health/live", "/health/ready", "/metrics"})
+	v.SetDefault("log.fast_request_threshold", 100*time.Millisecond)
+	v.SetDefault("log.slow_request_threshold", time.Second)
 
 	// Metrics defaults
 	v.SetDefault("metrics.enabled", true)
@@ -284,6 +404,7 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("health.endpoint", "/health")
 	v.SetDefault("health.port", "8081")
 	v.SetDefault("health.interval", "30s")
+	v.SetDefault("health.timeout", "5s")
 
 	// Environment defaults
 	v.SetDefault("env", "development")
@@ -301,9 +422,13 @@ func bindFlags(v *viper.Viper, prefix string) error {
 	flags.String(prefix+"redis.address", "localhost:6379", "Redis server address")
 	flags.String(prefix+"redis.password", "", "Redis password")
 	flags.Int(prefix+"redis.db", 0, "Redis database number")
+	flags.Bool(prefix+"redis.tls", false, "Connect to Redis over TLS")
 
 	// Kafka flags
 	flags.String(prefix+"kafka.brokers", "localhost:9092", "Kafka broker addresses (comma-separated)")
+	flags.String(prefix+"kafka.sasl_mechanism", "", "Kafka SASL mechanism (PLAIN, SCRAM-SHA-256, SCRAM-SHA-512)")
+	flags.String(prefix+"kafka.sasl_username", "", "Kafka SASL username")
+	flags.Bool(prefix+"kafka.tls", false, "Connect to Kafka over TLS")
 
 	// API flags
 	flags.String(prefix+"api.port", "8080", "API server port")
@@ -311,6 +436,8 @@ func bindFlags(v *viper.Viper, prefix string) error {
 
 	// Auth flags
 	flags.String(prefix+"auth.jwt_secret", "", "JWT secret key")
+	flags.String(prefix+"auth.time_oracle_secret", "", "Time oracle HMAC secret (at least 32 bytes)")
+	flags.Int(prefix+"auth.bcrypt_cost", 14, "Password hashing cost (10-31)")
 
 	// Supply flags
 	flags.Float64(prefix+"supply.min_inflation", 1.5, "Minimum inflation rate")
@@ -318,12 +445,18 @@ func bindFlags(v *viper.Viper, prefix string) error {
 	flags.Float64(prefix+"supply.max_step_size", 0.1, "Maximum inflation adjustment step size")
 	flags.String(prefix+"supply.reserve_address", "system_reserve_address", "Reserve address for supply management")
 
+	// Fee flags
+	flags.Float64(prefix+"fee.rate", 0.001, "Transaction fee rate (fraction of amount)")
+	flags.Float64(prefix+"fee.min_fee", 0.01, "Minimum transaction fee")
+	flags.String(prefix+"fee.collector_address", "fee_collector", "Address credited with transaction fees")
+
 	// Log flags
 	flags.String(prefix+"log.level", "info", "Log level (debug, info, warn, error)")
 	flags.String(prefix+"log.format", "json", "Log format (json, text)")
 	flags.String(prefix+"log.service_name", "stathera", "Service name for logging")
 	flags.String(prefix+"log.environment", "development", "Environment for logging")
 	flags.Bool(prefix+"log.include_trace", true, "Include stack traces in error logs")
+	flags.Int(prefix+"log.sample_rate", 1, "Write one in every N debug and info log messages")
 
 	// Metrics flags
 	flags.Bool(prefix+"metrics.enabled", true, "Enable metrics collection")
@@ -337,6 +470,7 @@ func bindFlags(v *viper.Viper, prefix string) error {
 	flags.String(prefix+"health.endpoint", "/health", "Health check endpoint")
 	flags.String(prefix+"health.port", "8081", "Health check server port")
 	flags.String(prefix+"health.interval", "30s", "Health check interval")
+	flags.String(prefix+"health.timeout", "5s", "Time a single health check may take before it is reported down")
 
 	// Parse flags
 	if err := flags.Parse(os.Args[1:]); err != nil {
@@ -378,6 +512,18 @@ func validateConfig(cfg *Config) error {
 		validationErrors = append(validationErrors, "redis.dial_timeout must be positive")
 	}
 
+	if cfg.Redis.BreakerThreshold < 0 {
+		validationErrors = append(validationErrors, "redis.breaker_threshold must be non-negative")
+	}
+
+	if cfg.Redis.BreakerThreshold > 0 && cfg.Redis.BreakerCooldown <= 0 {
+		validationErrors = append(validationErrors, "redis.breaker_cooldown must be positive")
+	}
+
+	if cfg.Redis.TLSServerName != "" && !cfg.Redis.TLS {
+		validationErrors = append(validationErrors, "redis.tls_server_name requires redis.tls")
+	}
+
 	// Validate Kafka configuration
 	if cfg.Kafka.Brokers == "" {
 		validationErrors = append(validationErrors, "kafka.brokers cannot be empty")
@@ -415,6 +561,23 @@ func validateConfig(cfg *Config) error {
 		validationErrors = append(validationErrors, "kafka.producer_max_retries must be non-negative")
 	}
 
+	if cfg.Kafka.ProducerFlushTimeout <= 0 {
+		validationErrors = append(validationErrors, "kafka.producer_flush_timeout must be positive")
+	}
+
+	if cfg.Kafka.SASLMechanism != "" {
+		if !kafkaSASLMechanisms[cfg.Kafka.SASLMechanism] {
+			validationErrors = append(validationErrors, fmt.Sprintf("kafka.sasl_mechanism %q is not supported", cfg.Kafka.SASLMechanism))
+		}
+		if cfg.Kafka.SASLUsername == "" || cfg.Kafka.SASLPassword == "" {
+			validationErrors = append(validationErrors, "kafka.sasl_username and kafka.sasl_password must be set when kafka.sasl_mechanism is set")
+		}
+	}
+
+	if cfg.Kafka.TLSCAFile != "" && !cfg.Kafka.TLS {
+		validationErrors = append(validationErrors, "kafka.tls_ca_file requires kafka.tls")
+	}
+
 	// Validate API configuration
 	if cfg.API.Port == "" {
 		validationErrors = append(validationErrors, "api.port cannot be empty")
@@ -438,11 +601,59 @@ func validateConfig(cfg *Config) error {
 		validationErrors = append(validationErrors, "api.shutdown_timeout must be positive")
 	}
 
+	if cfg.API.MaxBodySize <= 0 {
+		validationErrors = append(validationErrors, "api.max_body_size must be positive")
+	}
+
+	for path, size := range cfg.API.RouteBodySizes {
+		if size <= 0 {
+			validationErrors = append(validationErrors, fmt.Sprintf("api.route_body_sizes[%s] must be positive", path))
+		}
+	}
+
+	if cfg.API.CORSMaxAge < 0 {
+		validationErrors = append(validationErrors, "api.cors_max_age must be non-negative")
+	}
+
+	// Browsers reject wildcard origins with credentials, and allowing any
+	// origin to send credentials is unsafe in production
+	if cfg.Env == "production" && cfg.API.CORSAllowCredentials && hasWildcardOrigin(cfg.API.CORSAllowedOrigins) {
+		validationErrors = append(validationErrors, "api.cors_allowed_origins cannot contain wildcards when api.cors_allow_credentials is enabled in production environment")
+	}
+
+	if cfg.API.RateLimitRequests <= 0 {
+		validationErrors = append(validationErrors, "api.rate_limit_requests must be positive")
+	}
+
+	if cfg.API.RateLimitWindow <= 0 {
+		validationErrors = append(validationErrors, "api.rate_limit_window must be positive")
+	}
+
+	for pattern, limit := range cfg.API.RouteRateLimits {
+		if _, err := path.Match(pattern, "/"); err != nil {
+			validationErrors = append(validationErrors, fmt.Sprintf("api.route_rate_limits[%s] is not a valid path pattern", pattern))
+		}
+		if limit.Requests <= 0 {
+			validationErrors = append(validationErrors, fmt.Sprintf("api.route_rate_limits[%s].requests must be positive", pattern))
+		}
+		if limit.Window <= 0 {
+			validationErrors = append(validationErrors, fmt.Sprintf("api.route_rate_limits[%s].window must be positive", pattern))
+		}
+	}
+
 	// Validate Auth configuration
 	if cfg.Env == "production" && cfg.Auth.JWTSecret == "your_jwt_secret_here" {
 		validationErrors = append(validationErrors, "auth.jwt_secret must be set in production environment")
 	}
 
+	if cfg.Env == "production" && cfg.Auth.TimeOracleSecret == "" {
+		validationErrors = append(validationErrors, "auth.time_oracle_secret must be set in production environment")
+	}
+
+	if cfg.Auth.TimeOracleSecret != "" && len(cfg.Auth.TimeOracleSecret) < 32 {
+		validationErrors = append(validationErrors, "auth.time_oracle_secret must be at least 32 bytes")
+	}
+
 	if cfg.Auth.JWTExpirationTime <= 0 {
 		validationErrors = append(validationErrors, "auth.jwt_expiration_time must be positive")
 	}
@@ -451,6 +662,22 @@ func validateConfig(cfg *Config) error {
 		validationErrors = append(validationErrors, "auth.refresh_token_duration must be positive")
 	}
 
+	if cfg.Auth.APIKeyTTL < 0 {
+		validationErrors = append(validationErrors, "auth.api_key_ttl must be non-negative")
+	}
+
+	if cfg.Auth.APIKeyRotationGrace < 0 {
+		validationErrors = append(validationErrors, "auth.api_key_rotation_grace must be non-negative")
+	}
+
+	if cfg.Auth.BcryptCost < 10 || cfg.Auth.BcryptCost > 31 {
+		validationErrors = append(validationErrors, "auth.bcrypt_cost must be between 10 and 31")
+	}
+
+	if cfg.Auth.PasswordPolicy.MinLength < 8 {
+		validationErrors = append(validationErrors, "auth.password_policy.min_length must be at least 8")
+	}
+
 	// Validate Supply configuration
 	if cfg.Supply.MinInflation < 0 {
 		validationErrors = append(validationErrors, "supply.min_inflation must be non-negative")
@@ -472,6 +699,39 @@ func validateConfig(cfg *Config) error {
 		validationErrors = append(validationErrors, "supply.adjust_interval must be positive")
 	}
 
+	for _, address := range cfg.Supply.ProtectedAddresses {
+		if address == "" {
+			validationErrors = append(validationErrors, "supply.protected_addresses cannot contain empty addresses")
+			break
+		}
+	}
+
+	if cfg.Supply.MintInterval != 0 && cfg.Supply.MintInterval < time.Second {
+		validationErrors = append(validationErrors, "supply.mint_interval must be 0 or at least 1s")
+	}
+
+	// Validate Fee configuration
+	if cfg.Fee.Rate < 0 || cfg.Fee.Rate >= 1 {
+		validationErrors = append(validationErrors, "fee.rate must be in the range [0, 1)")
+	}
+
+	if cfg.Fee.MinFee < 0 {
+		validationErrors = append(validationErrors, "fee.min_fee must be non-negative")
+	}
+
+	if cfg.Fee.CollectorAddress == "" {
+		validationErrors = append(validationErrors, "fee.collector_address is required")
+	}
+
+	for txType, address := range cfg.Fee.Collectors {
+		if !feeTransactionTypes[strings.ToUpper(txType)] {
+			validationErrors = append(validationErrors, fmt.Sprintf("fee.collectors has unknown transaction type %q", txType))
+		}
+		if address == "" {
+			validationErrors = append(validationErrors, fmt.Sprintf("fee.collectors.%s must not be empty", txType))
+		}
+	}
+
 	// Validate Processor configuration
 	if cfg.Processor.BatchSize <= 0 {
 		validationErrors = append(validationErrors, "processor.batch_size must be positive")
@@ -485,6 +745,16 @@ func validateConfig(cfg *Config) error {
 		validationErrors = append(validationErrors, "processor.max_concurrency must be positive")
 	}
 
+	if cfg.Processor.SettlementWorkers <= 0 {
+		validationErrors = append(validationErrors, "processor.settlement_workers must be positive")
+	}
+
+	for _, txType := range cfg.Processor.RequireTimeProof {
+		if !transactionTypes[strings.ToUpper(txType)] {
+			validationErrors = append(validationErrors, fmt.Sprintf("processor.require_time_proof has unknown transaction type %q", txType))
+		}
+	}
+
 	// Validate Log configuration
 	validLogLevels := map[string]bool{"debug": true, "info": true, "warn": true, "error": true}
 	if !validLogLevels[strings.ToLower(cfg.Log.Level)] {
@@ -500,6 +770,18 @@ func validateConfig(cfg *Config) error {
 		validationErrors = append(validationErrors, "log.service_name cannot be empty")
 	}
 
+	if cfg.Log.SampleRate <= 0 {
+		validationErrors = append(validationErrors, "log.sample_rate must be positive")
+	}
+
+	if cfg.Log.FastRequestThreshold <= 0 {
+		validationErrors = append(validationErrors, "log.fast_request_threshold must be positive")
+	}
+
+	if cfg.Log.SlowRequestThreshold <= cfg.Log.FastRequestThreshold {
+		validationErrors = append(validationErrors, "log.slow_request_threshold must be greater than log.fast_request_threshold")
+	}
+
 	// Validate Metrics configuration
 	if cfg.Metrics.Enabled {
 		if cfg.Metrics.Namespace == "" {
@@ -519,6 +801,14 @@ func validateConfig(cfg *Config) error {
 		} else if port, err := strconv.Atoi(cfg.Metrics.Port); err != nil || port <= 0 || port > 65535 {
 			validationErrors = append(validationErrors, "metrics.port must be a valid port number (1-65535)")
 		}
+
+		if !bucketsIncreasing(cfg.Metrics.RequestDurationBuckets) {
+			validationErrors = append(validationErrors, "metrics.request_duration_buckets must be in increasing order")
+		}
+
+		if !bucketsIncreasing(cfg.Metrics.TransactionAmountBuckets) {
+			validationErrors = append(validationErrors, "metrics.transaction_amount_buckets must be in increasing order")
+		}
 	}
 
 	// Validate Health configuration
@@ -538,6 +828,12 @@ func validateConfig(cfg *Config) error {
 		} else if _, err := time.ParseDuration(cfg.Health.Interval); err != nil {
 			validationErrors = append(validationErrors, fmt.Sprintf("invalid health.interval: %v", err))
 		}
+
+		if timeout, err := time.ParseDuration(cfg.Health.Timeout); err != nil {
+			validationErrors = append(validationErrors, fmt.Sprintf("invalid health.timeout: %v", err))
+		} else if timeout <= 0 {
+			validationErrors = append(validationErrors, "health.timeout must be positive")
+		}
 	}
 
 	// Return validation errors if any
@@ -548,6 +844,71 @@ func validateConfig(cfg *Config) error {
 	return nil
 }
 
+// bucketsIncreasing reports whether histogram buckets are strictly increasing.
+// An empty list is allowed and means the default buckets are used.
+func bucketsIncreasing(buckets []float64) bool {
+	for i := 1; i < len(buckets); i++ {
+		if buckets[i] <= buckets[i-1] {
+			return false
+		}
+	}
+	return true
+}
+
+// hasWildcardOrigin reports whether any CORS origin pattern contains a wildcard
+func hasWildcardOrigin(origins []string) bool {
+	for _, origin := range origins {
+		if strings.Contains(origin, "*") {
+			return true
+		}
+	}
+	return false
+}
+
+// ProtectedAddresses returns the system accounts that ordinary payments may not
+// debit: the reserve, every fee collector and any additionally configured addresses
+func (c *Config) ProtectedAddresses() []string {
+	seen := make(map[string]bool)
+	var addresses []string
+	add := func(address string) {
+		if address != "" && !seen[address] {
+			seen[address] = true
+			addresses = append(addresses, address)
+		}
+	}
+
+	add(c.Supply.ReserveAddress)
+	add(c.Fee.CollectorAddress)
+	for _, address := range c.Fee.Collectors {
+		add(address)
+	}
+	for _, address := range c.Supply.ProtectedAddresses {
+		add(address)
+	}
+
+	return addresses
+}
+
+// feeTransactionTypes lists the transaction types that may have their own fee collector
+var feeTransactionTypes = map[string]bool{
+	"PAYMENT":      true,
+	"DEPOSIT":      true,
+	"WITHDRAWAL":   true,
+	"REFUND":       true,
+	"DISTRIBUTION": true,
+}
+
+// transactionTypes lists every transaction type the engine processes
+var transactionTypes = map[string]bool{
+	"PAYMENT":         true,
+	"DEPOSIT":         true,
+	"WITHDRAWAL":      true,
+	"FEE":             true,
+	"SUPPLY_INCREASE": true,
+	"REFUND":          true,
+	"DISTRIBUTION":    true,
+}
+
 // SaveToFile saves the configuration to a file
 func SaveToFile(cfg *Config, filePath string) error {
 	// Create directory if it doesn't exist
@@ -562,7 +923,9 @@ func SaveToFile(cfg *Config, filePath string) error {
 
 	switch strings.ToLower(filepath.Ext(filePath)) {
 	case ".json":
-		data, err = json.MarshalIndent(cfg, "", "  ")
+		data, err = marshalJSON(cfg)
+	case ".yaml", ".yml":
+		data, err = marshalYAML(cfg)
 	default:
 		return fmt.Errorf("unsupported file format: %s", filepath.Ext(filePath))
 	}
@@ -595,17 +958,26 @@ func LoadFromFile(filePath string) (*Config, error) {
 	}
 
 	// Determine file format based on extension
-	var cfg Config
+	var raw map[string]interface{}
 
 	switch strings.ToLower(filepath.Ext(filePath)) {
 	case ".json":
-		if err := json.Unmarshal(data, &cfg); err != nil {
+		if err := json.Unmarshal(data, &raw); err != nil {
 			return nil, fmt.Errorf("failed to unmarshal JSON config: %w", err)
 		}
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, &raw); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal YAML config: %w", err)
+		}
 	default:
 		return nil, fmt.Errorf("unsupported file format: %s", filepath.Ext(filePath))
 	}
 
+	var cfg Config
+	if err := decodeConfig(raw, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to decode config: %w", err)
+	}
+
 	// Validate config
 	if err := validateConfig(&cfg); err != nil {
 		return nil, fmt.Errorf("config validation error: %w", err)
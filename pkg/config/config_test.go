@@ -0,0 +1,264 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func defaultConfig(t *testing.T) *Config {
+	t.Helper()
+
+	cfg, err := LoadWithOptions(LoadOptions{})
+	if err != nil {
+		t.Fatalf("LoadWithOptions: %v", err)
+	}
+	return cfg
+}
+
+func TestSaveAndLoadRoundTrip(t *testing.T) {
+	tests := []struct {
+		file     string
+		duration string
+	}{
+		{file: "config.yaml", duration: "dial_timeout: 5s"},
+		{file: "config.yml", duration: "dial_timeout: 5s"},
+		{file: "config.json", duration: `"dial_timeout": "5s"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			cfg := defaultConfig(t)
+			cfg.Redis.DialTimeout = 5 * time.Second
+			cfg.Auth.TimeOracleSecret = strings.Repeat("s", 32)
+			cfg.API.RouteRateLimits = map[string]RouteRateLimit{
+				"/transfer": {Requests: 10, Window: time.Minute},
+			}
+
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := SaveToFile(cfg, path); err != nil {
+				t.Fatalf("SaveToFile: %v", err)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("ReadFile: %v", err)
+			}
+			if !strings.Contains(string(data), tt.duration) {
+				t.Fatalf("saved config does not contain %q:\n%s", tt.duration, data)
+			}
+
+			loaded, err := LoadFromFile(path)
+			if err != nil {
+				t.Fatalf("LoadFromFile: %v", err)
+			}
+			if !reflect.DeepEqual(loaded, cfg) {
+				t.Fatalf("loaded config differs from saved config\nsaved:  %+v\nloaded: %+v", cfg, loaded)
+			}
+		})
+	}
+}
+
+func TestLoadFromFileDurations(t *testing.T) {
+	tests := []struct {
+		name  string
+		file  string
+		saved string
+		value string
+		want  time.Duration
+	}{
+		{name: "yaml string", file: "config.yaml", saved: "dial_timeout: 5s", value: "dial_timeout: 2s", want: 2 * time.Second},
+		{name: "yaml nanoseconds", file: "config.yaml", saved: "dial_timeout: 5s", value: "dial_timeout: 2000000000", want: 2 * time.Second},
+		{name: "json string", file: "config.json", saved: `"dial_timeout": "5s"`, value: `"dial_timeout": "1m"`, want: time.Minute},
+		{name: "json nanoseconds", file: "config.json", saved: `"dial_timeout": "5s"`, value: `"dial_timeout": 3000000000`, want: 3 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := SaveToFile(defaultConfig(t), path); err != nil {
+				t.Fatalf("SaveToFile: %v", err)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("ReadFile: %v", err)
+			}
+			edited := strings.Replace(string(data), tt.saved, tt.value, 1)
+			if edited == string(data) {
+				t.Fatalf("saved config does not contain %q", tt.saved)
+			}
+			if err := os.WriteFile(path, []byte(edited), 0644); err != nil {
+				t.Fatalf("WriteFile: %v", err)
+			}
+
+			cfg, err := LoadFromFile(path)
+			if err != nil {
+				t.Fatalf("LoadFromFile: %v", err)
+			}
+			if cfg.Redis.DialTimeout != tt.want {
+				t.Fatalf("DialTimeout = %v, want %v", cfg.Redis.DialTimeout, tt.want)
+			}
+		})
+	}
+}
+
+func TestSavedProductionConfigLoads(t *testing.T) {
+	cfg := defaultConfig(t)
+	cfg.Env = "production"
+	cfg.Auth.JWTSecret = strings.Repeat("j", 32)
+	cfg.Auth.TimeOracleSecret = strings.Repeat("o", 32)
+	cfg.API.CORSAllowedOrigins = []string{"https://app.example.com"}
+	if err := validateConfig(cfg); err != nil {
+		t.Fatalf("validateConfig: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := SaveToFile(cfg, path); err != nil {
+		t.Fatalf("SaveToFile: %v", err)
+	}
+
+	loaded, err := LoadFromFile(path)
+	if err != nil {
+		t.Fatalf("LoadFromFile: %v", err)
+	}
+	if loaded.Auth.TimeOracleSecret != cfg.Auth.TimeOracleSecret {
+		t.Fatalf("TimeOracleSecret = %q, want %q", loaded.Auth.TimeOracleSecret, cfg.Auth.TimeOracleSecret)
+	}
+}
+
+func TestProtectedAddresses(t *testing.T) {
+	tests := []struct {
+		name   string
+		supply SupplyConfig
+		fee    FeeConfig
+		want   []string
+	}{
+		{
+			name:   "reserve and collector",
+			supply: SupplyConfig{ReserveAddress: "RESERVE"},
+			fee:    FeeConfig{CollectorAddress: "FEES"},
+			want:   []string{"RESERVE", "FEES"},
+		},
+		{
+			name:   "per-type collectors and extra addresses",
+			supply: SupplyConfig{ReserveAddress: "RESERVE", ProtectedAddresses: []string{"TREASURY"}},
+			fee:    FeeConfig{CollectorAddress: "FEES", Collectors: map[string]string{"WITHDRAWAL": "WITHDRAWAL_FEES"}},
+			want:   []string{"RESERVE", "FEES", "WITHDRAWAL_FEES", "TREASURY"},
+		},
+		{
+			name:   "duplicates and empty addresses are dropped",
+			supply: SupplyConfig{ReserveAddress: "RESERVE", ProtectedAddresses: []string{"FEES", ""}},
+			fee:    FeeConfig{CollectorAddress: "FEES", Collectors: map[string]string{"PAYMENT": "RESERVE"}},
+			want:   []string{"RESERVE", "FEES"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Supply: tt.supply, Fee: tt.fee}
+			if got := cfg.ProtectedAddresses(); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("ProtectedAddresses = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateRouteRateLimits(t *testing.T) {
+	tests := []struct {
+		name    string
+		limits  map[string]RouteRateLimit
+		wantErr string
+	}{
+		{name: "valid", limits: map[string]RouteRateLimit{"/transactions/*": {Requests: 10, Window: time.Minute}}},
+		{name: "invalid pattern", limits: map[string]RouteRateLimit{"/transactions/[": {Requests: 10, Window: time.Minute}}, wantErr: "is not a valid path pattern"},
+		{name: "no requests", limits: map[string]RouteRateLimit{"/login": {Window: time.Minute}}, wantErr: "api.route_rate_limits[/login].requests must be positive"},
+		{name: "no window", limits: map[string]RouteRateLimit{"/login": {Requests: 5}}, wantErr: "api.route_rate_limits[/login].window must be positive"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := defaultConfig(t)
+			cfg.API.RouteRateLimits = tt.limits
+
+			err := validateConfig(cfg)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("validateConfig: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("validateConfig = %v, want an error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidatePasswordHashing(t *testing.T) {
+	tests := []struct {
+		name      string
+		cost      int
+		minLength int
+		wantErr   string
+	}{
+		{name: "valid", cost: 12, minLength: 12},
+		{name: "lowest cost", cost: 10, minLength: 8},
+		{name: "cost too low", cost: 9, minLength: 12, wantErr: "auth.bcrypt_cost must be between 10 and 31"},
+		{name: "cost too high", cost: 32, minLength: 12, wantErr: "auth.bcrypt_cost must be between 10 and 31"},
+		{name: "short passwords", cost: 12, minLength: 7, wantErr: "auth.password_policy.min_length must be at least 8"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := defaultConfig(t)
+			cfg.Auth.BcryptCost = tt.cost
+			cfg.Auth.PasswordPolicy.MinLength = tt.minLength
+
+			err := validateConfig(cfg)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("validateConfig: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("validateConfig = %v, want an error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateMintInterval(t *testing.T) {
+	tests := []struct {
+		name     string
+		interval time.Duration
+		wantErr  bool
+	}{
+		{name: "disabled", interval: 0},
+		{name: "daily", interval: 24 * time.Hour},
+		{name: "below a second", interval: 500 * time.Millisecond, wantErr: true},
+		{name: "negative", interval: -time.Hour, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := defaultConfig(t)
+			cfg.Supply.MintInterval = tt.interval
+
+			err := validateConfig(cfg)
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "supply.mint_interval") {
+					t.Fatalf("validateConfig = %v, want a supply.mint_interval error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("validateConfig: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+	"gopkg.in/yaml.v3"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// orderedFields is an encoded struct whose fields keep their declaration
+// order in both JSON and YAML output
+type orderedFields []orderedField
+
+// orderedField is a single encoded struct field
+type orderedField struct {
+	name  string
+	value interface{}
+}
+
+// MarshalJSON encodes the fields as a JSON object in order
+func (f orderedFields) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, field := range f {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, err := json.Marshal(field.name)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(field.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// MarshalYAML encodes the fields as a YAML mapping in order
+func (f orderedFields) MarshalYAML() (interface{}, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for _, field := range f {
+		var value yaml.Node
+		if err := value.Encode(field.value); err != nil {
+			return nil, err
+		}
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: field.name}, &value)
+	}
+	return node, nil
+}
+
+// encodeValue converts a configuration value into a tree that encodes with
+// the mapstructure field names and durations written as strings such as
+// "5s", the form LoadFromFile and viper read back
+func encodeValue(v reflect.Value) interface{} {
+	if v.Type() == durationType {
+		return time.Duration(v.Int()).String()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		return encodeStruct(v)
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		encoded := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			encoded[iter.Key().String()] = encodeValue(iter.Value())
+		}
+		return encoded
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil
+		}
+		encoded := make([]interface{}, v.Len())
+		for i := range encoded {
+			encoded[i] = encodeValue(v.Index(i))
+		}
+		return encoded
+	default:
+		return v.Interface()
+	}
+}
+
+// encodeStruct encodes the exported fields of a struct. Fields tagged
+// json:"-" are skipped and json omitempty is honoured, so both file formats
+// carry the same fields.
+func encodeStruct(v reflect.Value) orderedFields {
+	t := v.Type()
+	fields := make(orderedFields, 0, v.NumField())
+	for i := 0; i < v.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		jsonTag := field.Tag.Get("json")
+		if jsonTag == "-" {
+			continue
+		}
+		if strings.Contains(jsonTag, ",omitempty") && v.Field(i).IsZero() {
+			continue
+		}
+
+		name := field.Tag.Get("mapstructure")
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		fields = append(fields, orderedField{name: name, value: encodeValue(v.Field(i))})
+	}
+	return fields
+}
+
+// marshalYAML encodes the configuration as YAML in field order
+func marshalYAML(cfg *Config) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(encodeStruct(reflect.ValueOf(cfg).Elem())); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// marshalJSON encodes the configuration as indented JSON in field order
+func marshalJSON(cfg *Config) ([]byte, error) {
+	return json.MarshalIndent(encodeStruct(reflect.ValueOf(cfg).Elem()), "", "  ")
+}
+
+// decodeConfig decodes a parsed configuration file the way viper does, using
+// the mapstructure field names and accepting durations such as "5s"
+func decodeConfig(raw interface{}, cfg *Config) error {
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook: mapstructure.ComposeDecodeHookFunc(
+			mapstructure.StringToTimeDurationHookFunc(),
+			mapstructure.StringToSliceHookFunc(","),
+		),
+		WeaklyTypedInput: true,
+		Result:           cfg,
+	})
+	if err != nil {
+		return err
+	}
+
+	return decoder.Decode(raw)
+}
@@ -0,0 +1,44 @@
+package config
+
+import "time"
+
+// kafkaSASLMechanisms lists the supported SASL mechanisms
+var kafkaSASLMechanisms = map[string]bool{
+	"PLAIN":         true,
+	"SCRAM-SHA-256": true,
+	"SCRAM-SHA-512": true,
+}
+
+// ClientSettings returns librdkafka client settings for the brokers and the
+// configured SASL and TLS security options
+func (c KafkaConfig) ClientSettings() map[string]string {
+	settings := map[string]string{
+		"bootstrap.servers": c.Brokers,
+	}
+
+	switch {
+	case c.SASLMechanism != "" && c.TLS:
+		settings["security.protocol"] = "SASL_SSL"
+	case c.SASLMechanism != "":
+		settings["security.protocol"] = "SASL_PLAINTEXT"
+	case c.TLS:
+		settings["security.protocol"] = "SSL"
+	}
+
+	if c.SASLMechanism != "" {
+		settings["sasl.mechanisms"] = c.SASLMechanism
+		settings["sasl.username"] = c.SASLUsername
+		settings["sasl.password"] = c.SASLPassword
+	}
+	if c.TLS && c.TLSCAFile != "" {
+		settings["ssl.ca.location"] = c.TLSCAFile
+	}
+
+	return settings
+}
+
+// FlushTimeoutMs returns the producer flush timeout in milliseconds, as
+// expected by the producer's Flush
+func (c KafkaConfig) FlushTimeoutMs() int {
+	return int(c.ProducerFlushTimeout / time.Millisecond)
+}
@@ -0,0 +1,55 @@
+package config
+
+import "reflect"
+
+// redactedMask replaces the value of secret configuration fields
+const redactedMask = "***"
+
+// Redacted returns a copy of the configuration with every field tagged
+// `secret:"true"` masked, suitable for logging or exposing over an API.
+// Empty secrets are left empty so it remains visible that they are unset.
+func (c Config) Redacted() Config {
+	redacted := c
+	redactStruct(reflect.ValueOf(&redacted).Elem())
+	return redacted
+}
+
+// redactStruct masks secret fields of a struct value in place, recursing into nested structs
+func redactStruct(v reflect.Value) {
+	t := v.Type()
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		if !field.CanSet() {
+			continue
+		}
+
+		if t.Field(i).Tag.Get("secret") == "true" {
+			redactValue(field)
+			continue
+		}
+
+		if field.Kind() == reflect.Struct {
+			redactStruct(field)
+		}
+	}
+}
+
+// redactValue masks a secret string or string slice
+func redactValue(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.String:
+		if v.Len() > 0 {
+			v.SetString(redactedMask)
+		}
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.String {
+			return
+		}
+		// Copy so the original slice is not modified
+		masked := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			masked.Index(i).SetString(redactedMask)
+		}
+		v.Set(masked)
+	}
+}
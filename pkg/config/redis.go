@@ -0,0 +1,28 @@
+package config
+
+import (
+	"crypto/tls"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// ClientOptions returns go-redis client options built from the Redis configuration
+func (c RedisConfig) ClientOptions() *redis.Options {
+	opts := &redis.Options{
+		Addr:        c.Address,
+		Password:    c.Password,
+		DB:          c.DB,
+		MaxRetries:  c.MaxRetries,
+		PoolSize:    c.PoolSize,
+		DialTimeout: c.DialTimeout,
+	}
+
+	if c.TLS {
+		opts.TLSConfig = &tls.Config{
+			MinVersion: tls.VersionTLS12,
+			ServerName: c.TLSServerName,
+		}
+	}
+
+	return opts
+}
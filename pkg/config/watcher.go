@@ -0,0 +1,126 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// Watcher reloads the configuration on SIGHUP and notifies subscribers.
+// A configuration that fails to load or validate is rejected and the
+// current configuration is kept.
+type Watcher struct {
+	mu        sync.RWMutex
+	opts      LoadOptions
+	current   *Config
+	callbacks []func(*Config)
+	onError   func(error)
+	sigCh     chan os.Signal
+	stopCh    chan struct{}
+	wg        sync.WaitGroup
+}
+
+// NewWatcher creates a new configuration watcher starting from the given configuration
+func NewWatcher(opts LoadOptions, current *Config) *Watcher {
+	return &Watcher{
+		opts:    opts,
+		current: current,
+	}
+}
+
+// OnChange registers a callback invoked with each successfully reloaded configuration
+func (w *Watcher) OnChange(fn func(*Config)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.callbacks = append(w.callbacks, fn)
+}
+
+// OnError registers a callback invoked when a reload is rejected
+func (w *Watcher) OnError(fn func(error)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.onError = fn
+}
+
+// Current returns the active configuration
+func (w *Watcher) Current() *Config {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.current
+}
+
+// Reload re-reads the configuration and, if it is valid, applies it and
+// notifies subscribers
+func (w *Watcher) Reload() error {
+	cfg, err := LoadWithOptions(w.opts)
+	if err != nil {
+		return fmt.Errorf("config reload rejected: %w", err)
+	}
+
+	w.mu.Lock()
+	w.current = cfg
+	callbacks := make([]func(*Config), len(w.callbacks))
+	copy(callbacks, w.callbacks)
+	w.mu.Unlock()
+
+	// Notify subscribers outside the lock
+	for _, fn := range callbacks {
+		fn(cfg)
+	}
+
+	return nil
+}
+
+// Start begins reloading the configuration whenever the process receives SIGHUP
+func (w *Watcher) Start() {
+	w.mu.Lock()
+	if w.stopCh != nil {
+		w.mu.Unlock()
+		return
+	}
+	w.sigCh = make(chan os.Signal, 1)
+	w.stopCh = make(chan struct{})
+	sigCh, stopCh := w.sigCh, w.stopCh
+	w.mu.Unlock()
+
+	signal.Notify(sigCh, syscall.SIGHUP)
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+
+		for {
+			select {
+			case <-sigCh:
+				if err := w.Reload(); err != nil {
+					w.mu.RLock()
+					onError := w.onError
+					w.mu.RUnlock()
+
+					if onError != nil {
+						onError(err)
+					}
+				}
+			case <-stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops watching for SIGHUP
+func (w *Watcher) Stop() {
+	w.mu.Lock()
+	if w.stopCh == nil {
+		w.mu.Unlock()
+		return
+	}
+	signal.Stop(w.sigCh)
+	close(w.stopCh)
+	w.stopCh = nil
+	w.mu.Unlock()
+
+	w.wg.Wait()
+}
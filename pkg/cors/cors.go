@@ -0,0 +1,39 @@
+// Package cors builds CORS middleware from the API configuration so every
+// HTTP server applies the same cross-origin policy.
+package cors
+
+import (
+	"net/http"
+
+	"github.com/go-chi/cors"
+
+	"github.com/cmatc13/stathera/pkg/config"
+)
+
+var (
+	// allowedMethods are the methods cross-origin requests may use
+	allowedMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
+
+	// allowedHeaders are the request headers cross-origin requests may send
+	allowedHeaders = []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "X-API-Key", "Idempotency-Key"}
+
+	// exposedHeaders are the response headers browsers may read
+	exposedHeaders = []string{"Link", "X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset", "Retry-After", "Idempotent-Replayed"}
+)
+
+// NewOptions returns the CORS options for the given API configuration
+func NewOptions(cfg config.APIConfig) cors.Options {
+	return cors.Options{
+		AllowedOrigins:   cfg.CORSAllowedOrigins,
+		AllowedMethods:   allowedMethods,
+		AllowedHeaders:   allowedHeaders,
+		ExposedHeaders:   exposedHeaders,
+		AllowCredentials: cfg.CORSAllowCredentials,
+		MaxAge:           int(cfg.CORSMaxAge.Seconds()),
+	}
+}
+
+// Handler returns CORS middleware for the given API configuration
+func Handler(cfg config.APIConfig) func(http.Handler) http.Handler {
+	return cors.Handler(NewOptions(cfg))
+}
@@ -0,0 +1,57 @@
+package cors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cmatc13/stathera/pkg/config"
+)
+
+func TestHandler(t *testing.T) {
+	cfg := config.APIConfig{
+		CORSAllowedOrigins:   []string{"https://app.example.com"},
+		CORSAllowCredentials: true,
+		CORSMaxAge:           time.Hour,
+	}
+
+	tests := []struct {
+		name        string
+		origin      string
+		header      string
+		wantAllowed bool
+	}{
+		{name: "allowed origin", origin: "https://app.example.com", header: "Content-Type", wantAllowed: true},
+		{name: "idempotency key", origin: "https://app.example.com", header: "Idempotency-Key", wantAllowed: true},
+		{name: "other origin", origin: "https://evil.example.com", header: "Content-Type"},
+		{name: "unlisted header", origin: "https://app.example.com", header: "X-Unlisted"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := Handler(cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+			r := httptest.NewRequest(http.MethodOptions, "/api/v1/transfer", nil)
+			r.Header.Set("Origin", tt.origin)
+			r.Header.Set("Access-Control-Request-Method", http.MethodPost)
+			r.Header.Set("Access-Control-Request-Headers", tt.header)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			allowed := w.Header().Get("Access-Control-Allow-Origin") == tt.origin
+			if allowed != tt.wantAllowed {
+				t.Fatalf("preflight allowed = %v, want %v (headers %v)", allowed, tt.wantAllowed, w.Header())
+			}
+			if !tt.wantAllowed {
+				return
+			}
+			if got := w.Header().Get("Access-Control-Allow-Credentials"); got != "true" {
+				t.Fatalf("Access-Control-Allow-Credentials = %q, want true", got)
+			}
+			if got := w.Header().Get("Access-Control-Max-Age"); got != "3600" {
+				t.Fatalf("Access-Control-Max-Age = %q, want 3600", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+// pkg/errors/json.go
+package errors
+
+import (
+	"encoding/json"
+	"sync/atomic"
+)
+
+// includeDetails controls whether serialized errors carry fields, causes and stack traces
+var includeDetails atomic.Bool
+
+// SetEnvironment configures error serialization for the environment the
+// service runs in. Fields, causes and stack traces are only serialized
+// outside production.
+func SetEnvironment(env string) {
+	includeDetails.Store(env != "production")
+}
+
+// errorJSON is the JSON representation of a domain error
+type errorJSON struct {
+	Code      string                 `json:"code,omitempty"`
+	Domain    string                 `json:"domain,omitempty"`
+	Operation string                 `json:"operation,omitempty"`
+	Message   string                 `json:"message,omitempty"`
+	Fields    map[string]interface{} `json:"fields,omitempty"`
+	Cause     string                 `json:"cause,omitempty"`
+	Stack     string                 `json:"stack,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler
+func (e *Error) MarshalJSON() ([]byte, error) {
+	out := errorJSON{
+		Code:      e.Code,
+		Domain:    e.Domain,
+		Operation: e.Operation,
+		Message:   e.Message,
+	}
+
+	if includeDetails.Load() {
+		out.Fields = e.Fields
+		out.Stack = e.Stack
+		if e.Original != nil {
+			out.Cause = e.Original.Error()
+		}
+	}
+
+	return json.Marshal(out)
+}
+
+// Response is the API response envelope for a domain error
+type Response struct {
+	Success bool   `json:"success"`
+	Data    *Error `json:"data,omitempty"`
+	Error   string `json:"error,omitempty"`
+	Code    string `json:"code,omitempty"`
+}
+
+// ToResponse returns the API response envelope for the error
+func (e *Error) ToResponse() Response {
+	return Response{
+		Success: false,
+		Data:    e,
+		Error:   e.Message,
+		Code:    e.Code,
+	}
+}
@@ -0,0 +1,79 @@
+// pkg/errors/json_test.go
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func TestMarshalJSON(t *testing.T) {
+	err := &Error{
+		Original:  New("connection refused"),
+		Domain:    StorageDomain,
+		Code:      "STORAGE_UNAVAILABLE",
+		Message:   "storage is unavailable",
+		Operation: "Get",
+		Fields:    map[string]interface{}{"key": "balance:alice"},
+		Stack:     "main.go:1 main.main\n",
+	}
+
+	tests := []struct {
+		env         string
+		wantDetails bool
+	}{
+		{env: "production"},
+		{env: "development", wantDetails: true},
+		{env: "staging", wantDetails: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.env, func(t *testing.T) {
+			SetEnvironment(tt.env)
+			defer SetEnvironment("production")
+
+			data, marshalErr := json.Marshal(err)
+			if marshalErr != nil {
+				t.Fatalf("Marshal: %v", marshalErr)
+			}
+			var got errorJSON
+			if err := json.Unmarshal(data, &got); err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+
+			if got.Code != err.Code || got.Domain != err.Domain || got.Operation != err.Operation || got.Message != err.Message {
+				t.Fatalf("serialized error = %s, missing its code, domain, operation or message", data)
+			}
+			hasDetails := got.Fields != nil || got.Cause != "" || got.Stack != ""
+			if hasDetails != tt.wantDetails {
+				t.Fatalf("serialized error = %s, details included = %v, want %v", data, hasDetails, tt.wantDetails)
+			}
+			if tt.wantDetails && got.Cause != "connection refused" {
+				t.Fatalf("cause = %q, want the original error", got.Cause)
+			}
+		})
+	}
+}
+
+func TestHTTPStatusFromAPIError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "bad request", err: NewAPIError(APIErrBadRequest, "bad", nil), want: 400},
+		{name: "expired token", err: NewAPIError(APIErrJWTExpired, "expired", nil), want: 401},
+		{name: "not found", err: NewAPIError(APIErrNotFound, "missing", nil), want: 404},
+		{name: "wrapped", err: fmt.Errorf("handler: %w", NewAPIError(APIErrForbidden, "no", nil)), want: 403},
+		{name: "other domain", err: NewStorageError("STORAGE_UNAVAILABLE", "down", nil), want: 500},
+		{name: "plain error", err: New("boom"), want: 500},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HTTPStatusFromAPIError(tt.err); got != tt.want {
+				t.Fatalf("HTTPStatusFromAPIError = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
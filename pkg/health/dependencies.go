@@ -0,0 +1,113 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/go-redis/redis/v8"
+
+	"github.com/cmatc13/stathera/pkg/breaker"
+)
+
+// DefaultPingTimeout is the timeout applied to dependency pings when the
+// caller's context has no earlier deadline.
+const DefaultPingTimeout = 2 * time.Second
+
+// RealRedisChecker creates a health check that pings Redis using the given client.
+func RealRedisChecker(client *redis.Client) Checker {
+	return RedisChecker(client.Options().Addr, func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, DefaultPingTimeout)
+		defer cancel()
+
+		return client.Ping(ctx).Err()
+	})
+}
+
+// RealKafkaChecker creates a health check that requests cluster metadata from
+// the given Kafka brokers. The admin client is created on first use and reused.
+func RealKafkaChecker(brokers string) Checker {
+	return RealKafkaCheckerWithSettings(map[string]string{"bootstrap.servers": brokers})
+}
+
+// RealKafkaCheckerWithSettings is like RealKafkaChecker but creates the admin
+// client with the given client settings, such as SASL and TLS options.
+func RealKafkaCheckerWithSettings(settings map[string]string) Checker {
+	var (
+		mu     sync.Mutex
+		client *kafka.AdminClient
+	)
+
+	configMap := kafka.ConfigMap{}
+	for key, value := range settings {
+		configMap[key] = value
+	}
+
+	return KafkaChecker(settings["bootstrap.servers"], func(ctx context.Context) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if client == nil {
+			c, err := kafka.NewAdminClient(&configMap)
+			if err != nil {
+				return fmt.Errorf("failed to create Kafka admin client: %w", err)
+			}
+			client = c
+		}
+
+		// Use the earlier of the context deadline and the default timeout
+		timeout := DefaultPingTimeout
+		if deadline, ok := ctx.Deadline(); ok {
+			if remaining := time.Until(deadline); remaining < timeout {
+				timeout = remaining
+			}
+		}
+		if timeout <= 0 {
+			return ctx.Err()
+		}
+
+		metadata, err := client.GetMetadata(nil, false, int(timeout.Milliseconds()))
+		if err != nil {
+			return fmt.Errorf("failed to get Kafka metadata: %w", err)
+		}
+		if len(metadata.Brokers) == 0 {
+			return fmt.Errorf("no Kafka brokers available")
+		}
+
+		return nil
+	})
+}
+
+// TimedChecker wraps a checker and reports how long each check took.
+// It is typically used to feed the DependencyLatency metric.
+func TimedChecker(checker Checker, record func(duration time.Duration)) Checker {
+	return func(ctx context.Context) Check {
+		start := time.Now()
+		check := checker(ctx)
+		record(time.Since(start))
+		return check
+	}
+}
+
+// CircuitBreakerChecker creates a health check reporting the state of a
+// circuit breaker. The check is down while the circuit is open.
+func CircuitBreakerChecker(name string, b *breaker.Breaker) Checker {
+	return func(ctx context.Context) Check {
+		state := b.State()
+		check := Check{
+			Name:        name,
+			Status:      StatusUp,
+			Message:     fmt.Sprintf("Circuit breaker %s is %s", name, state),
+			LastChecked: time.Now(),
+		}
+
+		if state == breaker.Open {
+			check.Status = StatusDown
+			check.Error = breaker.ErrUnavailable
+		}
+
+		return check
+	}
+}
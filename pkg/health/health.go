@@ -24,6 +24,26 @@ const (
 	StatusUnknown Status = "UNKNOWN"
 )
 
+// Probe identifies which kind of probe a health check answers.
+type Probe string
+
+const (
+	// ProbeLiveness checks report whether the process itself is working. They
+	// must not depend on other services, since a failing liveness probe gets
+	// the process restarted.
+	ProbeLiveness Probe = "liveness"
+	// ProbeReadiness checks report whether the dependencies needed to serve
+	// traffic are available.
+	ProbeReadiness Probe = "readiness"
+)
+
+// DefaultCheckTimeout is how long a single check may run before it is reported down.
+const DefaultCheckTimeout = 5 * time.Second
+
+// handlerTimeoutMargin is how much longer than the check timeout Handler waits
+// for all checks to finish.
+const handlerTimeoutMargin = time.Second
+
 // Check represents a health check for a component.
 type Check struct {
 	// Name is the name of the component being checked.
@@ -36,6 +56,18 @@ type Check struct {
 	LastChecked time.Time
 	// Error is an optional error that occurred during the health check.
 	Error error
+	// Duration is how long the check took, set by Registry.RunChecks.
+	Duration time.Duration
+}
+
+// BuildInfo identifies the build of the running binary.
+type BuildInfo struct {
+	// Version is the release version.
+	Version string `json:"version"`
+	// Commit is the source revision the binary was built from.
+	Commit string `json:"commit"`
+	// BuildDate is when the binary was built.
+	BuildDate string `json:"build_date,omitempty"`
 }
 
 // MarshalJSON implements the json.Marshaler interface.
@@ -51,40 +83,61 @@ func (c Check) MarshalJSON() ([]byte, error) {
 		Message     string    `json:"message,omitempty"`
 		LastChecked time.Time `json:"last_checked"`
 		Error       string    `json:"error,omitempty"`
+		DurationMS  float64   `json:"duration_ms"`
 	}{
 		Name:        c.Name,
 		Status:      c.Status,
 		Message:     c.Message,
 		LastChecked: c.LastChecked,
 		Error:       errorStr,
+		DurationMS:  float64(c.Duration) / float64(time.Millisecond),
 	})
 }
 
 // Checker defines a function that performs a health check.
 type Checker func(ctx context.Context) Check
 
+// registeredCheck is a health check and the probe it answers.
+type registeredCheck struct {
+	checker Checker
+	probe   Probe
+}
+
 // Registry manages health checks for the application.
 type Registry struct {
-	checks map[string]Checker
-	mutex  sync.RWMutex
-	logger *logging.Logger
+	checks    map[string]registeredCheck
+	mutex     sync.RWMutex
+	logger    *logging.Logger
+	buildInfo BuildInfo
+	timeout   time.Duration
 }
 
 // NewRegistry creates a new health check registry.
 func NewRegistry(logger *logging.Logger) *Registry {
 	return &Registry{
-		checks: make(map[string]Checker),
-		logger: logger,
+		checks:  make(map[string]registeredCheck),
+		logger:  logger,
+		timeout: DefaultCheckTimeout,
 	}
 }
 
-// Register adds a health check to the registry.
+// Register adds a readiness health check to the registry.
 func (r *Registry) Register(name string, checker Checker) {
+	r.RegisterProbe(name, ProbeReadiness, checker)
+}
+
+// RegisterLiveness adds a liveness health check to the registry.
+func (r *Registry) RegisterLiveness(name string, checker Checker) {
+	r.RegisterProbe(name, ProbeLiveness, checker)
+}
+
+// RegisterProbe adds a health check answering the given probe to the registry.
+func (r *Registry) RegisterProbe(name string, probe Probe, checker Checker) {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
-	r.checks[name] = checker
-	r.logger.Info("Registered health check", "name", name)
+	r.checks[name] = registeredCheck{checker: checker, probe: probe}
+	r.logger.Info("Registered health check", "name", name, "probe", probe)
 }
 
 // Unregister removes a health check from the registry.
@@ -96,20 +149,116 @@ func (r *Registry) Unregister(name string) {
 	r.logger.Info("Unregistered health check", "name", name)
 }
 
-// RunChecks runs all registered health checks.
-func (r *Registry) RunChecks(ctx context.Context) map[string]Check {
+// SetBuildInfo sets the build information reported with health checks.
+func (r *Registry) SetBuildInfo(info BuildInfo) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.buildInfo = info
+}
+
+// BuildInfo returns the build information reported with health checks.
+func (r *Registry) BuildInfo() BuildInfo {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
-	results := make(map[string]Check)
-	for name, checker := range r.checks {
-		r.logger.Debug("Running health check", "name", name)
-		results[name] = checker(ctx)
+	return r.buildInfo
+}
+
+// SetCheckTimeout sets how long a single check may run before it is reported
+// down. A non-positive timeout restores DefaultCheckTimeout.
+func (r *Registry) SetCheckTimeout(timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = DefaultCheckTimeout
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.timeout = timeout
+}
+
+// RunChecks runs all registered health checks concurrently and records how
+// long each took. Checks that do not finish within the check timeout are
+// reported down.
+func (r *Registry) RunChecks(ctx context.Context) map[string]Check {
+	return r.runChecks(ctx, func(Probe) bool { return true })
+}
+
+// RunProbe runs the health checks for a probe. Liveness runs only liveness
+// checks; readiness runs every check, since a process that is not alive is
+// not ready either.
+func (r *Registry) RunProbe(ctx context.Context, probe Probe) map[string]Check {
+	return r.runChecks(ctx, func(checkProbe Probe) bool {
+		return probe == ProbeReadiness || checkProbe == probe
+	})
+}
+
+// runChecks runs the registered health checks whose probe is included.
+func (r *Registry) runChecks(ctx context.Context, include func(Probe) bool) map[string]Check {
+	r.mutex.RLock()
+	checkers := make(map[string]Checker, len(r.checks))
+	for name, check := range r.checks {
+		if include(check.probe) {
+			checkers[name] = check.checker
+		}
 	}
+	timeout := r.timeout
+	r.mutex.RUnlock()
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		results = make(map[string]Check, len(checkers))
+	)
+	for name, checker := range checkers {
+		wg.Add(1)
+		go func(name string, checker Checker) {
+			defer wg.Done()
+
+			check := r.runCheck(ctx, name, checker, timeout)
+
+			mu.Lock()
+			results[name] = check
+			mu.Unlock()
+		}(name, checker)
+	}
+	wg.Wait()
 
 	return results
 }
 
+// runCheck runs a single checker, giving up once the timeout or ctx expires.
+// A checker that ignores its context is left to finish in the background.
+func (r *Registry) runCheck(ctx context.Context, name string, checker Checker, timeout time.Duration) Check {
+	r.logger.Debug("Running health check", "name", name)
+
+	checkCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan Check, 1)
+	go func() {
+		done <- checker(checkCtx)
+	}()
+
+	select {
+	case check := <-done:
+		check.Duration = time.Since(start)
+		return check
+	case <-checkCtx.Done():
+		r.logger.Warn("Health check timed out", "name", name, "timeout", timeout)
+		return Check{
+			Name:        name,
+			Status:      StatusDown,
+			Message:     "Health check timed out",
+			LastChecked: time.Now(),
+			Error:       fmt.Errorf("health check timed out after %v: %w", timeout, checkCtx.Err()),
+			Duration:    time.Since(start),
+		}
+	}
+}
+
 // IsHealthy returns true if all health checks are passing.
 func (r *Registry) IsHealthy(ctx context.Context) bool {
 	checks := r.RunChecks(ctx)
@@ -121,22 +270,53 @@ func (r *Registry) IsHealthy(ctx context.Context) bool {
 	return true
 }
 
-// Handler returns an HTTP handler for health checks.
+// OverallStatus combines check results: down if any check is down, unknown
+// if any is unknown, and up otherwise, including when there are no checks.
+func OverallStatus(checks map[string]Check) Status {
+	status := StatusUp
+	for _, check := range checks {
+		if check.Status == StatusDown {
+			return StatusDown
+		} else if check.Status == StatusUnknown {
+			status = StatusUnknown
+		}
+	}
+	return status
+}
+
+// Handler returns an HTTP handler running all health checks.
 func (r *Registry) Handler() http.Handler {
+	return r.handler(r.RunChecks)
+}
+
+// LivenessHandler returns an HTTP handler running the liveness checks. It
+// reports up while the process is serving unless a liveness check fails.
+func (r *Registry) LivenessHandler() http.Handler {
+	return r.handler(func(ctx context.Context) map[string]Check {
+		return r.RunProbe(ctx, ProbeLiveness)
+	})
+}
+
+// ReadinessHandler returns an HTTP handler running the readiness checks.
+func (r *Registry) ReadinessHandler() http.Handler {
+	return r.handler(func(ctx context.Context) map[string]Check {
+		return r.RunProbe(ctx, ProbeReadiness)
+	})
+}
+
+// handler returns an HTTP handler reporting the results of run.
+func (r *Registry) handler(run func(ctx context.Context) map[string]Check) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
-		ctx := req.Context()
-		checks := r.RunChecks(ctx)
+		r.mutex.RLock()
+		deadline := r.timeout + handlerTimeoutMargin
+		r.mutex.RUnlock()
+
+		ctx, cancel := context.WithTimeout(req.Context(), deadline)
+		defer cancel()
+		checks := run(ctx)
 
 		// Determine overall status
-		status := StatusUp
-		for _, check := range checks {
-			if check.Status == StatusDown {
-				status = StatusDown
-				break
-			} else if check.Status == StatusUnknown && status != StatusDown {
-				status = StatusUnknown
-			}
-		}
+		status := OverallStatus(checks)
 
 		// Set HTTP status code based on health status
 		if status == StatusDown {
@@ -152,10 +332,12 @@ func (r *Registry) Handler() http.Handler {
 			Status    Status           `json:"status"`
 			Timestamp time.Time        `json:"timestamp"`
 			Checks    map[string]Check `json:"checks"`
+			Build     BuildInfo        `json:"build"`
 		}{
 			Status:    status,
 			Timestamp: time.Now(),
 			Checks:    checks,
+			Build:     r.BuildInfo(),
 		}
 
 		// Write response
@@ -0,0 +1,172 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cmatc13/stathera/pkg/breaker"
+	"github.com/cmatc13/stathera/pkg/logging"
+)
+
+// statusChecker returns a checker reporting a fixed status
+func statusChecker(name string, status Status) Checker {
+	return func(ctx context.Context) Check {
+		return Check{Name: name, Status: status, LastChecked: time.Now()}
+	}
+}
+
+// blockingChecker never finishes before its context is done
+func blockingChecker(ctx context.Context) Check {
+	<-ctx.Done()
+	return Check{Name: "blocking", Status: StatusUp}
+}
+
+func newTestRegistry() *Registry {
+	return NewRegistry(logging.New(logging.Config{Level: logging.ErrorLevel}))
+}
+
+func TestRunProbe(t *testing.T) {
+	r := newTestRegistry()
+	r.RegisterLiveness("process", statusChecker("process", StatusUp))
+	r.Register("redis", statusChecker("redis", StatusDown))
+
+	tests := []struct {
+		probe Probe
+		want  []string
+	}{
+		{probe: ProbeLiveness, want: []string{"process"}},
+		{probe: ProbeReadiness, want: []string{"process", "redis"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.probe), func(t *testing.T) {
+			checks := r.RunProbe(context.Background(), tt.probe)
+			if len(checks) != len(tt.want) {
+				t.Fatalf("ran %d checks, want %v", len(checks), tt.want)
+			}
+			for _, name := range tt.want {
+				if _, ok := checks[name]; !ok {
+					t.Fatalf("check %s did not run for %s", name, tt.probe)
+				}
+			}
+		})
+	}
+}
+
+func TestRunChecksTimesOutSlowChecks(t *testing.T) {
+	r := newTestRegistry()
+	r.SetCheckTimeout(20 * time.Millisecond)
+	r.Register("blocking", blockingChecker)
+	r.Register("redis", statusChecker("redis", StatusUp))
+
+	start := time.Now()
+	checks := r.RunChecks(context.Background())
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("RunChecks took %v, want it bounded by the check timeout", elapsed)
+	}
+
+	blocked := checks["blocking"]
+	if blocked.Status != StatusDown || !errors.Is(blocked.Error, context.DeadlineExceeded) {
+		t.Fatalf("blocking check = %s, %v; want DOWN with a deadline error", blocked.Status, blocked.Error)
+	}
+	if blocked.Duration < 20*time.Millisecond {
+		t.Fatalf("blocking check duration = %v, want at least the timeout", blocked.Duration)
+	}
+	if checks["redis"].Status != StatusUp {
+		t.Fatalf("redis check = %s, want UP", checks["redis"].Status)
+	}
+}
+
+func TestOverallStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []Status
+		want     Status
+	}{
+		{name: "no checks", want: StatusUp},
+		{name: "all up", statuses: []Status{StatusUp, StatusUp}, want: StatusUp},
+		{name: "unknown", statuses: []Status{StatusUp, StatusUnknown}, want: StatusUnknown},
+		{name: "down wins", statuses: []Status{StatusUnknown, StatusDown, StatusUp}, want: StatusDown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checks := make(map[string]Check, len(tt.statuses))
+			for i, status := range tt.statuses {
+				checks[string(rune('a'+i))] = Check{Status: status}
+			}
+			if got := OverallStatus(checks); got != tt.want {
+				t.Fatalf("OverallStatus = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandlers(t *testing.T) {
+	r := newTestRegistry()
+	r.SetBuildInfo(BuildInfo{Version: "1.2.3", Commit: "abc123"})
+	r.RegisterLiveness("process", statusChecker("process", StatusUp))
+	r.Register("redis", statusChecker("redis", StatusDown))
+
+	tests := []struct {
+		name       string
+		handler    http.Handler
+		wantStatus int
+		wantBody   Status
+	}{
+		{name: "liveness ignores dependencies", handler: r.LivenessHandler(), wantStatus: http.StatusOK, wantBody: StatusUp},
+		{name: "readiness", handler: r.ReadinessHandler(), wantStatus: http.StatusServiceUnavailable, wantBody: StatusDown},
+		{name: "all checks", handler: r.Handler(), wantStatus: http.StatusServiceUnavailable, wantBody: StatusDown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			tt.handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health", nil))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status code = %d, want %d", w.Code, tt.wantStatus)
+			}
+			var body struct {
+				Status Status    `json:"status"`
+				Build  BuildInfo `json:"build"`
+			}
+			if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if body.Status != tt.wantBody || body.Build.Version != "1.2.3" {
+				t.Fatalf("response = %+v, want status %s and the build info", body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerChecker(t *testing.T) {
+	tests := []struct {
+		name     string
+		failures int
+		want     Status
+	}{
+		{name: "closed", want: StatusUp},
+		{name: "open", failures: 2, want: StatusDown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := breaker.New(2, time.Minute)
+			for i := 0; i < tt.failures; i++ {
+				b.Failure()
+			}
+
+			check := CircuitBreakerChecker("redis", b)(context.Background())
+			if check.Status != tt.want {
+				t.Fatalf("status = %s, want %s", check.Status, tt.want)
+			}
+		})
+	}
+}
@@ -3,10 +3,15 @@ package logging
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"log/slog"
 	"os"
+	"strings"
+	"sync"
 	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
 )
 
 // LogLevel represents the logging level.
@@ -23,15 +28,51 @@ const (
 	ErrorLevel LogLevel = "error"
 )
 
+// contextKey is the type of context keys defined by this package.
+type contextKey string
+
+const (
+	loggerKey  contextKey = "logger"
+	traceIDKey contextKey = "trace_id"
+)
+
+// Log files opened by OpenOutput, keyed by path
+var (
+	outputsMu sync.Mutex
+	outputs   = make(map[string]*os.File)
+)
+
+// contextLogger is a logger stored in a context along with the user ID it was created with.
+type contextLogger struct {
+	logger *Logger
+	userID string
+}
+
+// LogFormat represents the log output format.
+type LogFormat string
+
+const (
+	// JSONFormat writes one JSON object per log record.
+	JSONFormat LogFormat = "json"
+	// TextFormat writes key=value pairs, easier to read on a console.
+	TextFormat LogFormat = "text"
+)
+
 // Logger is a wrapper around slog.Logger that provides structured logging.
 type Logger struct {
 	*slog.Logger
+	level *slog.LevelVar
 }
 
 // Config holds the configuration for the logger.
 type Config struct {
 	// Level is the minimum log level to output.
 	Level LogLevel
+	// Format is the output format; defaults to JSON.
+	Format LogFormat
+	// SampleRate writes one in every SampleRate debug and info messages.
+	// Warnings and errors are always written. Values below 2 disable sampling.
+	SampleRate int
 	// Output is where the logs will be written to.
 	Output io.Writer
 	// ServiceName is the name of the service that is logging.
@@ -44,6 +85,7 @@ type Config struct {
 func DefaultConfig() Config {
 	return Config{
 		Level:       InfoLevel,
+		Format:      JSONFormat,
 		Output:      os.Stdout,
 		ServiceName: "stathera",
 		Environment: "development",
@@ -52,22 +94,15 @@ func DefaultConfig() Config {
 
 // New creates a new structured logger with the given configuration.
 func New(cfg Config) *Logger {
-	var level slog.Level
-	switch cfg.Level {
-	case DebugLevel:
-		level = slog.LevelDebug
-	case InfoLevel:
-		level = slog.LevelInfo
-	case WarnLevel:
-		level = slog.LevelWarn
-	case ErrorLevel:
-		level = slog.LevelError
-	default:
-		level = slog.LevelInfo
+	level := &slog.LevelVar{}
+	level.Set(toSlogLevel(cfg.Level))
+
+	output := cfg.Output
+	if output == nil {
+		output = os.Stdout
 	}
 
-	// Create a JSON handler with the configured level
-	handler := slog.NewJSONHandler(cfg.Output, &slog.HandlerOptions{
+	opts := &slog.HandlerOptions{
 		Level: level,
 		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
 			// Customize timestamp format
@@ -78,7 +113,19 @@ func New(cfg Config) *Logger {
 			}
 			return a
 		},
-	})
+	}
+
+	// Create a handler for the configured format
+	var handler slog.Handler
+	if LogFormat(strings.ToLower(string(cfg.Format))) == TextFormat {
+		handler = slog.NewTextHandler(output, opts)
+	} else {
+		handler = slog.NewJSONHandler(output, opts)
+	}
+
+	if cfg.SampleRate > 1 {
+		handler = newSamplingHandler(handler, cfg.SampleRate)
+	}
 
 	// Create a logger with the handler and add default attributes
 	logger := slog.New(handler).With(
@@ -86,20 +133,127 @@ func New(cfg Config) *Logger {
 		slog.String("environment", cfg.Environment),
 	)
 
-	return &Logger{Logger: logger}
+	return &Logger{Logger: logger, level: level}
+}
+
+// OpenOutput returns the writer for a configured output path: "stdout" (or
+// empty), "stderr", or a file path. Files are created if needed and opened
+// for appending; each path is opened once and shared for the life of the
+// process.
+func OpenOutput(path string) (io.Writer, error) {
+	switch strings.ToLower(path) {
+	case "", "stdout":
+		return os.Stdout, nil
+	case "stderr":
+		return os.Stderr, nil
+	}
+
+	outputsMu.Lock()
+	defer outputsMu.Unlock()
+
+	if f, ok := outputs[path]; ok {
+		return f, nil
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log output %s: %w", path, err)
+	}
+	outputs[path] = f
+
+	return f, nil
+}
+
+// SetLevel changes the minimum log level of the logger and all loggers derived from it.
+func (l *Logger) SetLevel(level LogLevel) {
+	if l.level != nil {
+		l.level.Set(toSlogLevel(level))
+	}
+}
+
+// toSlogLevel converts a LogLevel to a slog.Level, defaulting to info.
+func toSlogLevel(level LogLevel) slog.Level {
+	switch level {
+	case DebugLevel:
+		return slog.LevelDebug
+	case InfoLevel:
+		return slog.LevelInfo
+	case WarnLevel:
+		return slog.LevelWarn
+	case ErrorLevel:
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
 }
 
 // WithContext returns a new Logger with context values added to the logger.
+// It attaches the request ID set by chi's RequestID middleware, the trace ID
+// set by ContextWithTraceID, and the authenticated user ID when present.
 func (l *Logger) WithContext(ctx context.Context) *Logger {
-	// Extract values from context and add them to the logger
-	// This is a placeholder - in a real implementation, you would extract
-	// values like request ID, user ID, etc. from the context
-	return l
+	var attrs []any
+
+	if requestID := middleware.GetReqID(ctx); requestID != "" {
+		attrs = append(attrs, slog.String("request_id", requestID))
+	}
+	if traceID := TraceIDFromContext(ctx); traceID != "" {
+		attrs = append(attrs, slog.String("trace_id", traceID))
+	}
+	if userID := userIDFromContext(ctx); userID != "" {
+		attrs = append(attrs, slog.String("user_id", userID))
+	}
+
+	if len(attrs) == 0 {
+		return l
+	}
+	return &Logger{Logger: l.With(attrs...), level: l.level}
+}
+
+// NewContext returns a copy of ctx carrying the logger with the context's
+// request-scoped values attached.
+func NewContext(ctx context.Context, logger *Logger) context.Context {
+	return context.WithValue(ctx, loggerKey, &contextLogger{
+		logger: logger.WithContext(ctx),
+		userID: userIDFromContext(ctx),
+	})
+}
+
+// FromContext returns the request-scoped logger stored in ctx by NewContext,
+// or fallback with the context's values attached if there is none. A user ID
+// set on the context after the logger was stored is added as well.
+func FromContext(ctx context.Context, fallback *Logger) *Logger {
+	stored, ok := ctx.Value(loggerKey).(*contextLogger)
+	if !ok {
+		return fallback.WithContext(ctx)
+	}
+
+	// Authentication usually runs after the logger is stored
+	if userID := userIDFromContext(ctx); userID != "" && userID != stored.userID {
+		return stored.logger.WithField("user_id", userID)
+	}
+	return stored.logger
+}
+
+// ContextWithTraceID returns a copy of ctx carrying a trace ID for log correlation.
+func ContextWithTraceID(ctx context.Context, traceID string) context.Context {
+	return context.WithValue(ctx, traceIDKey, traceID)
+}
+
+// TraceIDFromContext returns the trace ID stored in ctx, if any.
+func TraceIDFromContext(ctx context.Context) string {
+	traceID, _ := ctx.Value(traceIDKey).(string)
+	return traceID
+}
+
+// userIDFromContext returns the authenticated user ID stored in ctx, if any.
+func userIDFromContext(ctx context.Context) string {
+	userID, _ := ctx.Value("user_id").(string)
+	return userID
 }
 
 // WithField adds a field to the logger.
 func (l *Logger) WithField(key string, value interface{}) *Logger {
-	return &Logger{Logger: l.With(slog.Any(key, value))}
+	return &Logger{Logger: l.With(slog.Any(key, value)), level: l.level}
 }
 
 // WithFields adds multiple fields to the logger.
@@ -108,7 +262,7 @@ func (l *Logger) WithFields(fields map[string]interface{}) *Logger {
 	for k, v := range fields {
 		logger = logger.With(slog.Any(k, v))
 	}
-	return &Logger{Logger: logger}
+	return &Logger{Logger: logger, level: l.level}
 }
 
 // WithError adds an error to the logger.
@@ -116,7 +270,7 @@ func (l *Logger) WithError(err error) *Logger {
 	if err == nil {
 		return l
 	}
-	return &Logger{Logger: l.With(slog.String("error", err.Error()))}
+	return &Logger{Logger: l.With(slog.String("error", err.Error())), level: l.level}
 }
 
 // Debug logs a debug message.
@@ -0,0 +1,143 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// decodeLines parses JSON log output into one map per record
+func decodeLines(t *testing.T, out *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+
+	var records []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var record map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("log line %q is not JSON: %v", line, err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		format LogFormat
+		want   string
+	}{
+		{format: JSONFormat, want: `"msg":"hello"`},
+		{format: "", want: `"msg":"hello"`},
+		{format: TextFormat, want: "msg=hello"},
+		{format: "TEXT", want: "msg=hello"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			var out bytes.Buffer
+			New(Config{Level: InfoLevel, Format: tt.format, Output: &out}).Info("hello")
+
+			if !strings.Contains(out.String(), tt.want) {
+				t.Fatalf("output %q does not contain %q", out.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestSetLevel(t *testing.T) {
+	var out bytes.Buffer
+	logger := New(Config{Level: InfoLevel, Output: &out})
+	derived := logger.WithField("component", "test")
+
+	derived.Debug("before")
+	logger.SetLevel(DebugLevel)
+	derived.Debug("after")
+
+	if strings.Contains(out.String(), "before") || !strings.Contains(out.String(), "after") {
+		t.Fatalf("derived logger did not follow the level change: %q", out.String())
+	}
+}
+
+func TestSampling(t *testing.T) {
+	tests := []struct {
+		name       string
+		sampleRate int
+		log        func(*Logger)
+		want       int
+	}{
+		{name: "disabled", sampleRate: 1, log: func(l *Logger) { l.Info("message") }, want: 10},
+		{name: "info sampled", sampleRate: 5, log: func(l *Logger) { l.Info("message") }, want: 2},
+		{name: "warnings kept", sampleRate: 5, log: func(l *Logger) { l.Warn("message") }, want: 10},
+		{name: "errors kept", sampleRate: 5, log: func(l *Logger) { l.Error("message") }, want: 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			logger := New(Config{Level: DebugLevel, Output: &out, SampleRate: tt.sampleRate})
+			for i := 0; i < 10; i++ {
+				tt.log(logger)
+			}
+
+			if got := len(decodeLines(t, &out)); got != tt.want {
+				t.Fatalf("wrote %d of 10 records, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	withRequestID := context.WithValue(context.Background(), middleware.RequestIDKey, "req-1")
+
+	tests := []struct {
+		name  string
+		ctx   func(fallback *Logger) context.Context
+		wants map[string]string
+	}{
+		{
+			name:  "fallback with request ID",
+			ctx:   func(*Logger) context.Context { return withRequestID },
+			wants: map[string]string{"request_id": "req-1"},
+		},
+		{
+			name: "stored logger with trace ID",
+			ctx: func(fallback *Logger) context.Context {
+				return NewContext(ContextWithTraceID(withRequestID, "trace-1"), fallback)
+			},
+			wants: map[string]string{"request_id": "req-1", "trace_id": "trace-1"},
+		},
+		{
+			name: "user authenticated after the logger was stored",
+			ctx: func(fallback *Logger) context.Context {
+				return context.WithValue(NewContext(withRequestID, fallback), "user_id", "user-1")
+			},
+			wants: map[string]string{"request_id": "req-1", "user_id": "user-1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			fallback := New(Config{Level: InfoLevel, Output: &out})
+
+			FromContext(tt.ctx(fallback), fallback).Info("handled")
+
+			records := decodeLines(t, &out)
+			if len(records) != 1 {
+				t.Fatalf("wrote %d records, want 1", len(records))
+			}
+			for key, want := range tt.wants {
+				if got := records[0][key]; got != want {
+					t.Fatalf("%s = %v, want %q", key, got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+)
+
+// samplingHandler wraps a handler and writes only one in every rate records
+// below warning level. Warnings and errors are always passed through.
+type samplingHandler struct {
+	next    slog.Handler
+	rate    uint64
+	counter *atomic.Uint64
+}
+
+// newSamplingHandler creates a sampling handler around next
+func newSamplingHandler(next slog.Handler, rate int) *samplingHandler {
+	return &samplingHandler{
+		next:    next,
+		rate:    uint64(rate),
+		counter: &atomic.Uint64{},
+	}
+}
+
+// Enabled reports whether the wrapped handler handles records at the given level.
+func (h *samplingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle writes the record if it is a warning or above, or if it is the
+// sampled one in its window.
+func (h *samplingHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level < slog.LevelWarn {
+		if (h.counter.Add(1)-1)%h.rate != 0 {
+			return nil
+		}
+	}
+	return h.next.Handle(ctx, r)
+}
+
+// WithAttrs returns a sampling handler whose wrapped handler has the given attributes.
+// The sample counter is shared so derived loggers are sampled together.
+func (h *samplingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &samplingHandler{next: h.next.WithAttrs(attrs), rate: h.rate, counter: h.counter}
+}
+
+// WithGroup returns a sampling handler whose wrapped handler has the given group.
+func (h *samplingHandler) WithGroup(name string) slog.Handler {
+	return &samplingHandler{next: h.next.WithGroup(name), rate: h.rate, counter: h.counter}
+}
@@ -3,11 +3,14 @@ package metrics
 
 import (
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	apierrors "github.com/cmatc13/stathera/pkg/errors"
 )
 
 // Metrics holds all the metrics collectors for the application.
@@ -20,6 +23,7 @@ type Metrics struct {
 	RequestDuration     *prometheus.HistogramVec
 	RequestInFlight     *prometheus.GaugeVec
 	ErrorCount          *prometheus.CounterVec
+	DomainErrorCount    *prometheus.CounterVec
 	ServiceUptime       prometheus.Gauge
 	ServiceLastStarted  prometheus.Gauge
 	DependencyUp        *prometheus.GaugeVec
@@ -32,18 +36,40 @@ type Metrics struct {
 	TransactionDuration   *prometheus.HistogramVec
 	TransactionErrorCount *prometheus.CounterVec
 
+	// Consumer metrics
+	ConsumerLag       *prometheus.GaugeVec
+	MessagesConsumed  *prometheus.CounterVec
+	ProcessingLatency *prometheus.HistogramVec
+
 	// Order book metrics
 	OrderCount      *prometheus.CounterVec
 	OrderAmount     *prometheus.HistogramVec
 	OrderDuration   *prometheus.HistogramVec
 	OrderErrorCount *prometheus.CounterVec
 	OrderBookDepth  *prometheus.GaugeVec
+	OrderBookSpread prometheus.Gauge
+	OrderBookMid    prometheus.Gauge
 
 	// Supply metrics
 	TotalSupply    prometheus.Gauge
 	InflationRate  prometheus.Gauge
 	SupplyChanges  *prometheus.CounterVec
 	ReserveBalance prometheus.Gauge
+
+	// Ledger metrics
+	LedgerIntegrityChecks *prometheus.CounterVec
+
+	// Settlement metrics
+	SettlementBatchSize   prometheus.Histogram
+	SettlementDuration    prometheus.Histogram
+	SettlementCount       *prometheus.CounterVec
+	SettlementLastSuccess prometheus.Gauge
+
+	// Security metrics
+	SecurityFailedLogins   prometheus.Counter
+	SecurityRateLimited    *prometheus.CounterVec
+	SecurityCSRFFailures   prometheus.Counter
+	SecurityInvalidAPIKeys prometheus.Counter
 }
 
 // Config holds the configuration for metrics.
@@ -54,8 +80,21 @@ type Config struct {
 	Subsystem string
 	// ServiceName is the name of the service that is collecting metrics.
 	ServiceName string
+	// RequestDurationBuckets are the request duration histogram buckets in seconds.
+	// Buckets must be in increasing order; empty uses DefaultRequestDurationBuckets.
+	RequestDurationBuckets []float64
+	// TransactionAmountBuckets are the transaction amount histogram buckets.
+	// Buckets must be in increasing order; empty uses DefaultTransactionAmountBuckets.
+	TransactionAmountBuckets []float64
 }
 
+var (
+	// DefaultRequestDurationBuckets are the default request duration buckets in seconds.
+	DefaultRequestDurationBuckets = prometheus.DefBuckets
+	// DefaultTransactionAmountBuckets are the default transaction amount buckets.
+	DefaultTransactionAmountBuckets = []float64{1, 10, 100, 1000, 10000, 100000}
+)
+
 // DefaultConfig returns a default metrics configuration.
 func DefaultConfig() Config {
 	return Config{
@@ -67,6 +106,15 @@ func DefaultConfig() Config {
 
 // New creates a new metrics collector with the given configuration.
 func New(cfg Config) *Metrics {
+	requestDurationBuckets := cfg.RequestDurationBuckets
+	if len(requestDurationBuckets) == 0 {
+		requestDurationBuckets = DefaultRequestDurationBuckets
+	}
+	transactionAmountBuckets := cfg.TransactionAmountBuckets
+	if len(transactionAmountBuckets) == 0 {
+		transactionAmountBuckets = DefaultTransactionAmountBuckets
+	}
+
 	registry := prometheus.NewRegistry()
 	factory := promauto.With(registry)
 
@@ -90,7 +138,7 @@ func New(cfg Config) *Metrics {
 				Subsystem: cfg.Subsystem,
 				Name:      "request_duration_seconds",
 				Help:      "Request duration in seconds",
-				Buckets:   prometheus.DefBuckets,
+				Buckets:   requestDurationBuckets,
 			},
 			[]string{"service", "method", "path"},
 		),
@@ -115,6 +163,16 @@ func New(cfg Config) *Metrics {
 			[]string{"service", "type", "code"},
 		),
 
+		DomainErrorCount: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: cfg.Namespace,
+				Subsystem: "domain",
+				Name:      "errors_total",
+				Help:      "Total number of errors by domain, code and operation",
+			},
+			[]string{"domain", "code", "operation"},
+		),
+
 		ServiceUptime: factory.NewGauge(
 			prometheus.GaugeOpts{
 				Namespace: cfg.Namespace,
@@ -187,7 +245,7 @@ func New(cfg Config) *Metrics {
 				Subsystem: "transaction",
 				Name:      "amount",
 				Help:      "Transaction amount distribution",
-				Buckets:   []float64{1, 10, 100, 1000, 10000, 100000},
+				Buckets:   transactionAmountBuckets,
 			},
 			[]string{"type"},
 		),
@@ -213,6 +271,38 @@ func New(cfg Config) *Metrics {
 			[]string{"type", "code"},
 		),
 
+		// Consumer metrics
+		ConsumerLag: factory.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: cfg.Namespace,
+				Subsystem: "consumer",
+				Name:      "lag",
+				Help:      "Number of messages between the committed offset and the high watermark",
+			},
+			[]string{"topic", "partition"},
+		),
+
+		MessagesConsumed: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: cfg.Namespace,
+				Subsystem: "consumer",
+				Name:      "messages_total",
+				Help:      "Total number of messages consumed",
+			},
+			[]string{"topic"},
+		),
+
+		ProcessingLatency: factory.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: cfg.Namespace,
+				Subsystem: "consumer",
+				Name:      "processing_latency_seconds",
+				Help:      "End-to-end latency from message production to confirmation in seconds",
+				Buckets:   prometheus.DefBuckets,
+			},
+			[]string{"topic"},
+		),
+
 		// Order book metrics
 		OrderCount: factory.NewCounterVec(
 			prometheus.CounterOpts{
@@ -266,6 +356,24 @@ func New(cfg Config) *Metrics {
 			[]string{"side"},
 		),
 
+		OrderBookSpread: factory.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: cfg.Namespace,
+				Subsystem: "orderbook",
+				Name:      "spread",
+				Help:      "Difference between the best ask and best bid prices",
+			},
+		),
+
+		OrderBookMid: factory.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: cfg.Namespace,
+				Subsystem: "orderbook",
+				Name:      "mid_price",
+				Help:      "Midpoint between the best ask and best bid prices",
+			},
+		),
+
 		// Supply metrics
 		TotalSupply: factory.NewGauge(
 			prometheus.GaugeOpts{
@@ -303,6 +411,95 @@ func New(cfg Config) *Metrics {
 				Help:      "Current balance of the reserve account",
 			},
 		),
+
+		// Ledger metrics
+		LedgerIntegrityChecks: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: cfg.Namespace,
+				Subsystem: "ledger",
+				Name:      "integrity_check_total",
+				Help:      "Total number of ledger integrity checks",
+			},
+			[]string{"result"},
+		),
+
+		// Settlement metrics
+		SettlementBatchSize: factory.NewHistogram(
+			prometheus.HistogramOpts{
+				Namespace: cfg.Namespace,
+				Subsystem: "settlement",
+				Name:      "batch_size",
+				Help:      "Number of transactions per settlement batch",
+				Buckets:   prometheus.ExponentialBuckets(1, 4, 8),
+			},
+		),
+
+		SettlementDuration: factory.NewHistogram(
+			prometheus.HistogramOpts{
+				Namespace: cfg.Namespace,
+				Subsystem: "settlement",
+				Name:      "duration_seconds",
+				Help:      "Settlement duration in seconds",
+				Buckets:   prometheus.DefBuckets,
+			},
+		),
+
+		SettlementCount: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: cfg.Namespace,
+				Subsystem: "settlement",
+				Name:      "total",
+				Help:      "Total number of settlement attempts",
+			},
+			[]string{"status"},
+		),
+
+		SettlementLastSuccess: factory.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: cfg.Namespace,
+				Subsystem: "settlement",
+				Name:      "last_success_timestamp",
+				Help:      "Unix time of the last successful settlement",
+			},
+		),
+
+		// Security metrics
+		SecurityFailedLogins: factory.NewCounter(
+			prometheus.CounterOpts{
+				Namespace: cfg.Namespace,
+				Subsystem: "security",
+				Name:      "failed_login_total",
+				Help:      "Total number of failed login attempts",
+			},
+		),
+
+		SecurityRateLimited: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: cfg.Namespace,
+				Subsystem: "security",
+				Name:      "rate_limited_total",
+				Help:      "Total number of requests rejected by rate limiting",
+			},
+			[]string{"path"},
+		),
+
+		SecurityCSRFFailures: factory.NewCounter(
+			prometheus.CounterOpts{
+				Namespace: cfg.Namespace,
+				Subsystem: "security",
+				Name:      "csrf_failure_total",
+				Help:      "Total number of requests failing CSRF validation",
+			},
+		),
+
+		SecurityInvalidAPIKeys: factory.NewCounter(
+			prometheus.CounterOpts{
+				Namespace: cfg.Namespace,
+				Subsystem: "security",
+				Name:      "invalid_apikey_total",
+				Help:      "Total number of requests with an invalid, expired or revoked API key",
+			},
+		),
 	}
 
 	// Set initial values
@@ -345,6 +542,26 @@ func (m *Metrics) RecordError(service, errorType, errorCode string) {
 	m.ErrorCount.WithLabelValues(service, errorType, errorCode).Inc()
 }
 
+// RecordDomainError records an error by its domain, code and operation.
+// Errors that are not domain errors are recorded with domain "unknown".
+func (m *Metrics) RecordDomainError(err error) {
+	if err == nil {
+		return
+	}
+
+	domain, code, operation := "unknown", "", ""
+	var domainErr *apierrors.Error
+	if apierrors.As(err, &domainErr) {
+		if domainErr.Domain != "" {
+			domain = domainErr.Domain
+		}
+		code = domainErr.Code
+		operation = domainErr.Operation
+	}
+
+	m.DomainErrorCount.WithLabelValues(domain, code, operation).Inc()
+}
+
 // RecordDependencyStatus records the status of a dependency.
 func (m *Metrics) RecordDependencyStatus(service, dependency string, up bool) {
 	var value float64
@@ -376,6 +593,17 @@ func (m *Metrics) RecordTransactionError(txType, errorCode string) {
 	m.TransactionErrorCount.WithLabelValues(txType, errorCode).Inc()
 }
 
+// RecordConsumerLag records the lag of a consumer on a topic partition.
+func (m *Metrics) RecordConsumerLag(topic string, partition int32, lag int64) {
+	m.ConsumerLag.WithLabelValues(topic, strconv.Itoa(int(partition))).Set(float64(lag))
+}
+
+// RecordMessageConsumed records a consumed message and its end-to-end latency.
+func (m *Metrics) RecordMessageConsumed(topic string, latency time.Duration) {
+	m.MessagesConsumed.WithLabelValues(topic).Inc()
+	m.ProcessingLatency.WithLabelValues(topic).Observe(latency.Seconds())
+}
+
 // RecordOrder records metrics for an order.
 func (m *Metrics) RecordOrder(orderType, status string, amount float64, duration time.Duration) {
 	m.OrderCount.WithLabelValues(orderType, status).Inc()
@@ -393,6 +621,12 @@ func (m *Metrics) RecordOrderBookDepth(side string, depth float64) {
 	m.OrderBookDepth.WithLabelValues(side).Set(depth)
 }
 
+// RecordOrderBookPrices records the spread and mid price from the best bid and ask.
+func (m *Metrics) RecordOrderBookPrices(bestBid, bestAsk float64) {
+	m.OrderBookSpread.Set(bestAsk - bestBid)
+	m.OrderBookMid.Set((bestAsk + bestBid) / 2)
+}
+
 // RecordTotalSupply records the total supply of the currency.
 func (m *Metrics) RecordTotalSupply(supply float64) {
 	m.TotalSupply.Set(supply)
@@ -412,3 +646,51 @@ func (m *Metrics) RecordSupplyChange(changeType string) {
 func (m *Metrics) RecordReserveBalance(balance float64) {
 	m.ReserveBalance.Set(balance)
 }
+
+// RecordLedgerIntegrityCheck records the result of a ledger integrity check.
+func (m *Metrics) RecordLedgerIntegrityCheck(valid bool) {
+	result := "valid"
+	if !valid {
+		result = "invalid"
+	}
+	m.LedgerIntegrityChecks.WithLabelValues(result).Inc()
+}
+
+// RecordSettlement records a settled or failed settlement batch.
+func (m *Metrics) RecordSettlement(success bool, batchSize int, duration time.Duration) {
+	status := "success"
+	if !success {
+		status = "failure"
+	}
+	m.SettlementCount.WithLabelValues(status).Inc()
+	m.SettlementBatchSize.Observe(float64(batchSize))
+	m.SettlementDuration.Observe(duration.Seconds())
+	if success {
+		m.SettlementLastSuccess.Set(float64(time.Now().Unix()))
+	}
+}
+
+// RecordEmptySettlement records a settlement run with nothing to settle.
+func (m *Metrics) RecordEmptySettlement() {
+	m.SettlementCount.WithLabelValues("empty").Inc()
+}
+
+// RecordFailedLogin records a failed login attempt.
+func (m *Metrics) RecordFailedLogin() {
+	m.SecurityFailedLogins.Inc()
+}
+
+// RecordRateLimited records a request rejected by rate limiting.
+func (m *Metrics) RecordRateLimited(path string) {
+	m.SecurityRateLimited.WithLabelValues(path).Inc()
+}
+
+// RecordCSRFFailure records a request failing CSRF validation.
+func (m *Metrics) RecordCSRFFailure() {
+	m.SecurityCSRFFailures.Inc()
+}
+
+// RecordInvalidAPIKey records a request with an invalid API key.
+func (m *Metrics) RecordInvalidAPIKey() {
+	m.SecurityInvalidAPIKeys.Inc()
+}
@@ -0,0 +1,48 @@
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	apierrors "github.com/cmatc13/stathera/pkg/errors"
+)
+
+func TestNewRegistersAllCollectors(t *testing.T) {
+	m := New(DefaultConfig())
+	if _, err := m.Registry.Gather(); err != nil {
+		t.Fatalf("Gather: %v", err)
+	}
+}
+
+func TestRecordDomainError(t *testing.T) {
+	orderErr := apierrors.OrderBookWrapWithCode(errors.New("connection refused"), apierrors.OpPlaceOrder, apierrors.OrderBookErrRedisConnection, "failed to place order")
+
+	tests := []struct {
+		name      string
+		err       error
+		domain    string
+		code      string
+		operation string
+	}{
+		{name: "wrapped orderbook error", err: fmt.Errorf("handler: %w", orderErr), domain: apierrors.OrderBookDomain, code: apierrors.OrderBookErrRedisConnection, operation: apierrors.OpPlaceOrder},
+		{name: "plain error", err: errors.New("boom"), domain: "unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := New(DefaultConfig())
+			m.RecordDomainError(tt.err)
+
+			counter := m.DomainErrorCount.WithLabelValues(tt.domain, tt.code, tt.operation)
+			if got := testutil.ToFloat64(counter); got != 1 {
+				t.Fatalf("domain_errors_total{domain=%q,code=%q,operation=%q} = %v, want 1", tt.domain, tt.code, tt.operation, got)
+			}
+			if got := testutil.CollectAndCount(m.DomainErrorCount); got != 1 {
+				t.Fatalf("recorded %d label sets, want 1", got)
+			}
+		})
+	}
+}
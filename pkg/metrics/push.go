@@ -0,0 +1,45 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/push"
+)
+
+// PushTo pushes all metrics in the registry to a Prometheus Pushgateway.
+// It is intended for short-lived processes that exit before they can be scraped.
+// Metrics previously pushed under the same job are replaced.
+func (m *Metrics) PushTo(gatewayURL, job string) error {
+	if err := push.New(gatewayURL, job).Gatherer(m.Registry).Push(); err != nil {
+		return fmt.Errorf("failed to push metrics to %s: %w", gatewayURL, err)
+	}
+	return nil
+}
+
+// StartPushing starts a goroutine that pushes metrics to a Pushgateway every
+// interval until ctx is cancelled, with a final push on cancellation so the
+// last values are not lost. Push failures are logged and retried on the next tick.
+func (m *Metrics) StartPushing(ctx context.Context, gatewayURL, job string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+
+	go func() {
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if err := m.PushTo(gatewayURL, job); err != nil {
+					log.Printf("%v", err)
+				}
+			case <-ctx.Done():
+				if err := m.PushTo(gatewayURL, job); err != nil {
+					log.Printf("%v", err)
+				}
+				return
+			}
+		}
+	}()
+}
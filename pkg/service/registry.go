@@ -5,17 +5,26 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"sort"
 	"sync"
 	"time"
+
+	"golang.org/x/sync/errgroup"
 )
 
 // Registry manages all services and their lifecycle.
 // It handles service registration, dependency resolution, and coordinated
-// startup and shutdown of services.
+// startup and shutdown of services. Services implementing Restartable are
+// supervised after startup and restarted if they become unhealthy.
 type Registry struct {
 	services map[string]Service
 	mutex    sync.RWMutex
 	logger   *log.Logger
+
+	supervisorMu     sync.Mutex
+	supervisorCancel context.CancelFunc
+	supervisorWg     sync.WaitGroup
+	failed           map[string]error
 }
 
 // NewRegistry creates a new service registry with the provided logger.
@@ -24,6 +33,7 @@ func NewRegistry(logger *log.Logger) *Registry {
 	return &Registry{
 		services: make(map[string]Service),
 		logger:   logger,
+		failed:   make(map[string]error),
 	}
 }
 
@@ -58,9 +68,12 @@ func (r *Registry) Get(name string) (Service, error) {
 }
 
 // StartAll starts all services in dependency order.
-// It builds a dependency graph, performs a topological sort to determine
-// the correct startup order, and starts each service in that order.
-// It waits for each service to become healthy before starting the next one.
+// It builds a dependency graph and groups services into levels, where each
+// level depends only on services in earlier levels. Services within a level
+// are started concurrently, and each level must be healthy before the next
+// one starts. Services are started with ctx, so they run until it is
+// cancelled. If any service fails to start, the remaining health waits are
+// cancelled and every service that was already started is stopped.
 func (r *Registry) StartAll(ctx context.Context) error {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
@@ -72,22 +85,47 @@ func (r *Registry) StartAll(ctx context.Context) error {
 		return fmt.Errorf("dependency cycle detected: %w", err)
 	}
 
-	// Start services in order
-	for _, name := range order {
-		service := r.services[name]
-		r.logger.Printf("Starting service: %s", name)
+	var (
+		started   []string
+		startedMu sync.Mutex
+	)
+
+	// Start services level by level
+	for _, level := range dependencyLevels(graph, order) {
+		g, gctx := errgroup.WithContext(ctx)
+
+		for _, name := range level {
+			name := name
+			service := r.services[name]
 
-		if err := service.Start(ctx); err != nil {
-			r.logger.Printf("Failed to start service %s: %v", name, err)
-			return fmt.Errorf("failed to start service %s: %w", name, err)
+			g.Go(func() error {
+				r.logger.Printf("Starting service: %s", name)
+
+				// The group context ends with the level, so it only bounds the health wait
+				if err := service.Start(ctx); err != nil {
+					r.logger.Printf("Failed to start service %s: %v", name, err)
+					return fmt.Errorf("failed to start service %s: %w", name, err)
+				}
+
+				startedMu.Lock()
+				started = append(started, name)
+				startedMu.Unlock()
+
+				// Wait for service to be healthy
+				return r.waitForHealth(gctx, name, service)
+			})
 		}
 
-		// Wait for service to be healthy
-		if err := r.waitForHealth(ctx, name); err != nil {
+		if err := g.Wait(); err != nil {
+			// Roll back everything started so far, even if ctx was cancelled
+			r.stopServices(context.WithoutCancel(ctx), started)
 			return err
 		}
 	}
 
+	// Supervise services that opted into restarts
+	r.startSupervisors()
+
 	return nil
 }
 
@@ -106,13 +144,19 @@ func (r *Registry) StopAll(ctx context.Context) error {
 		return fmt.Errorf("dependency cycle detected: %w", err)
 	}
 
-	// Reverse the order for stopping
-	for i, j := 0, len(order)-1; i < j; i, j = i+1, j-1 {
-		order[i], order[j] = order[j], order[i]
-	}
+	// Stop supervision first so stopped services are not restarted
+	r.stopSupervisors()
 
-	// Stop services in reverse order
-	for _, name := range order {
+	r.stopServices(ctx, order)
+
+	return nil
+}
+
+// stopServices stops the named services in the reverse of the given order.
+// Errors are logged and do not prevent the remaining services from stopping.
+func (r *Registry) stopServices(ctx context.Context, order []string) {
+	for i := len(order) - 1; i >= 0; i-- {
+		name := order[i]
 		service := r.services[name]
 		r.logger.Printf("Stopping service: %s", name)
 
@@ -121,18 +165,21 @@ func (r *Registry) StopAll(ctx context.Context) error {
 			// Continue stopping other services
 		}
 	}
-
-	return nil
 }
 
 // HealthCheck performs health checks on all services.
 // It returns a map of service names to health check results (nil if healthy, error if not).
+// Services that exhausted their restart policy report their failure.
 func (r *Registry) HealthCheck() map[string]error {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
 	results := make(map[string]error)
 	for name, service := range r.services {
+		if err := r.failure(name); err != nil {
+			results[name] = err
+			continue
+		}
 		results[name] = service.Health()
 	}
 
@@ -141,23 +188,26 @@ func (r *Registry) HealthCheck() map[string]error {
 
 // waitForHealth waits for a service to become healthy.
 // It polls the service's Health method until it returns nil or a timeout occurs.
-func (r *Registry) waitForHealth(ctx context.Context, name string) error {
-	service, err := r.Get(name)
-	if err != nil {
-		return err
-	}
+// Services implementing HealthWaiter control the timeout and poll interval.
+func (r *Registry) waitForHealth(ctx context.Context, name string, service Service) error {
+	timeout, interval := healthWaitSettings(service)
+	return r.waitForHealthWithin(ctx, name, service, timeout, interval)
+}
 
-	ticker := time.NewTicker(500 * time.Millisecond)
+// waitForHealthWithin polls a service's Health method every interval until it
+// returns nil, failing once timeout has passed
+func (r *Registry) waitForHealthWithin(ctx context.Context, name string, service Service, timeout, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
-	timeout := time.After(30 * time.Second)
+	deadline := time.After(timeout)
 
 	for {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
-		case <-timeout:
-			return fmt.Errorf("timeout waiting for service %s to become healthy", name)
+		case <-deadline:
+			return fmt.Errorf("timeout waiting for service %s to become healthy after %s", name, timeout)
 		case <-ticker.C:
 			if err := service.Health(); err == nil {
 				return nil
@@ -166,6 +216,22 @@ func (r *Registry) waitForHealth(ctx context.Context, name string) error {
 	}
 }
 
+// healthWaitSettings returns the health wait timeout and poll interval for a service
+func healthWaitSettings(service Service) (time.Duration, time.Duration) {
+	timeout, interval := DefaultHealthTimeout, DefaultHealthPollInterval
+
+	if waiter, ok := service.(HealthWaiter); ok {
+		if t := waiter.HealthTimeout(); t > 0 {
+			timeout = t
+		}
+		if i := waiter.HealthPollInterval(); i > 0 {
+			interval = i
+		}
+	}
+
+	return timeout, interval
+}
+
 // buildDependencyGraph creates a graph representation of service dependencies.
 // The graph is a map where keys are service names and values are lists of
 // services that the key service depends on.
@@ -179,9 +245,45 @@ func buildDependencyGraph(services map[string]Service) map[string][]string {
 	return graph
 }
 
+// dependencyLevels groups services from a topological order into levels.
+// A service's level is one more than the highest level of its dependencies,
+// so services in the same level are independent of each other.
+func dependencyLevels(graph map[string][]string, order []string) [][]string {
+	levelOf := make(map[string]int, len(order))
+	var levels [][]string
+
+	for _, name := range order {
+		level := 0
+		for _, dep := range graph[name] {
+			// Skip if dependency doesn't exist (might be external)
+			depLevel, exists := levelOf[dep]
+			if !exists {
+				continue
+			}
+			if depLevel+1 > level {
+				level = depLevel + 1
+			}
+		}
+
+		levelOf[name] = level
+		if level == len(levels) {
+			levels = append(levels, nil)
+		}
+		levels[level] = append(levels[level], name)
+	}
+
+	// Keep startup order stable within each level
+	for _, level := range levels {
+		sort.Strings(level)
+	}
+
+	return levels
+}
+
 // topologicalSort performs a topological sort on the dependency graph
-// and returns the sorted service names. It detects cycles in the dependency
-// graph and returns an error if a cycle is found.
+// and returns the sorted service names, dependencies before dependents.
+// It detects cycles in the dependency graph and returns an error if a
+// cycle is found.
 func topologicalSort(graph map[string][]string) ([]string, error) {
 	// Create a map to track visited nodes
 	visited := make(map[string]bool)
@@ -238,10 +340,7 @@ func topologicalSort(graph map[string][]string) ([]string, error) {
 		}
 	}
 
-	// Reverse the order (topological sort gives reverse dependency order)
-	for i, j := 0, len(order)-1; i < j; i, j = i+1, j-1 {
-		order[i], order[j] = order[j], order[i]
-	}
-
+	// Nodes are appended after their dependencies, so order is already
+	// dependencies first
 	return order, nil
 }
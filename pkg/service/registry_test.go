@@ -0,0 +1,237 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeService is a Service test double that records its lifecycle calls
+type fakeService struct {
+	name     string
+	deps     []string
+	startErr error
+
+	mu       sync.Mutex
+	ctx      context.Context
+	starts   int
+	stops    int
+	healthy  bool
+	unhealth error
+	events   *eventLog
+}
+
+// eventLog records lifecycle events from several services in order
+type eventLog struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (l *eventLog) add(event string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events = append(l.events, event)
+}
+
+func (l *eventLog) list() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.events...)
+}
+
+func (s *fakeService) Name() string           { return s.name }
+func (s *fakeService) Dependencies() []string { return s.deps }
+func (s *fakeService) Status() Status         { return StatusRunning }
+
+func (s *fakeService) Start(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.starts++
+	s.ctx = ctx
+	if s.events != nil {
+		s.events.add("start:" + s.name)
+	}
+	if s.startErr != nil {
+		return s.startErr
+	}
+	s.healthy = true
+	return nil
+}
+
+func (s *fakeService) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stops++
+	s.healthy = false
+	if s.events != nil {
+		s.events.add("stop:" + s.name)
+	}
+	return nil
+}
+
+func (s *fakeService) Health() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.unhealth != nil {
+		return s.unhealth
+	}
+	if !s.healthy {
+		return errors.New("not started")
+	}
+	return nil
+}
+
+func (s *fakeService) HealthTimeout() time.Duration      { return 200 * time.Millisecond }
+func (s *fakeService) HealthPollInterval() time.Duration { return time.Millisecond }
+
+func (s *fakeService) startContext() context.Context {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ctx
+}
+
+func newTestRegistry(t *testing.T, services ...Service) *Registry {
+	t.Helper()
+
+	r := NewRegistry(log.New(io.Discard, "", 0))
+	for _, s := range services {
+		if err := r.Register(s); err != nil {
+			t.Fatalf("Register(%s): %v", s.Name(), err)
+		}
+	}
+	return r
+}
+
+func TestStartAllKeepsServiceContextAlive(t *testing.T) {
+	svc := &fakeService{name: "api"}
+	r := newTestRegistry(t, svc)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if err := r.StartAll(ctx); err != nil {
+		t.Fatalf("StartAll: %v", err)
+	}
+	defer r.StopAll(context.Background())
+
+	if err := svc.startContext().Err(); err != nil {
+		t.Fatalf("service context after StartAll: %v", err)
+	}
+
+	cancel()
+	if err := svc.startContext().Err(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("service context after cancelling parent: got %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestStartAllOrder(t *testing.T) {
+	tests := []struct {
+		name     string
+		services map[string][]string
+		want     []string
+	}{
+		{
+			name:     "chain starts dependencies first",
+			services: map[string][]string{"api": {"processor"}, "processor": {"redis"}, "redis": nil},
+			want:     []string{"start:redis", "start:processor", "start:api"},
+		},
+		{
+			name:     "external dependencies are ignored",
+			services: map[string][]string{"api": {"postgres"}},
+			want:     []string{"start:api"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events := &eventLog{}
+			var services []Service
+			for name, deps := range tt.services {
+				services = append(services, &fakeService{name: name, deps: deps, events: events})
+			}
+			r := newTestRegistry(t, services...)
+
+			if err := r.StartAll(context.Background()); err != nil {
+				t.Fatalf("StartAll: %v", err)
+			}
+
+			if got := events.list(); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("events = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStartAllRollsBackOnFailure(t *testing.T) {
+	redis := &fakeService{name: "redis"}
+	processor := &fakeService{name: "processor", deps: []string{"redis"}, startErr: errors.New("boom")}
+	r := newTestRegistry(t, redis, processor)
+
+	err := r.StartAll(context.Background())
+	if err == nil {
+		t.Fatal("StartAll succeeded, want error")
+	}
+
+	if redis.stops != 1 || processor.stops != 0 {
+		t.Errorf("stops: redis %d, processor %d; want 1 and 0", redis.stops, processor.stops)
+	}
+}
+
+func TestStartAllDetectsCycles(t *testing.T) {
+	r := newTestRegistry(t,
+		&fakeService{name: "a", deps: []string{"b"}},
+		&fakeService{name: "b", deps: []string{"a"}},
+	)
+
+	if err := r.StartAll(context.Background()); err == nil {
+		t.Fatal("StartAll succeeded with a dependency cycle")
+	}
+}
+
+func TestRegisterRejectsDuplicates(t *testing.T) {
+	r := newTestRegistry(t, &fakeService{name: "api"})
+
+	if err := r.Register(&fakeService{name: "api"}); err == nil {
+		t.Fatal("Register accepted a duplicate service name")
+	}
+}
+
+func TestDependencyLevels(t *testing.T) {
+	tests := []struct {
+		name  string
+		graph map[string][]string
+		want  [][]string
+	}{
+		{
+			name:  "single level",
+			graph: map[string][]string{"a": nil, "b": nil},
+			want:  [][]string{{"a", "b"}},
+		},
+		{
+			name:  "diamond",
+			graph: map[string][]string{"top": {"left", "right"}, "left": {"base"}, "right": {"base"}, "base": nil},
+			want:  [][]string{{"base"}, {"left", "right"}, {"top"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order, err := topologicalSort(tt.graph)
+			if err != nil {
+				t.Fatalf("topologicalSort: %v", err)
+			}
+
+			if got := dependencyLevels(tt.graph, order); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("dependencyLevels = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
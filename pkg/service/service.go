@@ -5,6 +5,7 @@ package service
 
 import (
 	"context"
+	"time"
 )
 
 // Status represents the current state of a service.
@@ -53,3 +54,51 @@ type Service interface {
 	// services should be started and stopped.
 	Dependencies() []string
 }
+
+// Default health wait settings used when a service does not implement HealthWaiter.
+const (
+	// DefaultHealthTimeout is how long the registry waits for a service to become healthy.
+	DefaultHealthTimeout = 30 * time.Second
+	// DefaultHealthPollInterval is how often the registry checks a starting service's health.
+	DefaultHealthPollInterval = 500 * time.Millisecond
+)
+
+// HealthWaiter is an optional interface for services that need a custom
+// health wait after starting, such as slow-starting services or test doubles.
+// A non-positive value falls back to the corresponding default.
+type HealthWaiter interface {
+	// HealthTimeout returns how long to wait for the service to become healthy.
+	HealthTimeout() time.Duration
+
+	// HealthPollInterval returns how often to check the service's health while waiting.
+	HealthPollInterval() time.Duration
+}
+
+// RestartPolicy describes how the registry restarts a service that becomes
+// unhealthy after startup.
+type RestartPolicy struct {
+	// MaxRetries is the number of restart attempts before the service is marked failed.
+	MaxRetries int
+	// Backoff is the delay before the first restart attempt; it doubles on each retry.
+	Backoff time.Duration
+	// MaxBackoff caps the delay between restart attempts; defaults to DefaultMaxRestartBackoff.
+	MaxBackoff time.Duration
+	// CheckInterval is how often the service's health is checked; defaults to DefaultSupervisionInterval.
+	CheckInterval time.Duration
+	// HealthTimeout is how long a restarted service has to become healthy;
+	// defaults to the check interval. A HealthWaiter's shorter timeout wins.
+	HealthTimeout time.Duration
+}
+
+// DefaultMaxRestartBackoff is the default cap on the delay between restart attempts.
+const DefaultMaxRestartBackoff = time.Minute
+
+// DefaultSupervisionInterval is how often supervised services are health checked.
+const DefaultSupervisionInterval = 5 * time.Second
+
+// Restartable is an optional interface for services that should be supervised
+// and restarted by the registry when they become unhealthy.
+type Restartable interface {
+	// RestartPolicy returns the policy used to restart the service.
+	RestartPolicy() RestartPolicy
+}
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// defaultRestartBackoff is used when a restart policy does not set a backoff
+const defaultRestartBackoff = time.Second
+
+// startSupervisors starts a supervisor goroutine for every service that
+// implements Restartable. Supervisors run until stopSupervisors is called.
+func (r *Registry) startSupervisors() {
+	r.supervisorMu.Lock()
+	defer r.supervisorMu.Unlock()
+
+	if r.supervisorCancel != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r.supervisorCancel = cancel
+
+	for name, service := range r.services {
+		restartable, ok := service.(Restartable)
+		if !ok {
+			continue
+		}
+
+		r.supervisorWg.Add(1)
+		go func(name string, service Service, policy RestartPolicy) {
+			defer r.supervisorWg.Done()
+			r.supervise(ctx, name, service, policy)
+		}(name, service, restartable.RestartPolicy())
+	}
+}
+
+// stopSupervisors stops all supervisor goroutines and waits for them to exit
+func (r *Registry) stopSupervisors() {
+	r.supervisorMu.Lock()
+	cancel := r.supervisorCancel
+	r.supervisorCancel = nil
+	r.supervisorMu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+
+	cancel()
+	r.supervisorWg.Wait()
+}
+
+// supervise periodically checks a service's health and restarts it with
+// exponential backoff when it becomes unhealthy. Restarts count as failed
+// until a later check finds the service healthy, so a service that keeps
+// failing right after restarting still runs out of retries. After exhausting
+// its retries the service is marked failed and supervision stops.
+func (r *Registry) supervise(ctx context.Context, name string, service Service, policy RestartPolicy) {
+	interval := policy.CheckInterval
+	if interval <= 0 {
+		interval = DefaultSupervisionInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	attempts := 0
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		healthErr := service.Health()
+		if healthErr == nil {
+			attempts = 0
+			continue
+		}
+
+		r.logger.Printf("Service %s is unhealthy: %v", name, healthErr)
+
+		if err := r.restart(ctx, name, service, policy, &attempts); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+
+			r.logger.Printf("FATAL: service %s failed after %d restart attempts: %v", name, policy.MaxRetries, err)
+			r.markFailed(name, fmt.Errorf("service %s failed after %d restart attempts: %w", name, policy.MaxRetries, err))
+			return
+		}
+
+		r.logger.Printf("Service %s recovered", name)
+	}
+}
+
+// restart attempts to restart a service according to its policy, counting
+// each attempt in attempts. It returns the last error once attempts reaches
+// the policy's retry limit.
+func (r *Registry) restart(ctx context.Context, name string, service Service, policy RestartPolicy, attempts *int) error {
+	lastErr := fmt.Errorf("no restart attempts allowed")
+	for *attempts < policy.MaxRetries {
+		*attempts++
+		backoff := restartBackoff(policy, *attempts)
+		r.logger.Printf("Restarting service %s in %s (attempt %d/%d)", name, backoff, *attempts, policy.MaxRetries)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		if err := service.Stop(ctx); err != nil {
+			r.logger.Printf("Error stopping service %s before restart: %v", name, err)
+		}
+
+		if err := service.Start(ctx); err != nil {
+			lastErr = fmt.Errorf("failed to start service %s: %w", name, err)
+			r.logger.Printf("Restart of service %s failed: %v", name, lastErr)
+			continue
+		}
+
+		timeout, interval := restartHealthWait(service, policy)
+		if err := r.waitForHealthWithin(ctx, name, service, timeout, interval); err != nil {
+			lastErr = err
+			r.logger.Printf("Restart of service %s failed: %v", name, lastErr)
+			continue
+		}
+
+		return nil
+	}
+
+	return lastErr
+}
+
+// restartBackoff returns the delay before a restart attempt: the policy's
+// backoff doubled for each earlier attempt, capped at its maximum
+func restartBackoff(policy RestartPolicy, attempt int) time.Duration {
+	backoff := policy.Backoff
+	if backoff <= 0 {
+		backoff = defaultRestartBackoff
+	}
+	maxBackoff := policy.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = DefaultMaxRestartBackoff
+	}
+
+	for i := 1; i < attempt && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+	return backoff
+}
+
+// restartHealthWait returns how long a restarted service has to become
+// healthy, and how often its health is polled meanwhile
+func restartHealthWait(service Service, policy RestartPolicy) (time.Duration, time.Duration) {
+	timeout, interval := healthWaitSettings(service)
+
+	limit := policy.HealthTimeout
+	if limit <= 0 {
+		limit = policy.CheckInterval
+	}
+	if limit <= 0 {
+		limit = DefaultSupervisionInterval
+	}
+	if limit < timeout {
+		timeout = limit
+	}
+
+	return timeout, interval
+}
+
+// markFailed records that a service has permanently failed
+func (r *Registry) markFailed(name string, err error) {
+	r.supervisorMu.Lock()
+	defer r.supervisorMu.Unlock()
+	r.failed[name] = err
+}
+
+// failure returns the recorded permanent failure of a service, if any
+func (r *Registry) failure(name string) error {
+	r.supervisorMu.Lock()
+	defer r.supervisorMu.Unlock()
+	return r.failed[name]
+}
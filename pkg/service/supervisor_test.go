@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// restartableService is a fakeService opting into supervision
+type restartableService struct {
+	*fakeService
+	policy RestartPolicy
+}
+
+func (s *restartableService) RestartPolicy() RestartPolicy { return s.policy }
+
+// flappingService becomes unhealthy again right after every restart
+type flappingService struct {
+	restartableService
+}
+
+func (s *flappingService) Health() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Healthy only while the restart waits for it, then failing again
+	if s.healthy {
+		s.healthy = false
+		return nil
+	}
+	return errors.New("flapping")
+}
+
+// slowHealthService never becomes healthy and asks for a long health wait
+type slowHealthService struct {
+	restartableService
+	mu     sync.Mutex
+	checks []time.Time
+}
+
+func (s *slowHealthService) Health() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.checks = append(s.checks, time.Now())
+	return errors.New("still starting")
+}
+
+func (s *slowHealthService) HealthTimeout() time.Duration { return time.Minute }
+
+func TestRestartBackoff(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  RestartPolicy
+		attempt int
+		want    time.Duration
+	}{
+		{name: "first attempt", policy: RestartPolicy{Backoff: time.Second}, attempt: 1, want: time.Second},
+		{name: "doubles", policy: RestartPolicy{Backoff: time.Second}, attempt: 3, want: 4 * time.Second},
+		{name: "capped", policy: RestartPolicy{Backoff: time.Second, MaxBackoff: 5 * time.Second}, attempt: 4, want: 5 * time.Second},
+		{name: "default cap", policy: RestartPolicy{Backoff: time.Second}, attempt: 60, want: DefaultMaxRestartBackoff},
+		{name: "default backoff", policy: RestartPolicy{}, attempt: 1, want: defaultRestartBackoff},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := restartBackoff(tt.policy, tt.attempt); got != tt.want {
+				t.Fatalf("restartBackoff(attempt %d) = %s, want %s", tt.attempt, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRestartHealthWait(t *testing.T) {
+	tests := []struct {
+		name    string
+		service Service
+		policy  RestartPolicy
+		want    time.Duration
+	}{
+		{name: "bounded by check interval", service: &slowHealthService{}, policy: RestartPolicy{CheckInterval: time.Second}, want: time.Second},
+		{name: "bounded by policy timeout", service: &slowHealthService{}, policy: RestartPolicy{HealthTimeout: 3 * time.Second}, want: 3 * time.Second},
+		{name: "default supervision interval", service: &slowHealthService{}, want: DefaultSupervisionInterval},
+		{name: "shorter service timeout", service: &fakeService{}, policy: RestartPolicy{CheckInterval: time.Second}, want: 200 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, _ := restartHealthWait(tt.service, tt.policy); got != tt.want {
+				t.Fatalf("restartHealthWait = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSuperviseGivesUpOnFlappingService(t *testing.T) {
+	svc := &flappingService{restartableService{
+		fakeService: &fakeService{name: "api"},
+		policy:      RestartPolicy{MaxRetries: 3, Backoff: time.Millisecond, CheckInterval: 5 * time.Millisecond},
+	}}
+	r := newTestRegistry(t, svc)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		r.supervise(context.Background(), "api", svc, svc.policy)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("supervision of a flapping service never gave up")
+	}
+
+	if err := r.failure("api"); err == nil {
+		t.Fatal("flapping service was not marked failed")
+	}
+	if svc.starts != 3 {
+		t.Fatalf("service restarted %d times, want 3", svc.starts)
+	}
+}
+
+func TestSuperviseResetsAttemptsAfterHealthyCheck(t *testing.T) {
+	svc := &restartableService{
+		fakeService: &fakeService{name: "api", healthy: true},
+		policy:      RestartPolicy{MaxRetries: 1, Backoff: time.Millisecond, CheckInterval: 5 * time.Millisecond},
+	}
+	r := newTestRegistry(t, svc)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		r.supervise(ctx, "api", svc, svc.policy)
+	}()
+
+	// Fail twice, with healthy checks in between; each failure needs one restart
+	for i := 0; i < 2; i++ {
+		svc.mu.Lock()
+		svc.healthy = false
+		svc.mu.Unlock()
+
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			svc.mu.Lock()
+			starts := svc.starts
+			svc.mu.Unlock()
+			if starts == i+1 {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("service was not restarted after failure %d", i+1)
+			}
+			time.Sleep(time.Millisecond)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	cancel()
+	<-done
+	if err := r.failure("api"); err != nil {
+		t.Fatalf("service that recovered was marked failed: %v", err)
+	}
+}
+
+func TestRestartBoundsHealthWait(t *testing.T) {
+	svc := &slowHealthService{restartableService: restartableService{
+		fakeService: &fakeService{name: "api"},
+		policy:      RestartPolicy{MaxRetries: 1, Backoff: time.Millisecond, HealthTimeout: 50 * time.Millisecond},
+	}}
+	r := newTestRegistry(t, svc)
+
+	start := time.Now()
+	attempts := 0
+	if err := r.restart(context.Background(), "api", svc, svc.policy, &attempts); err == nil {
+		t.Fatal("restart succeeded for a service that never became healthy")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("restart waited %s for health, want about the 50ms policy timeout", elapsed)
+	}
+	if attempts != 1 {
+		t.Fatalf("attempts = %d, want 1", attempts)
+	}
+}
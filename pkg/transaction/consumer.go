@@ -0,0 +1,207 @@
+package transaction
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/go-redis/redis/v8"
+
+	"github.com/cmatc13/stathera/internal/transaction"
+	"github.com/cmatc13/stathera/pkg/config"
+	"github.com/cmatc13/stathera/pkg/logging"
+	"github.com/cmatc13/stathera/pkg/metrics"
+)
+
+const (
+	// consumerPollTimeout is how long a consumer waits for a message before
+	// checking whether it should stop
+	consumerPollTimeout = time.Second
+
+	// consumerRetryDelay is how long a consumer waits before retrying a
+	// message it could not handle
+	consumerRetryDelay = time.Second
+)
+
+// errMalformedMessage reports a topic message that can never be handled, so
+// it is skipped rather than retried
+var errMalformedMessage = errors.New("malformed message")
+
+// FailedRecorder records failed transactions for review.
+type FailedRecorder interface {
+	Record(ctx context.Context, tx *transaction.Transaction, reason string) error
+}
+
+// Consumer reads the transaction events processors publish on a Kafka topic
+// and handles each one, committing its offset once it has been handled.
+type Consumer struct {
+	consumer   *kafka.Consumer
+	topic      string
+	handle     func(ctx context.Context, value []byte) error
+	watermarks func(topic string, partition int32) (low, high int64, err error)
+	logger     *logging.Logger
+	metrics    *metrics.Metrics
+}
+
+// NewFailedConsumer creates a consumer of cfg.FailedTopic that records each
+// failed transaction with recorder. Messages are JSON FailedTransaction records.
+func NewFailedConsumer(cfg config.KafkaConfig, recorder FailedRecorder, logger *logging.Logger) (*Consumer, error) {
+	return newConsumer(cfg, cfg.FailedTopic, "failed", func(ctx context.Context, value []byte) error {
+		return recordFailedMessage(ctx, recorder, value)
+	}, logger)
+}
+
+// NewConfirmationConsumer creates a consumer of cfg.ConfirmedTopic that
+// publishes each confirmed transaction to its parties' confirmation channels
+// with PublishConfirmation. Messages are JSON transactions.
+func NewConfirmationConsumer(cfg config.KafkaConfig, client *redis.Client, logger *logging.Logger) (*Consumer, error) {
+	publish := func(ctx context.Context, tx *transaction.Transaction) error {
+		return PublishConfirmation(ctx, client, tx)
+	}
+	return newConsumer(cfg, cfg.ConfirmedTopic, "confirmations", func(ctx context.Context, value []byte) error {
+		return publishConfirmedMessage(ctx, publish, value)
+	}, logger)
+}
+
+// newConsumer creates a consumer of topic in its own consumer group, named
+// after the configured group and purpose
+func newConsumer(cfg config.KafkaConfig, topic, purpose string, handle func(ctx context.Context, value []byte) error, logger *logging.Logger) (*Consumer, error) {
+	configMap := kafka.ConfigMap{}
+	for key, value := range cfg.ClientSettings() {
+		configMap[key] = value
+	}
+	configMap["group.id"] = cfg.ConsumerGroupID + "-" + purpose
+	for key, d := range map[string]time.Duration{
+		"session.timeout.ms":    cfg.SessionTimeout,
+		"heartbeat.interval.ms": cfg.HeartbeatInterval,
+		"max.poll.interval.ms":  cfg.MaxPollInterval,
+	} {
+		if d > 0 {
+			configMap[key] = int(d / time.Millisecond)
+		}
+	}
+	configMap["auto.offset.reset"] = "earliest"
+	// Offsets are committed once a message has been handled
+	configMap["enable.auto.commit"] = false
+
+	consumer, err := kafka.NewConsumer(&configMap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s consumer: %w", purpose, err)
+	}
+	if err := consumer.Subscribe(topic, nil); err != nil {
+		consumer.Close()
+		return nil, fmt.Errorf("failed to subscribe to %s: %w", topic, err)
+	}
+
+	return &Consumer{
+		consumer:   consumer,
+		topic:      topic,
+		handle:     handle,
+		watermarks: consumer.GetWatermarkOffsets,
+		logger:     logger.WithField("topic", topic),
+	}, nil
+}
+
+// SetMetrics sets the collector that records the consumer's throughput,
+// latency and lag
+func (c *Consumer) SetMetrics(m *metrics.Metrics) {
+	c.metrics = m
+}
+
+// Run handles messages until ctx is done. A message that fails to be handled
+// is retried before the next one is read, so none is lost.
+func (c *Consumer) Run(ctx context.Context) error {
+	for ctx.Err() == nil {
+		msg, err := c.consumer.ReadMessage(consumerPollTimeout)
+		if err != nil {
+			var kafkaErr kafka.Error
+			if errors.As(err, &kafkaErr) && kafkaErr.Code() == kafka.ErrTimedOut {
+				continue
+			}
+			c.logger.Warn("Failed to read message", "error", err)
+			continue
+		}
+
+		for {
+			err := c.handle(ctx, msg.Value)
+			if err == nil {
+				break
+			}
+			if errors.Is(err, errMalformedMessage) {
+				c.logger.Warn("Skipping malformed message", "offset", msg.TopicPartition.Offset, "error", err)
+				break
+			}
+
+			c.logger.Error("Failed to handle message", "error", err)
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(consumerRetryDelay):
+			}
+		}
+
+		if _, err := c.consumer.CommitMessage(msg); err != nil {
+			c.logger.Warn("Failed to commit message offset", "error", err)
+		}
+		c.recordConsumed(msg)
+	}
+
+	return nil
+}
+
+// recordConsumed records a handled message's end-to-end latency and how far
+// its partition's high watermark is ahead of it
+func (c *Consumer) recordConsumed(msg *kafka.Message) {
+	if c.metrics == nil {
+		return
+	}
+	c.metrics.RecordMessageConsumed(c.topic, time.Since(msg.Timestamp))
+
+	partition := msg.TopicPartition.Partition
+	_, high, err := c.watermarks(c.topic, partition)
+	if err != nil {
+		c.logger.Debug("Failed to get watermark offsets", "partition", partition, "error", err)
+		return
+	}
+	lag := high - int64(msg.TopicPartition.Offset) - 1
+	if lag < 0 {
+		lag = 0
+	}
+	c.metrics.RecordConsumerLag(c.topic, partition, lag)
+}
+
+// Close closes the consumer, leaving its consumer group.
+func (c *Consumer) Close() error {
+	return c.consumer.Close()
+}
+
+// recordFailedMessage records the failed transaction carried by a failed
+// topic message
+func recordFailedMessage(ctx context.Context, recorder FailedRecorder, value []byte) error {
+	var record FailedTransaction
+	if err := json.Unmarshal(value, &record); err != nil {
+		return fmt.Errorf("%w: %v", errMalformedMessage, err)
+	}
+	if record.Transaction == nil || record.Transaction.ID == "" {
+		return fmt.Errorf("%w: missing transaction", errMalformedMessage)
+	}
+
+	return recorder.Record(ctx, record.Transaction, record.Reason)
+}
+
+// publishConfirmedMessage publishes the transaction carried by a confirmed
+// topic message
+func publishConfirmedMessage(ctx context.Context, publish func(context.Context, *transaction.Transaction) error, value []byte) error {
+	var tx transaction.Transaction
+	if err := json.Unmarshal(value, &tx); err != nil {
+		return fmt.Errorf("%w: %v", errMalformedMessage, err)
+	}
+	if tx.ID == "" {
+		return fmt.Errorf("%w: missing transaction ID", errMalformedMessage)
+	}
+
+	return publish(ctx, &tx)
+}
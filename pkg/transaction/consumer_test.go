@@ -0,0 +1,172 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/cmatc13/stathera/internal/transaction"
+	"github.com/cmatc13/stathera/pkg/logging"
+	"github.com/cmatc13/stathera/pkg/metrics"
+)
+
+// fakeRecorder collects the failed transactions it is asked to record
+type fakeRecorder struct {
+	err     error
+	records []FailedTransaction
+}
+
+func (r *fakeRecorder) Record(ctx context.Context, tx *transaction.Transaction, reason string) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.records = append(r.records, FailedTransaction{Transaction: tx, Reason: reason})
+	return nil
+}
+
+func TestRecordFailedMessage(t *testing.T) {
+	storeErr := errors.New("redis unavailable")
+
+	tests := []struct {
+		name       string
+		value      string
+		recordErr  error
+		wantErr    error
+		wantReason string
+	}{
+		{
+			name:       "valid record",
+			value:      `{"transaction":{"id":"tx-1","status":"FAILED"},"reason":"insufficient funds","failed_at":1000}`,
+			wantReason: "insufficient funds",
+		},
+		{name: "not json", value: `not json`, wantErr: errMalformedMessage},
+		{name: "no transaction", value: `{"reason":"insufficient funds"}`, wantErr: errMalformedMessage},
+		{name: "no transaction id", value: `{"transaction":{},"reason":"insufficient funds"}`, wantErr: errMalformedMessage},
+		{
+			name:      "store fails",
+			value:     `{"transaction":{"id":"tx-1"},"reason":"insufficient funds"}`,
+			recordErr: storeErr,
+			wantErr:   storeErr,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &fakeRecorder{err: tt.recordErr}
+			err := recordFailedMessage(context.Background(), recorder, []byte(tt.value))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("recordFailedMessage error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if len(recorder.records) != 0 {
+					t.Fatalf("recorded %d transactions, want none", len(recorder.records))
+				}
+				return
+			}
+
+			if len(recorder.records) != 1 {
+				t.Fatalf("recorded %d transactions, want 1", len(recorder.records))
+			}
+			if got := recorder.records[0]; got.Transaction.ID != "tx-1" || got.Reason != tt.wantReason {
+				t.Fatalf("recorded %s with reason %q, want tx-1 with %q", got.Transaction.ID, got.Reason, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestPublishConfirmedMessage(t *testing.T) {
+	publishErr := errors.New("redis unavailable")
+
+	tests := []struct {
+		name       string
+		value      string
+		publishErr error
+		wantErr    error
+	}{
+		{name: "confirmed transaction", value: `{"id":"tx-1","sender":"alice","receiver":"bob","status":"CONFIRMED"}`},
+		{name: "not json", value: `[`, wantErr: errMalformedMessage},
+		{name: "no id", value: `{"sender":"alice"}`, wantErr: errMalformedMessage},
+		{name: "publish fails", value: `{"id":"tx-1"}`, publishErr: publishErr, wantErr: publishErr},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var published []*transaction.Transaction
+			publish := func(ctx context.Context, tx *transaction.Transaction) error {
+				if tt.publishErr != nil {
+					return tt.publishErr
+				}
+				published = append(published, tx)
+				return nil
+			}
+
+			err := publishConfirmedMessage(context.Background(), publish, []byte(tt.value))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("publishConfirmedMessage error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if len(published) != 0 {
+					t.Fatalf("published %d transactions, want none", len(published))
+				}
+				return
+			}
+			if len(published) != 1 || published[0].ID != "tx-1" {
+				t.Fatalf("published %v, want tx-1", published)
+			}
+		})
+	}
+}
+
+func TestRecordConsumed(t *testing.T) {
+	tests := []struct {
+		name          string
+		high          int64
+		watermarkErr  error
+		wantLag       float64
+		wantLagSeries int
+	}{
+		{name: "behind the watermark", high: 15, wantLag: 4, wantLagSeries: 1},
+		{name: "caught up", high: 11, wantLag: 0, wantLagSeries: 1},
+		{name: "stale watermark", high: 5, wantLag: 0, wantLagSeries: 1},
+		{name: "no watermark", watermarkErr: errors.New("no cached offsets"), wantLagSeries: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := metrics.New(metrics.DefaultConfig())
+			topic := "confirmed"
+			c := &Consumer{
+				topic: topic,
+				watermarks: func(string, int32) (int64, int64, error) {
+					return 0, tt.high, tt.watermarkErr
+				},
+				logger:  logging.New(logging.Config{Level: logging.ErrorLevel}),
+				metrics: m,
+			}
+
+			c.recordConsumed(&kafka.Message{
+				TopicPartition: kafka.TopicPartition{Topic: &topic, Partition: 2, Offset: 10},
+				Timestamp:      time.Now().Add(-time.Second),
+			})
+
+			if got := testutil.ToFloat64(m.MessagesConsumed.WithLabelValues(topic)); got != 1 {
+				t.Fatalf("MessagesConsumed = %v, want 1", got)
+			}
+			if got := testutil.CollectAndCount(m.ProcessingLatency); got != 1 {
+				t.Fatalf("ProcessingLatency series = %d, want 1", got)
+			}
+			if got := testutil.CollectAndCount(m.ConsumerLag); got != tt.wantLagSeries {
+				t.Fatalf("ConsumerLag series = %d, want %d", got, tt.wantLagSeries)
+			}
+			if tt.wantLagSeries > 0 {
+				if got := testutil.ToFloat64(m.ConsumerLag.WithLabelValues(topic, "2")); got != tt.wantLag {
+					t.Fatalf("ConsumerLag = %v, want %v", got, tt.wantLag)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package transaction
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/cmatc13/stathera/internal/transaction"
+)
+
+// confirmationChannelPrefix prefixes the Redis pub/sub channels carrying confirmations
+const confirmationChannelPrefix = "tx:confirmed:"
+
+// ConfirmationChannel returns the Redis pub/sub channel on which confirmed
+// transactions involving an address are published.
+func ConfirmationChannel(address string) string {
+	return confirmationChannelPrefix + address
+}
+
+// PublishConfirmation publishes a confirmed transaction as JSON to the
+// channels of its sender and receiver.
+func PublishConfirmation(ctx context.Context, client *redis.Client, tx *transaction.Transaction) error {
+	payload, err := json.Marshal(tx)
+	if err != nil {
+		return fmt.Errorf("failed to marshal transaction: %w", err)
+	}
+
+	pipe := client.Pipeline()
+	if tx.Sender != "" {
+		pipe.Publish(ctx, ConfirmationChannel(tx.Sender), payload)
+	}
+	if tx.Receiver != "" && tx.Receiver != tx.Sender {
+		pipe.Publish(ctx, ConfirmationChannel(tx.Receiver), payload)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to publish confirmation: %w", err)
+	}
+
+	return nil
+}
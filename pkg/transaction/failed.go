@@ -0,0 +1,162 @@
+package transaction
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/cmatc13/stathera/internal/transaction"
+	apierrors "github.com/cmatc13/stathera/pkg/errors"
+)
+
+const (
+	// failedTxPrefix prefixes the Redis keys holding failed transaction records
+	failedTxPrefix = "tx:failed:"
+
+	// failedTxIndexKey is the sorted set of failed transaction IDs, scored by failure time
+	failedTxIndexKey = "tx:failed"
+)
+
+// FailedTransaction is a failed transaction awaiting operator review.
+type FailedTransaction struct {
+	Transaction *transaction.Transaction `json:"transaction"`
+	Reason      string                   `json:"reason"`
+	FailedAt    int64                    `json:"failed_at"`
+}
+
+// FailedStore indexes failed transactions in Redis so they can be reviewed
+// and requeued.
+type FailedStore struct {
+	client *redis.Client
+}
+
+// NewFailedStore creates a failed transaction store backed by client.
+func NewFailedStore(client *redis.Client) *FailedStore {
+	return &FailedStore{client: client}
+}
+
+// Record stores a failed transaction with the reason it failed. Recording a
+// transaction again replaces its previous record.
+func (s *FailedStore) Record(ctx context.Context, tx *transaction.Transaction, reason string) error {
+	record := FailedTransaction{
+		Transaction: tx,
+		Reason:      reason,
+		FailedAt:    time.Now().Unix(),
+	}
+
+	return s.Restore(ctx, &record)
+}
+
+// Restore stores a failed transaction record as it is, such as one taken
+// out of the store by Claim that could not be requeued.
+func (s *FailedStore) Restore(ctx context.Context, record *FailedTransaction) error {
+	payload, err := json.Marshal(record)
+	if err != nil {
+		return apierrors.NewStorageError(apierrors.StorageErrSerialization, "failed to marshal failed transaction", err)
+	}
+
+	pipe := s.client.TxPipeline()
+	pipe.Set(ctx, failedTxPrefix+record.Transaction.ID, payload, 0)
+	pipe.ZAdd(ctx, failedTxIndexKey, &redis.Z{Score: float64(record.FailedAt), Member: record.Transaction.ID})
+	if _, err := pipe.Exec(ctx); err != nil {
+		return apierrors.NewStorageError(apierrors.StorageErrWrite, "failed to record failed transaction", err)
+	}
+
+	return nil
+}
+
+// Get returns the failed transaction record for an ID. A transaction that is
+// not in the store is reported as a storage not-found error.
+func (s *FailedStore) Get(ctx context.Context, id string) (*FailedTransaction, error) {
+	payload, err := s.client.Get(ctx, failedTxPrefix+id).Bytes()
+	if err == redis.Nil {
+		return nil, apierrors.NewStorageError(apierrors.StorageErrNotFound, "failed transaction not found", err)
+	}
+	if err != nil {
+		return nil, apierrors.NewStorageError(apierrors.StorageErrRead, "failed to read failed transaction", err)
+	}
+
+	return decodeFailed(payload)
+}
+
+// Claim removes a failed transaction record and returns it. Of several
+// concurrent claims of one transaction, only one gets the record; the others
+// get a storage not-found error.
+func (s *FailedStore) Claim(ctx context.Context, id string) (*FailedTransaction, error) {
+	pipe := s.client.TxPipeline()
+	get := pipe.Get(ctx, failedTxPrefix+id)
+	del := pipe.Del(ctx, failedTxPrefix+id)
+	pipe.ZRem(ctx, failedTxIndexKey, id)
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, apierrors.NewStorageError(apierrors.StorageErrDelete, "failed to claim failed transaction", err)
+	}
+	if del.Val() == 0 {
+		return nil, apierrors.NewStorageError(apierrors.StorageErrNotFound, "failed transaction not found", redis.Nil)
+	}
+
+	payload, err := get.Bytes()
+	if err != nil {
+		return nil, apierrors.NewStorageError(apierrors.StorageErrRead, "failed to read failed transaction", err)
+	}
+	return decodeFailed(payload)
+}
+
+// List returns a page of failed transactions, most recent first.
+func (s *FailedStore) List(ctx context.Context, limit, offset int64) ([]*FailedTransaction, error) {
+	ids, err := s.client.ZRevRange(ctx, failedTxIndexKey, offset, offset+limit-1).Result()
+	if err != nil {
+		return nil, apierrors.NewStorageError(apierrors.StorageErrRead, "failed to list failed transactions", err)
+	}
+
+	records := make([]*FailedTransaction, 0, len(ids))
+	for _, id := range ids {
+		record, err := s.Get(ctx, id)
+		if apierrors.IsStorageError(err, apierrors.StorageErrNotFound) {
+			// Removed between listing and reading
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// Count returns the number of failed transactions in the store.
+func (s *FailedStore) Count(ctx context.Context) (int64, error) {
+	count, err := s.client.ZCard(ctx, failedTxIndexKey).Result()
+	if err != nil {
+		return 0, apierrors.NewStorageError(apierrors.StorageErrRead, "failed to count failed transactions", err)
+	}
+
+	return count, nil
+}
+
+// Remove deletes a failed transaction record, clearing its failure reason.
+func (s *FailedStore) Remove(ctx context.Context, id string) error {
+	pipe := s.client.TxPipeline()
+	pipe.Del(ctx, failedTxPrefix+id)
+	pipe.ZRem(ctx, failedTxIndexKey, id)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return apierrors.NewStorageError(apierrors.StorageErrDelete, "failed to remove failed transaction", err)
+	}
+
+	return nil
+}
+
+// decodeFailed unmarshals a failed transaction record
+func decodeFailed(payload []byte) (*FailedTransaction, error) {
+	var record FailedTransaction
+	if err := json.Unmarshal(payload, &record); err != nil {
+		return nil, apierrors.NewStorageError(apierrors.StorageErrDeserialization, "failed to unmarshal failed transaction", err)
+	}
+	if record.Transaction == nil {
+		return nil, apierrors.NewStorageError(apierrors.StorageErrDeserialization, "failed transaction record has no transaction", nil)
+	}
+
+	return &record, nil
+}
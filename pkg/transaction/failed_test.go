@@ -0,0 +1,37 @@
+package transaction
+
+import (
+	"testing"
+
+	apierrors "github.com/cmatc13/stathera/pkg/errors"
+)
+
+func TestDecodeFailed(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		wantErr bool
+	}{
+		{name: "valid record", payload: `{"transaction":{"id":"tx-1"},"reason":"expired","failed_at":1000}`},
+		{name: "not json", payload: `{`, wantErr: true},
+		{name: "no transaction", payload: `{"reason":"expired"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record, err := decodeFailed([]byte(tt.payload))
+			if tt.wantErr {
+				if !apierrors.IsStorageError(err, apierrors.StorageErrDeserialization) {
+					t.Fatalf("decodeFailed error = %v, want a deserialization error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("decodeFailed: %v", err)
+			}
+			if record.Transaction.ID != "tx-1" || record.Reason != "expired" || record.FailedAt != 1000 {
+				t.Fatalf("decodeFailed = %+v, want tx-1 expired at 1000", record)
+			}
+		})
+	}
+}
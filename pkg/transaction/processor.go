@@ -12,3 +12,27 @@ type Processor interface {
 	// SubmitTransaction submits a new transaction to be processed.
 	SubmitTransaction(tx *transaction.Transaction) error
 }
+
+// HistoryReader is implemented by processors that can serve a user's
+// transaction history.
+type HistoryReader interface {
+	// GetUserTransactions returns a page of transactions for an address, newest first.
+	GetUserTransactions(address string, limit, offset int64) ([]*transaction.Transaction, error)
+
+	// CountUserTransactions returns the total number of transactions for an address.
+	CountUserTransactions(address string) (int64, error)
+}
+
+// Reader is implemented by processors that can look up stored transactions.
+type Reader interface {
+	// GetTransaction returns a stored transaction by ID. A transaction that has
+	// not been stored yet is reported as a storage not-found error.
+	GetTransaction(id string) (*transaction.Transaction, error)
+}
+
+// ReservationReader is implemented by processors that hold funds for pending
+// operations and can report the held part of a balance.
+type ReservationReader interface {
+	// GetAvailableBalance returns the spendable and held parts of an address's balance.
+	GetAvailableBalance(address string) (available, held float64, err error)
+}